@@ -16,6 +16,7 @@ require (
 	github.com/vicanso/elton v1.13.2
 	github.com/vicanso/go-axios v1.6.1
 	github.com/vicanso/hes v0.7.0
+	golang.org/x/image v0.0.0-20211028202545-6944b10bf410
 	google.golang.org/grpc v1.63.2
 )
 
@@ -32,7 +33,6 @@ require (
 	github.com/vicanso/http-trace v1.1.0 // indirect
 	github.com/vicanso/intranet-ip v0.1.0 // indirect
 	github.com/vicanso/keygrip v1.2.1 // indirect
-	golang.org/x/image v0.0.0-20211028202545-6944b10bf410 // indirect
 	golang.org/x/net v0.21.0 // indirect
 	golang.org/x/sys v0.17.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
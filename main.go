@@ -36,6 +36,24 @@ func main() {
 		return
 	}
 
+	server.InitFallbackPolicy()
+	err := server.ProbeTools()
+	if err != nil {
+		panic(err)
+	}
+
+	server.StartTempFileCleaner()
+
+	err = server.InitEncodeLimits()
+	if err != nil {
+		panic(err)
+	}
+
+	err = server.LoadDictionaries()
+	if err != nil {
+		panic(err)
+	}
+
 	// 如果两个服务都未指定地址，则使用默认地址
 	if httpAddress == "" && grpcAddress == "" {
 		httpAddress = defaultHTTPAddress
@@ -63,7 +81,7 @@ func main() {
 		}
 		return
 	}
-	err := server.NewGRPCServer(grpcAddress)
+	err = server.NewGRPCServer(grpcAddress)
 	if err != nil {
 		panic(err)
 	}
@@ -5,6 +5,7 @@ package pb
 
 import (
 	context "context"
+	encoding_binary "encoding/binary"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
 	grpc "google.golang.org/grpc"
@@ -38,11 +39,19 @@ const (
 	Type_LZ4 Type = 4
 	// zstd
 	Type_ZSTD Type = 5
+	// 自动选择压缩后数据最小的类型（仅用于输出）
+	Type_AUTO Type = 6
 	Type_JPEG Type = 11
 	Type_PNG  Type = 12
 	Type_WEBP Type = 13
 	// AVIF
 	Type_AVIF Type = 14
+	// GIF
+	Type_GIF Type = 15
+	// BMP（仅支持作为源图片）
+	Type_BMP Type = 16
+	// TIFF（仅支持作为源图片）
+	Type_TIFF Type = 17
 )
 
 var Type_name = map[int32]string{
@@ -52,10 +61,14 @@ var Type_name = map[int32]string{
 	3:  "SNAPPY",
 	4:  "LZ4",
 	5:  "ZSTD",
+	6:  "AUTO",
 	11: "JPEG",
 	12: "PNG",
 	13: "WEBP",
 	14: "AVIF",
+	15: "GIF",
+	16: "BMP",
+	17: "TIFF",
 }
 
 var Type_value = map[string]int32{
@@ -65,10 +78,14 @@ var Type_value = map[string]int32{
 	"SNAPPY":  3,
 	"LZ4":     4,
 	"ZSTD":    5,
+	"AUTO":    6,
 	"JPEG":    11,
 	"PNG":     12,
 	"WEBP":    13,
 	"AVIF":    14,
+	"GIF":     15,
+	"BMP":     16,
+	"TIFF":    17,
 }
 
 func (x Type) String() string {
@@ -79,6 +96,137 @@ func (Type) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_b0f4449489fcc4ff, []int{0}
 }
 
+// 图片元数据的处理方式
+type Metadata int32
+
+const (
+	// 删除所有元数据
+	Metadata_STRIP Metadata = 0
+	// 保留exif与icc
+	Metadata_KEEP Metadata = 1
+	// 仅保留icc
+	Metadata_KEEP_ICC Metadata = 2
+	// 保留exif中的版权与作者，以及icc
+	Metadata_KEEP_COPYRIGHT Metadata = 3
+)
+
+var Metadata_name = map[int32]string{
+	0: "STRIP",
+	1: "KEEP",
+	2: "KEEP_ICC",
+	3: "KEEP_COPYRIGHT",
+}
+
+var Metadata_value = map[string]int32{
+	"STRIP":          0,
+	"KEEP":           1,
+	"KEEP_ICC":       2,
+	"KEEP_COPYRIGHT": 3,
+}
+
+func (x Metadata) String() string {
+	return proto.EnumName(Metadata_name, int32(x))
+}
+
+func (Metadata) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_b0f4449489fcc4ff, []int{1}
+}
+
+// 图片缩放的适配方式
+type Fit int32
+
+const (
+	// 直接缩放至指定尺寸（指定裁剪时则只裁剪）
+	Fit_NONE Fit = 0
+	// 保持比例缩放至尺寸内，空白部分透明填充
+	Fit_CONTAIN Fit = 1
+	// 保持比例缩放至覆盖尺寸，超出部分裁剪
+	Fit_COVER Fit = 2
+	// 忽略比例拉伸至指定尺寸
+	Fit_FILL Fit = 3
+	// 保持比例缩放至尺寸内
+	Fit_INSIDE Fit = 4
+	// 保持比例缩放至覆盖尺寸，不裁剪
+	Fit_OUTSIDE Fit = 5
+)
+
+var Fit_name = map[int32]string{
+	0: "NONE",
+	1: "CONTAIN",
+	2: "COVER",
+	3: "FILL",
+	4: "INSIDE",
+	5: "OUTSIDE",
+}
+
+var Fit_value = map[string]int32{
+	"NONE":    0,
+	"CONTAIN": 1,
+	"COVER":   2,
+	"FILL":    3,
+	"INSIDE":  4,
+	"OUTSIDE": 5,
+}
+
+func (x Fit) String() string {
+	return proto.EnumName(Fit_name, int32(x))
+}
+
+func (Fit) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_b0f4449489fcc4ff, []int{2}
+}
+
+// 缩放的重采样滤波器
+type Filter int32
+
+const (
+	// 大倍数缩小时使用linear，否则为lanczos
+	Filter_FILTER_AUTO        Filter = 0
+	Filter_FILTER_NEAREST     Filter = 1
+	Filter_FILTER_BOX         Filter = 2
+	Filter_FILTER_LINEAR      Filter = 3
+	Filter_FILTER_HERMITE     Filter = 4
+	Filter_FILTER_MITCHELL    Filter = 5
+	Filter_FILTER_CATMULL_ROM Filter = 6
+	Filter_FILTER_BSPLINE     Filter = 7
+	Filter_FILTER_GAUSSIAN    Filter = 8
+	Filter_FILTER_LANCZOS     Filter = 9
+)
+
+var Filter_name = map[int32]string{
+	0: "FILTER_AUTO",
+	1: "FILTER_NEAREST",
+	2: "FILTER_BOX",
+	3: "FILTER_LINEAR",
+	4: "FILTER_HERMITE",
+	5: "FILTER_MITCHELL",
+	6: "FILTER_CATMULL_ROM",
+	7: "FILTER_BSPLINE",
+	8: "FILTER_GAUSSIAN",
+	9: "FILTER_LANCZOS",
+}
+
+var Filter_value = map[string]int32{
+	"FILTER_AUTO":        0,
+	"FILTER_NEAREST":     1,
+	"FILTER_BOX":         2,
+	"FILTER_LINEAR":      3,
+	"FILTER_HERMITE":     4,
+	"FILTER_MITCHELL":    5,
+	"FILTER_CATMULL_ROM": 6,
+	"FILTER_BSPLINE":     7,
+	"FILTER_GAUSSIAN":    8,
+	"FILTER_LANCZOS":     9,
+}
+
+func (x Filter) String() string {
+	return proto.EnumName(Filter_name, int32(x))
+}
+
+func (Filter) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_b0f4449489fcc4ff, []int{3}
+}
+
 // The request message for optim
 type OptimRequest struct {
 	// 数据类型
@@ -89,11 +237,45 @@ type OptimRequest struct {
 	Quality uint32 `protobuf:"varint,7,opt,name=quality,proto3" json:"quality,omitempty"`
 	Width   uint32 `protobuf:"varint,8,opt,name=width,proto3" json:"width,omitempty"`
 	Height  uint32 `protobuf:"varint,9,opt,name=height,proto3" json:"height,omitempty"`
-	// 裁剪类型
-	Crop                 uint32   `protobuf:"varint,10,opt,name=crop,proto3" json:"crop,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	// 裁剪类型（1-9为固定位置，10为智能裁剪）
+	Crop uint32 `protobuf:"varint,10,opt,name=crop,proto3" json:"crop,omitempty"`
+	// zstd压缩参数
+	Zstd *ZstdOptions `protobuf:"bytes,11,opt,name=zstd,proto3" json:"zstd,omitempty"`
+	// 压缩使用的字典id（支持zstd与brotli）
+	Dictionary uint32 `protobuf:"varint,12,opt,name=dictionary,proto3" json:"dictionary,omitempty"`
+	// auto输出的候选类型，未指定则为所有文本压缩类型
+	Candidates []Type `protobuf:"varint,13,rep,packed,name=candidates,proto3,enum=pb.Type" json:"candidates,omitempty"`
+	// auto输出的时间限制（毫秒），超时后不再尝试其它类型
+	Timeout uint32 `protobuf:"varint,14,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	// 自定义编码类型的名称，指定时替代source
+	SourceName string `protobuf:"bytes,15,opt,name=source_name,json=sourceName,proto3" json:"source_name,omitempty"`
+	// 自定义编码类型的名称，指定时替代output
+	OutputName string `protobuf:"bytes,16,opt,name=output_name,json=outputName,proto3" json:"output_name,omitempty"`
+	// 不根据exif的方向旋转图片
+	DisableAutoOrient bool `protobuf:"varint,17,opt,name=disable_auto_orient,json=disableAutoOrient,proto3" json:"disable_auto_orient,omitempty"`
+	// 图片元数据的处理方式
+	Metadata Metadata `protobuf:"varint,18,opt,name=metadata,proto3,enum=pb.Metadata" json:"metadata,omitempty"`
+	// 根据图片的icc将像素转换为sRGB
+	ConvertToSrgb bool `protobuf:"varint,19,opt,name=convert_to_srgb,json=convertToSrgb,proto3" json:"convert_to_srgb,omitempty"`
+	// 输出为gif时使用抖动
+	Dither bool `protobuf:"varint,20,opt,name=dither,proto3" json:"dither,omitempty"`
+	// 多页图片（tiff）的页码，从0开始
+	Page uint32 `protobuf:"varint,21,opt,name=page,proto3" json:"page,omitempty"`
+	// 缩放的适配方式
+	Fit Fit `protobuf:"varint,22,opt,name=fit,proto3,enum=pb.Fit" json:"fit,omitempty"`
+	// 不放大小于指定尺寸的图片
+	WithoutEnlargement bool `protobuf:"varint,23,opt,name=without_enlargement,json=withoutEnlargement,proto3" json:"without_enlargement,omitempty"`
+	// 缩放至覆盖尺寸后按焦点裁剪
+	Focal *FocalPoint `protobuf:"bytes,24,opt,name=focal,proto3" json:"focal,omitempty"`
+	// 缩放前裁剪的区域（像素）
+	Rect *CropRect `protobuf:"bytes,25,opt,name=rect,proto3" json:"rect,omitempty"`
+	// 缩放的重采样滤波器
+	Filter Filter `protobuf:"varint,26,opt,name=filter,proto3,enum=pb.Filter" json:"filter,omitempty"`
+	// 缩放后按顺序执行的操作
+	Operations           []*Operation `protobuf:"bytes,27,rep,name=operations,proto3" json:"operations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
 }
 
 func (m *OptimRequest) Reset()         { *m = OptimRequest{} }
@@ -178,29 +360,150 @@ func (m *OptimRequest) GetCrop() uint32 {
 	return 0
 }
 
-// The response message for optim
-type OptimReply struct {
-	Output               Type     `protobuf:"varint,1,opt,name=output,proto3,enum=pb.Type" json:"output,omitempty"`
-	Data                 []byte   `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
-	Width                uint32   `protobuf:"varint,8,opt,name=width,proto3" json:"width,omitempty"`
-	Height               uint32   `protobuf:"varint,9,opt,name=height,proto3" json:"height,omitempty"`
+func (m *OptimRequest) GetZstd() *ZstdOptions {
+	if m != nil {
+		return m.Zstd
+	}
+	return nil
+}
+
+func (m *OptimRequest) GetDictionary() uint32 {
+	if m != nil {
+		return m.Dictionary
+	}
+	return 0
+}
+
+func (m *OptimRequest) GetCandidates() []Type {
+	if m != nil {
+		return m.Candidates
+	}
+	return nil
+}
+
+func (m *OptimRequest) GetTimeout() uint32 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
+}
+
+func (m *OptimRequest) GetSourceName() string {
+	if m != nil {
+		return m.SourceName
+	}
+	return ""
+}
+
+func (m *OptimRequest) GetOutputName() string {
+	if m != nil {
+		return m.OutputName
+	}
+	return ""
+}
+
+func (m *OptimRequest) GetDisableAutoOrient() bool {
+	if m != nil {
+		return m.DisableAutoOrient
+	}
+	return false
+}
+
+func (m *OptimRequest) GetMetadata() Metadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return Metadata_STRIP
+}
+
+func (m *OptimRequest) GetConvertToSrgb() bool {
+	if m != nil {
+		return m.ConvertToSrgb
+	}
+	return false
+}
+
+func (m *OptimRequest) GetDither() bool {
+	if m != nil {
+		return m.Dither
+	}
+	return false
+}
+
+func (m *OptimRequest) GetPage() uint32 {
+	if m != nil {
+		return m.Page
+	}
+	return 0
+}
+
+func (m *OptimRequest) GetFit() Fit {
+	if m != nil {
+		return m.Fit
+	}
+	return Fit_NONE
+}
+
+func (m *OptimRequest) GetWithoutEnlargement() bool {
+	if m != nil {
+		return m.WithoutEnlargement
+	}
+	return false
+}
+
+func (m *OptimRequest) GetFocal() *FocalPoint {
+	if m != nil {
+		return m.Focal
+	}
+	return nil
+}
+
+func (m *OptimRequest) GetRect() *CropRect {
+	if m != nil {
+		return m.Rect
+	}
+	return nil
+}
+
+func (m *OptimRequest) GetFilter() Filter {
+	if m != nil {
+		return m.Filter
+	}
+	return Filter_FILTER_AUTO
+}
+
+func (m *OptimRequest) GetOperations() []*Operation {
+	if m != nil {
+		return m.Operations
+	}
+	return nil
+}
+
+// The zstd encoder options
+type ZstdOptions struct {
+	// 窗口大小，需为1KB至256MB（最大解压数据）之间2的幂
+	WindowSize uint32 `protobuf:"varint,1,opt,name=window_size,json=windowSize,proto3" json:"window_size,omitempty"`
+	// 是否禁用校验
+	DisableChecksum bool `protobuf:"varint,2,opt,name=disable_checksum,json=disableChecksum,proto3" json:"disable_checksum,omitempty"`
+	// 流式压缩时的并发数
+	Concurrency          uint32   `protobuf:"varint,3,opt,name=concurrency,proto3" json:"concurrency,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *OptimReply) Reset()         { *m = OptimReply{} }
-func (m *OptimReply) String() string { return proto.CompactTextString(m) }
-func (*OptimReply) ProtoMessage()    {}
-func (*OptimReply) Descriptor() ([]byte, []int) {
+func (m *ZstdOptions) Reset()         { *m = ZstdOptions{} }
+func (m *ZstdOptions) String() string { return proto.CompactTextString(m) }
+func (*ZstdOptions) ProtoMessage()    {}
+func (*ZstdOptions) Descriptor() ([]byte, []int) {
 	return fileDescriptor_b0f4449489fcc4ff, []int{1}
 }
-func (m *OptimReply) XXX_Unmarshal(b []byte) error {
+func (m *ZstdOptions) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
 }
-func (m *OptimReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+func (m *ZstdOptions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 	if deterministic {
-		return xxx_messageInfo_OptimReply.Marshal(b, m, deterministic)
+		return xxx_messageInfo_ZstdOptions.Marshal(b, m, deterministic)
 	} else {
 		b = b[:cap(b)]
 		n, err := m.MarshalToSizedBuffer(b)
@@ -210,350 +513,3141 @@ func (m *OptimReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 		return b[:n], nil
 	}
 }
-func (m *OptimReply) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_OptimReply.Merge(m, src)
+func (m *ZstdOptions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ZstdOptions.Merge(m, src)
 }
-func (m *OptimReply) XXX_Size() int {
+func (m *ZstdOptions) XXX_Size() int {
 	return m.Size()
 }
-func (m *OptimReply) XXX_DiscardUnknown() {
-	xxx_messageInfo_OptimReply.DiscardUnknown(m)
+func (m *ZstdOptions) XXX_DiscardUnknown() {
+	xxx_messageInfo_ZstdOptions.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_OptimReply proto.InternalMessageInfo
+var xxx_messageInfo_ZstdOptions proto.InternalMessageInfo
 
-func (m *OptimReply) GetOutput() Type {
+func (m *ZstdOptions) GetWindowSize() uint32 {
 	if m != nil {
-		return m.Output
+		return m.WindowSize
 	}
-	return Type_UNKNOWN
+	return 0
 }
 
-func (m *OptimReply) GetData() []byte {
+func (m *ZstdOptions) GetDisableChecksum() bool {
 	if m != nil {
-		return m.Data
+		return m.DisableChecksum
 	}
-	return nil
+	return false
 }
 
-func (m *OptimReply) GetWidth() uint32 {
+func (m *ZstdOptions) GetConcurrency() uint32 {
 	if m != nil {
-		return m.Width
+		return m.Concurrency
 	}
 	return 0
 }
 
-func (m *OptimReply) GetHeight() uint32 {
+// 焦点，取值为0-1
+type FocalPoint struct {
+	X                    float32  `protobuf:"fixed32,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y                    float32  `protobuf:"fixed32,2,opt,name=y,proto3" json:"y,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FocalPoint) Reset()         { *m = FocalPoint{} }
+func (m *FocalPoint) String() string { return proto.CompactTextString(m) }
+func (*FocalPoint) ProtoMessage()    {}
+func (*FocalPoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b0f4449489fcc4ff, []int{2}
+}
+func (m *FocalPoint) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *FocalPoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_FocalPoint.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *FocalPoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FocalPoint.Merge(m, src)
+}
+func (m *FocalPoint) XXX_Size() int {
+	return m.Size()
+}
+func (m *FocalPoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_FocalPoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FocalPoint proto.InternalMessageInfo
+
+func (m *FocalPoint) GetX() float32 {
 	if m != nil {
-		return m.Height
+		return m.X
 	}
 	return 0
 }
 
-func init() {
-	proto.RegisterEnum("pb.Type", Type_name, Type_value)
-	proto.RegisterType((*OptimRequest)(nil), "pb.OptimRequest")
-	proto.RegisterType((*OptimReply)(nil), "pb.OptimReply")
+func (m *FocalPoint) GetY() float32 {
+	if m != nil {
+		return m.Y
+	}
+	return 0
 }
 
-func init() { proto.RegisterFile("optim.proto", fileDescriptor_b0f4449489fcc4ff) }
-
-var fileDescriptor_b0f4449489fcc4ff = []byte{
-	// 338 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x91, 0x4f, 0x4b, 0x02, 0x41,
-	0x18, 0xc6, 0x9d, 0x75, 0xdd, 0xb5, 0xd7, 0x3f, 0x0c, 0x2f, 0x11, 0x43, 0x87, 0x65, 0xf1, 0x24,
-	0x41, 0x1e, 0x2c, 0xba, 0x2b, 0x9a, 0x58, 0xb1, 0x0e, 0xab, 0x25, 0x79, 0xf3, 0xcf, 0x92, 0x4b,
-	0xc6, 0x8c, 0x3a, 0x4b, 0xec, 0x37, 0xe9, 0xf3, 0x74, 0xea, 0xd8, 0x47, 0x08, 0xfb, 0x22, 0x31,
-	0xa3, 0x81, 0x44, 0x1d, 0xba, 0x3d, 0xcf, 0x6f, 0xe6, 0x9d, 0x79, 0x1e, 0x5e, 0x28, 0x08, 0xa9,
-	0xe2, 0xa7, 0x9a, 0x5c, 0x09, 0x25, 0xd0, 0x92, 0x93, 0xca, 0x2b, 0x81, 0x62, 0x4f, 0xb3, 0x30,
-	0x5a, 0x26, 0xd1, 0x5a, 0xa1, 0x0f, 0xce, 0x5a, 0x24, 0xab, 0x69, 0xc4, 0x88, 0x4f, 0xaa, 0xe5,
-	0x7a, 0xbe, 0x26, 0x27, 0xb5, 0x41, 0x2a, 0xa3, 0x70, 0xc7, 0x11, 0xc1, 0x9e, 0x8d, 0xd5, 0x98,
-	0x59, 0x3e, 0xa9, 0x16, 0x43, 0xa3, 0xf5, 0x94, 0x48, 0x94, 0x4c, 0x14, 0x73, 0x7e, 0x4e, 0x6d,
-	0x39, 0x32, 0x70, 0x97, 0xc9, 0x78, 0x11, 0xab, 0x94, 0xb9, 0x3e, 0xa9, 0x96, 0xc2, 0x6f, 0x8b,
-	0x87, 0x90, 0x7b, 0x8e, 0x67, 0x6a, 0xce, 0xf2, 0x86, 0x6f, 0x0d, 0x1e, 0x81, 0x33, 0x8f, 0xe2,
-	0x87, 0xb9, 0x62, 0x07, 0x06, 0xef, 0x9c, 0xfe, 0x7d, 0xba, 0x12, 0x92, 0x81, 0xa1, 0x46, 0x57,
-	0x24, 0xc0, 0xae, 0x83, 0x5c, 0xa4, 0x7b, 0x59, 0xc8, 0x1f, 0x59, 0x7e, 0x6b, 0xf0, 0xaf, 0x14,
-	0x27, 0x8f, 0x60, 0xeb, 0x17, 0xb1, 0x00, 0xee, 0x6d, 0x70, 0x1d, 0xf4, 0x86, 0x01, 0xcd, 0x60,
-	0x1e, 0xec, 0xce, 0xa8, 0xcb, 0x29, 0x41, 0x07, 0xac, 0x66, 0x48, 0x2d, 0x04, 0x70, 0xfa, 0x41,
-	0x83, 0xf3, 0x7b, 0x9a, 0x45, 0x17, 0xb2, 0x37, 0xa3, 0x73, 0x6a, 0xeb, 0x6b, 0xa3, 0xfe, 0xa0,
-	0x45, 0x73, 0x5a, 0x5d, 0xf1, 0x76, 0x87, 0x16, 0xf4, 0x21, 0x0f, 0x3a, 0xb4, 0xa8, 0xd1, 0xb0,
-	0xdd, 0xe4, 0xb4, 0xa4, 0x55, 0xe3, 0xae, 0x7b, 0x49, 0xcb, 0xf5, 0x0b, 0xc8, 0x99, 0x7a, 0x78,
-	0x0a, 0x6e, 0x4b, 0x6c, 0x25, 0xd5, 0xa5, 0xf6, 0x17, 0x77, 0x5c, 0xde, 0x23, 0x72, 0x91, 0x56,
-	0x32, 0x4d, 0xfa, 0xb6, 0xf1, 0xc8, 0xfb, 0xc6, 0x23, 0x1f, 0x1b, 0x8f, 0xbc, 0x7c, 0x7a, 0x99,
-	0x89, 0x63, 0x16, 0x7f, 0xf6, 0x15, 0x00, 0x00, 0xff, 0xff, 0xdf, 0x46, 0x05, 0xee, 0x07, 0x02,
-	0x00, 0x00,
+// 图片操作
+type Operation struct {
+	// 操作名称：rotate、flipH、flipV、blur、sharpen、gamma、brightness、contrast、saturation与grayscale
+	Op string `protobuf:"bytes,1,opt,name=op,proto3" json:"op,omitempty"`
+	// 顺时针旋转的角度
+	Angle float32 `protobuf:"fixed32,2,opt,name=angle,proto3" json:"angle,omitempty"`
+	// blur与sharpen的sigma
+	Sigma float32 `protobuf:"fixed32,3,opt,name=sigma,proto3" json:"sigma,omitempty"`
+	// gamma、brightness、contrast与saturation的值
+	Value                float32  `protobuf:"fixed32,4,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *Operation) Reset()         { *m = Operation{} }
+func (m *Operation) String() string { return proto.CompactTextString(m) }
+func (*Operation) ProtoMessage()    {}
+func (*Operation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b0f4449489fcc4ff, []int{3}
+}
+func (m *Operation) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Operation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Operation.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Operation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Operation.Merge(m, src)
+}
+func (m *Operation) XXX_Size() int {
+	return m.Size()
+}
+func (m *Operation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Operation.DiscardUnknown(m)
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+var xxx_messageInfo_Operation proto.InternalMessageInfo
 
-// OptimClient is the client API for Optim service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type OptimClient interface {
-	DoOptim(ctx context.Context, in *OptimRequest, opts ...grpc.CallOption) (*OptimReply, error)
+func (m *Operation) GetOp() string {
+	if m != nil {
+		return m.Op
+	}
+	return ""
 }
 
-type optimClient struct {
-	cc *grpc.ClientConn
+func (m *Operation) GetAngle() float32 {
+	if m != nil {
+		return m.Angle
+	}
+	return 0
 }
 
-func NewOptimClient(cc *grpc.ClientConn) OptimClient {
-	return &optimClient{cc}
+func (m *Operation) GetSigma() float32 {
+	if m != nil {
+		return m.Sigma
+	}
+	return 0
 }
 
-func (c *optimClient) DoOptim(ctx context.Context, in *OptimRequest, opts ...grpc.CallOption) (*OptimReply, error) {
-	out := new(OptimReply)
-	err := c.cc.Invoke(ctx, "/pb.Optim/DoOptim", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *Operation) GetValue() float32 {
+	if m != nil {
+		return m.Value
 	}
-	return out, nil
+	return 0
 }
 
-// OptimServer is the server API for Optim service.
-type OptimServer interface {
-	DoOptim(context.Context, *OptimRequest) (*OptimReply, error)
+// 裁剪区域
+type CropRect struct {
+	X                    uint32   `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y                    uint32   `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+	Width                uint32   `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height               uint32   `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-// UnimplementedOptimServer can be embedded to have forward compatible implementations.
-type UnimplementedOptimServer struct {
+func (m *CropRect) Reset()         { *m = CropRect{} }
+func (m *CropRect) String() string { return proto.CompactTextString(m) }
+func (*CropRect) ProtoMessage()    {}
+func (*CropRect) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b0f4449489fcc4ff, []int{4}
 }
-
-func (*UnimplementedOptimServer) DoOptim(ctx context.Context, req *OptimRequest) (*OptimReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DoOptim not implemented")
+func (m *CropRect) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
 }
-
-func RegisterOptimServer(s *grpc.Server, srv OptimServer) {
-	s.RegisterService(&_Optim_serviceDesc, srv)
+func (m *CropRect) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CropRect.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CropRect) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CropRect.Merge(m, src)
+}
+func (m *CropRect) XXX_Size() int {
+	return m.Size()
+}
+func (m *CropRect) XXX_DiscardUnknown() {
+	xxx_messageInfo_CropRect.DiscardUnknown(m)
 }
 
-func _Optim_DoOptim_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(OptimRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(OptimServer).DoOptim(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.Optim/DoOptim",
+var xxx_messageInfo_CropRect proto.InternalMessageInfo
+
+func (m *CropRect) GetX() uint32 {
+	if m != nil {
+		return m.X
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(OptimServer).DoOptim(ctx, req.(*OptimRequest))
+	return 0
+}
+
+func (m *CropRect) GetY() uint32 {
+	if m != nil {
+		return m.Y
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
 }
 
-var _Optim_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "pb.Optim",
-	HandlerType: (*OptimServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "DoOptim",
-			Handler:    _Optim_DoOptim_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "optim.proto",
+func (m *CropRect) GetWidth() uint32 {
+	if m != nil {
+		return m.Width
+	}
+	return 0
 }
 
-func (m *OptimRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+func (m *CropRect) GetHeight() uint32 {
+	if m != nil {
+		return m.Height
 	}
-	return dAtA[:n], nil
+	return 0
 }
 
-func (m *OptimRequest) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+// The response message for optim
+type OptimReply struct {
+	Output Type   `protobuf:"varint,1,opt,name=output,proto3,enum=pb.Type" json:"output,omitempty"`
+	Data   []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Width  uint32 `protobuf:"varint,8,opt,name=width,proto3" json:"width,omitempty"`
+	Height uint32 `protobuf:"varint,9,opt,name=height,proto3" json:"height,omitempty"`
+	// 压缩使用的字典id
+	Dictionary uint32 `protobuf:"varint,10,opt,name=dictionary,proto3" json:"dictionary,omitempty"`
+	// 输出类型的名称
+	OutputName string `protobuf:"bytes,11,opt,name=output_name,json=outputName,proto3" json:"output_name,omitempty"`
+	// 图片实际使用的编码器，如cjpeg或兜底的image/jpeg
+	Encoder              string   `protobuf:"bytes,12,opt,name=encoder,proto3" json:"encoder,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *OptimRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i -= len(m.XXX_unrecognized)
-		copy(dAtA[i:], m.XXX_unrecognized)
-	}
-	if m.Crop != 0 {
-		i = encodeVarintOptim(dAtA, i, uint64(m.Crop))
-		i--
-		dAtA[i] = 0x50
+func (m *OptimReply) Reset()         { *m = OptimReply{} }
+func (m *OptimReply) String() string { return proto.CompactTextString(m) }
+func (*OptimReply) ProtoMessage()    {}
+func (*OptimReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b0f4449489fcc4ff, []int{5}
+}
+func (m *OptimReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OptimReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OptimReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	if m.Height != 0 {
-		i = encodeVarintOptim(dAtA, i, uint64(m.Height))
-		i--
-		dAtA[i] = 0x48
+}
+func (m *OptimReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OptimReply.Merge(m, src)
+}
+func (m *OptimReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *OptimReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_OptimReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OptimReply proto.InternalMessageInfo
+
+func (m *OptimReply) GetOutput() Type {
+	if m != nil {
+		return m.Output
 	}
-	if m.Width != 0 {
-		i = encodeVarintOptim(dAtA, i, uint64(m.Width))
-		i--
-		dAtA[i] = 0x40
+	return Type_UNKNOWN
+}
+
+func (m *OptimReply) GetData() []byte {
+	if m != nil {
+		return m.Data
 	}
-	if m.Quality != 0 {
-		i = encodeVarintOptim(dAtA, i, uint64(m.Quality))
-		i--
-		dAtA[i] = 0x38
+	return nil
+}
+
+func (m *OptimReply) GetWidth() uint32 {
+	if m != nil {
+		return m.Width
 	}
-	if m.Output != 0 {
-		i = encodeVarintOptim(dAtA, i, uint64(m.Output))
-		i--
-		dAtA[i] = 0x30
+	return 0
+}
+
+func (m *OptimReply) GetHeight() uint32 {
+	if m != nil {
+		return m.Height
 	}
-	if len(m.Data) > 0 {
-		i -= len(m.Data)
-		copy(dAtA[i:], m.Data)
-		i = encodeVarintOptim(dAtA, i, uint64(len(m.Data)))
-		i--
-		dAtA[i] = 0x12
+	return 0
+}
+
+func (m *OptimReply) GetDictionary() uint32 {
+	if m != nil {
+		return m.Dictionary
 	}
-	if m.Source != 0 {
-		i = encodeVarintOptim(dAtA, i, uint64(m.Source))
-		i--
-		dAtA[i] = 0x8
+	return 0
+}
+
+func (m *OptimReply) GetOutputName() string {
+	if m != nil {
+		return m.OutputName
 	}
-	return len(dAtA) - i, nil
+	return ""
 }
 
-func (m *OptimReply) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+func (m *OptimReply) GetEncoder() string {
+	if m != nil {
+		return m.Encoder
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *OptimReply) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+// The request message for capabilities
+type CapabilitiesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *OptimReply) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i -= len(m.XXX_unrecognized)
-		copy(dAtA[i:], m.XXX_unrecognized)
+func (m *CapabilitiesRequest) Reset()         { *m = CapabilitiesRequest{} }
+func (m *CapabilitiesRequest) String() string { return proto.CompactTextString(m) }
+func (*CapabilitiesRequest) ProtoMessage()    {}
+func (*CapabilitiesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b0f4449489fcc4ff, []int{6}
+}
+func (m *CapabilitiesRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CapabilitiesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CapabilitiesRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	if m.Height != 0 {
-		i = encodeVarintOptim(dAtA, i, uint64(m.Height))
-		i--
-		dAtA[i] = 0x48
+}
+func (m *CapabilitiesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CapabilitiesRequest.Merge(m, src)
+}
+func (m *CapabilitiesRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *CapabilitiesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CapabilitiesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CapabilitiesRequest proto.InternalMessageInfo
+
+// The external tool of encoder
+type Tool struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Installed            bool     `protobuf:"varint,2,opt,name=installed,proto3" json:"installed,omitempty"`
+	Path                 string   `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Version              string   `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Tool) Reset()         { *m = Tool{} }
+func (m *Tool) String() string { return proto.CompactTextString(m) }
+func (*Tool) ProtoMessage()    {}
+func (*Tool) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b0f4449489fcc4ff, []int{7}
+}
+func (m *Tool) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Tool) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Tool.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	if m.Width != 0 {
-		i = encodeVarintOptim(dAtA, i, uint64(m.Width))
-		i--
-		dAtA[i] = 0x40
+}
+func (m *Tool) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Tool.Merge(m, src)
+}
+func (m *Tool) XXX_Size() int {
+	return m.Size()
+}
+func (m *Tool) XXX_DiscardUnknown() {
+	xxx_messageInfo_Tool.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Tool proto.InternalMessageInfo
+
+func (m *Tool) GetName() string {
+	if m != nil {
+		return m.Name
 	}
-	if len(m.Data) > 0 {
-		i -= len(m.Data)
-		copy(dAtA[i:], m.Data)
-		i = encodeVarintOptim(dAtA, i, uint64(len(m.Data)))
-		i--
-		dAtA[i] = 0x12
+	return ""
+}
+
+func (m *Tool) GetInstalled() bool {
+	if m != nil {
+		return m.Installed
 	}
-	if m.Output != 0 {
-		i = encodeVarintOptim(dAtA, i, uint64(m.Output))
-		i--
-		dAtA[i] = 0x8
+	return false
+}
+
+func (m *Tool) GetPath() string {
+	if m != nil {
+		return m.Path
 	}
-	return len(dAtA) - i, nil
+	return ""
 }
 
-func encodeVarintOptim(dAtA []byte, offset int, v uint64) int {
-	offset -= sovOptim(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *Tool) GetVersion() string {
+	if m != nil {
+		return m.Version
 	}
-	dAtA[offset] = uint8(v)
-	return base
+	return ""
 }
-func (m *OptimRequest) Size() (n int) {
-	if m == nil {
-		return 0
+
+// The response message for capabilities
+type CapabilitiesReply struct {
+	// 可用的图片输入类型
+	ImageInputs []string `protobuf:"bytes,1,rep,name=image_inputs,json=imageInputs,proto3" json:"image_inputs,omitempty"`
+	// 可用的图片输出类型
+	ImageOutputs []string `protobuf:"bytes,2,rep,name=image_outputs,json=imageOutputs,proto3" json:"image_outputs,omitempty"`
+	// 可用的文本数据源类型
+	TextInputs []string `protobuf:"bytes,3,rep,name=text_inputs,json=textInputs,proto3" json:"text_inputs,omitempty"`
+	// 可用的文本输出类型
+	TextOutputs []string `protobuf:"bytes,4,rep,name=text_outputs,json=textOutputs,proto3" json:"text_outputs,omitempty"`
+	Tools       []*Tool  `protobuf:"bytes,5,rep,name=tools,proto3" json:"tools,omitempty"`
+	// 兜底策略
+	Fallback             string   `protobuf:"bytes,6,opt,name=fallback,proto3" json:"fallback,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CapabilitiesReply) Reset()         { *m = CapabilitiesReply{} }
+func (m *CapabilitiesReply) String() string { return proto.CompactTextString(m) }
+func (*CapabilitiesReply) ProtoMessage()    {}
+func (*CapabilitiesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b0f4449489fcc4ff, []int{8}
+}
+func (m *CapabilitiesReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CapabilitiesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CapabilitiesReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CapabilitiesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CapabilitiesReply.Merge(m, src)
+}
+func (m *CapabilitiesReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *CapabilitiesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_CapabilitiesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CapabilitiesReply proto.InternalMessageInfo
+
+func (m *CapabilitiesReply) GetImageInputs() []string {
+	if m != nil {
+		return m.ImageInputs
+	}
+	return nil
+}
+
+func (m *CapabilitiesReply) GetImageOutputs() []string {
+	if m != nil {
+		return m.ImageOutputs
+	}
+	return nil
+}
+
+func (m *CapabilitiesReply) GetTextInputs() []string {
+	if m != nil {
+		return m.TextInputs
+	}
+	return nil
+}
+
+func (m *CapabilitiesReply) GetTextOutputs() []string {
+	if m != nil {
+		return m.TextOutputs
+	}
+	return nil
+}
+
+func (m *CapabilitiesReply) GetTools() []*Tool {
+	if m != nil {
+		return m.Tools
+	}
+	return nil
+}
+
+func (m *CapabilitiesReply) GetFallback() string {
+	if m != nil {
+		return m.Fallback
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterEnum("pb.Type", Type_name, Type_value)
+	proto.RegisterEnum("pb.Metadata", Metadata_name, Metadata_value)
+	proto.RegisterEnum("pb.Fit", Fit_name, Fit_value)
+	proto.RegisterEnum("pb.Filter", Filter_name, Filter_value)
+	proto.RegisterType((*OptimRequest)(nil), "pb.OptimRequest")
+	proto.RegisterType((*ZstdOptions)(nil), "pb.ZstdOptions")
+	proto.RegisterType((*FocalPoint)(nil), "pb.FocalPoint")
+	proto.RegisterType((*Operation)(nil), "pb.Operation")
+	proto.RegisterType((*CropRect)(nil), "pb.CropRect")
+	proto.RegisterType((*OptimReply)(nil), "pb.OptimReply")
+	proto.RegisterType((*CapabilitiesRequest)(nil), "pb.CapabilitiesRequest")
+	proto.RegisterType((*Tool)(nil), "pb.Tool")
+	proto.RegisterType((*CapabilitiesReply)(nil), "pb.CapabilitiesReply")
+}
+
+func init() { proto.RegisterFile("optim.proto", fileDescriptor_b0f4449489fcc4ff) }
+
+var fileDescriptor_b0f4449489fcc4ff = []byte{
+	// 1266 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x96, 0xdd, 0x6e, 0xdb, 0xc6,
+	0x12, 0x80, 0x4d, 0xfd, 0x6b, 0xf4, 0xb7, 0x5e, 0xe7, 0x67, 0xe3, 0x73, 0xe0, 0xa3, 0x28, 0x07,
+	0x85, 0x6a, 0x20, 0x2e, 0xe0, 0xf6, 0x05, 0x64, 0x45, 0x52, 0x98, 0xc8, 0xa4, 0xb0, 0xa2, 0x93,
+	0xc6, 0x40, 0x21, 0x50, 0xd4, 0x5a, 0x26, 0x42, 0x71, 0x19, 0x72, 0x15, 0x47, 0xc9, 0x53, 0xf4,
+	0xae, 0xd7, 0x7d, 0x9a, 0x02, 0x05, 0x8a, 0xde, 0xf7, 0xa6, 0x48, 0x5f, 0xa4, 0xd8, 0x5d, 0x2a,
+	0xa6, 0xe3, 0xf4, 0xa2, 0x77, 0x33, 0xdf, 0xcc, 0xce, 0xec, 0xcc, 0x72, 0x46, 0x82, 0x1a, 0x8f,
+	0x84, 0xbf, 0x3a, 0x8a, 0x62, 0x2e, 0x38, 0xce, 0x45, 0xf3, 0xce, 0xcf, 0x25, 0xa8, 0xdb, 0x92,
+	0x51, 0xf6, 0x66, 0xcd, 0x12, 0x81, 0xdb, 0x50, 0x4a, 0xf8, 0x3a, 0xf6, 0x18, 0x31, 0xda, 0x46,
+	0xb7, 0x79, 0x5c, 0x39, 0x8a, 0xe6, 0x47, 0xce, 0x26, 0x62, 0x34, 0xe5, 0x18, 0x43, 0x61, 0xe1,
+	0x0a, 0x97, 0xe4, 0xda, 0x46, 0xb7, 0x4e, 0x95, 0x2c, 0x4f, 0xf1, 0xb5, 0x88, 0xd6, 0x82, 0x94,
+	0x3e, 0x3f, 0xa5, 0x39, 0x26, 0x50, 0x7e, 0xb3, 0x76, 0x03, 0x5f, 0x6c, 0x48, 0xb9, 0x6d, 0x74,
+	0x1b, 0x74, 0xab, 0xe2, 0x3b, 0x50, 0xbc, 0xf2, 0x17, 0xe2, 0x92, 0x54, 0x14, 0xd7, 0x0a, 0xbe,
+	0x07, 0xa5, 0x4b, 0xe6, 0x2f, 0x2f, 0x05, 0xa9, 0x2a, 0x9c, 0x6a, 0x32, 0xbb, 0x17, 0xf3, 0x88,
+	0x80, 0xa2, 0x4a, 0xc6, 0x8f, 0xa0, 0xf0, 0x3e, 0x11, 0x0b, 0x52, 0x6b, 0x1b, 0xdd, 0xda, 0x71,
+	0x4b, 0xe6, 0x3e, 0x4f, 0xc4, 0x42, 0xd6, 0xc5, 0xc3, 0x84, 0x2a, 0x23, 0x3e, 0x00, 0x58, 0xf8,
+	0x9e, 0x24, 0x6e, 0xbc, 0x21, 0x75, 0x75, 0x3c, 0x43, 0x70, 0x17, 0xc0, 0x73, 0xc3, 0x85, 0xbf,
+	0x70, 0x05, 0x4b, 0x48, 0xa3, 0x9d, 0xbf, 0x51, 0x46, 0xc6, 0x26, 0x4b, 0x11, 0xfe, 0x8a, 0xf1,
+	0xb5, 0x20, 0x4d, 0x5d, 0x4a, 0xaa, 0xe2, 0xff, 0x41, 0x4d, 0x37, 0x69, 0x16, 0xba, 0x2b, 0x46,
+	0x5a, 0x6d, 0xa3, 0x5b, 0xa5, 0xa0, 0x91, 0xe5, 0xae, 0x98, 0x74, 0xd0, 0xfd, 0xd0, 0x0e, 0x48,
+	0x3b, 0x68, 0xa4, 0x1c, 0x8e, 0x60, 0x6f, 0xe1, 0x27, 0xee, 0x3c, 0x60, 0x33, 0x77, 0x2d, 0xf8,
+	0x8c, 0xc7, 0x3e, 0x0b, 0x05, 0xd9, 0x6d, 0x1b, 0xdd, 0x0a, 0xdd, 0x4d, 0x4d, 0xbd, 0xb5, 0xe0,
+	0xb6, 0x32, 0xe0, 0x2e, 0x54, 0x56, 0x4c, 0xb8, 0xea, 0x41, 0xb0, 0x6a, 0x7d, 0x5d, 0xde, 0xf9,
+	0x34, 0x65, 0xf4, 0x93, 0x15, 0x7f, 0x05, 0x2d, 0x8f, 0x87, 0x6f, 0x59, 0x2c, 0x66, 0x82, 0xcf,
+	0x92, 0x78, 0x39, 0x27, 0x7b, 0x2a, 0x6a, 0x23, 0xc5, 0x0e, 0x9f, 0xc6, 0xcb, 0xb9, 0x6c, 0xfc,
+	0xc2, 0x17, 0x97, 0x2c, 0x26, 0x77, 0x94, 0x39, 0xd5, 0x64, 0xe3, 0x23, 0x77, 0xc9, 0xc8, 0x5d,
+	0xdd, 0x78, 0x29, 0xe3, 0x07, 0x90, 0xbf, 0xf0, 0x05, 0xb9, 0xa7, 0x12, 0x97, 0x65, 0xe2, 0xa1,
+	0x2f, 0xa8, 0x64, 0xf8, 0x1b, 0xd8, 0xbb, 0xf2, 0xc5, 0x25, 0x5f, 0x8b, 0x19, 0x0b, 0x03, 0x37,
+	0x5e, 0xb2, 0x95, 0x2c, 0xe4, 0xbe, 0x8a, 0x89, 0x53, 0xd3, 0xe0, 0xda, 0x82, 0xff, 0x0f, 0xc5,
+	0x0b, 0xee, 0xb9, 0x01, 0x21, 0xea, 0x15, 0x9b, 0x2a, 0x9a, 0x04, 0x13, 0xee, 0x87, 0x82, 0x6a,
+	0x23, 0x6e, 0x43, 0x21, 0x66, 0x9e, 0x20, 0x0f, 0x94, 0x93, 0xaa, 0xb5, 0x1f, 0xf3, 0x88, 0x32,
+	0x4f, 0x50, 0x65, 0xc1, 0x1d, 0x28, 0x5d, 0xf8, 0x81, 0x60, 0x31, 0xd9, 0x57, 0xd7, 0x02, 0x7d,
+	0x2d, 0x49, 0x68, 0x6a, 0xc1, 0x8f, 0x01, 0x78, 0xc4, 0x62, 0x57, 0x7d, 0x1f, 0xe4, 0x3f, 0xed,
+	0x7c, 0xb7, 0x76, 0xdc, 0x90, 0x7e, 0xf6, 0x96, 0xd2, 0x8c, 0x43, 0xe7, 0x03, 0xd4, 0x32, 0xdf,
+	0x93, 0x7c, 0xc4, 0x2b, 0x3f, 0x5c, 0xf0, 0xab, 0x59, 0xe2, 0xbf, 0xd7, 0x73, 0xd2, 0xa0, 0xa0,
+	0xd1, 0xd4, 0x7f, 0xcf, 0xf0, 0xd7, 0x80, 0xb6, 0x8f, 0xe8, 0x5d, 0x32, 0xef, 0x75, 0xb2, 0x5e,
+	0xa9, 0x69, 0xa9, 0xd0, 0x56, 0xca, 0xfb, 0x29, 0xc6, 0x6d, 0xa8, 0x79, 0x3c, 0xf4, 0xd6, 0x71,
+	0xcc, 0x42, 0x6f, 0x43, 0xf2, 0x2a, 0x56, 0x16, 0x75, 0xba, 0x00, 0xd7, 0x6d, 0xc0, 0x75, 0x30,
+	0xde, 0xa9, 0x8c, 0x39, 0x6a, 0xbc, 0x93, 0xda, 0x46, 0x45, 0xce, 0x51, 0x63, 0xd3, 0xf9, 0x01,
+	0xaa, 0x9f, 0xee, 0x8f, 0x9b, 0x90, 0xe3, 0x91, 0xf2, 0xac, 0xd2, 0x1c, 0x8f, 0xe4, 0x94, 0xb9,
+	0xe1, 0x32, 0x60, 0xa9, 0xbb, 0x56, 0x24, 0x4d, 0xfc, 0xe5, 0xca, 0x55, 0x89, 0x73, 0x54, 0x2b,
+	0x92, 0xbe, 0x75, 0x83, 0x35, 0x23, 0x05, 0x4d, 0x95, 0xd2, 0x71, 0xa0, 0xb2, 0x6d, 0xf5, 0xf5,
+	0x35, 0x1a, 0x37, 0xae, 0xd1, 0xa0, 0x46, 0x66, 0x9e, 0xf3, 0x5f, 0x9e, 0xe7, 0x42, 0x76, 0x9e,
+	0x3b, 0xbf, 0x1a, 0x00, 0xe9, 0x02, 0x8a, 0x82, 0x4d, 0x66, 0x91, 0x18, 0xff, 0xb0, 0x48, 0xbe,
+	0xb4, 0x7e, 0xfe, 0xdd, 0x0a, 0xb9, 0xb9, 0x09, 0xe0, 0xd6, 0x26, 0xf8, 0x6c, 0x48, 0x6b, 0xb7,
+	0x86, 0x94, 0x40, 0x99, 0x85, 0x1e, 0x5f, 0xb0, 0x58, 0xed, 0x91, 0x2a, 0xdd, 0xaa, 0x9d, 0xbb,
+	0xb0, 0xd7, 0x77, 0x23, 0x77, 0xee, 0x07, 0xbe, 0xf0, 0x59, 0x92, 0x2e, 0xd5, 0xce, 0x05, 0x14,
+	0x1c, 0xce, 0x03, 0x79, 0x77, 0x15, 0x52, 0x3f, 0x8b, 0x92, 0xf1, 0x7f, 0xa1, 0xea, 0x87, 0x89,
+	0x70, 0x83, 0x80, 0x2d, 0xd2, 0xaf, 0xe4, 0x1a, 0xe8, 0xa9, 0x4b, 0x7b, 0x59, 0xa5, 0x4a, 0x96,
+	0xe9, 0xdf, 0xb2, 0x38, 0xf1, 0x79, 0xa8, 0x7a, 0x59, 0xa5, 0x5b, 0xb5, 0xf3, 0x87, 0x01, 0xbb,
+	0x37, 0xf3, 0xcb, 0x9e, 0x3e, 0x84, 0xba, 0xbf, 0x72, 0x97, 0x6c, 0xe6, 0x87, 0xd1, 0x5a, 0x24,
+	0xc4, 0x68, 0xe7, 0xbb, 0x55, 0x5a, 0x53, 0xcc, 0x54, 0x08, 0x3f, 0x82, 0x86, 0x76, 0xd1, 0x55,
+	0x26, 0x24, 0xa7, 0x7c, 0xf4, 0x39, 0x5b, 0x33, 0xd9, 0x17, 0xc1, 0xde, 0x89, 0x6d, 0x98, 0xbc,
+	0x72, 0x01, 0x89, 0xd2, 0x28, 0x0f, 0xa1, 0xae, 0x1c, 0xb6, 0x41, 0x0a, 0x3a, 0x91, 0x64, 0xdb,
+	0x18, 0x07, 0x50, 0x14, 0x9c, 0x07, 0x09, 0x29, 0xaa, 0xa1, 0xd3, 0xcf, 0xcb, 0x79, 0x40, 0x35,
+	0xc6, 0xfb, 0x50, 0xb9, 0x70, 0x83, 0x60, 0xee, 0x7a, 0xaf, 0xd5, 0x4f, 0x49, 0x95, 0x7e, 0xd2,
+	0x0f, 0x7f, 0x34, 0xa0, 0x20, 0x3f, 0x05, 0x5c, 0x83, 0xf2, 0x99, 0xf5, 0xdc, 0xb2, 0x5f, 0x5a,
+	0x68, 0x07, 0x57, 0xa0, 0x30, 0x3a, 0x37, 0x27, 0xc8, 0xc0, 0x25, 0xc8, 0x9d, 0x50, 0x94, 0xc3,
+	0x00, 0xa5, 0xa9, 0xd5, 0x9b, 0x4c, 0x5e, 0xa1, 0x3c, 0x2e, 0x43, 0x7e, 0x7c, 0xfe, 0x1d, 0x2a,
+	0x48, 0xb7, 0xf3, 0xa9, 0xf3, 0x04, 0x15, 0xa5, 0xd4, 0x3b, 0x73, 0x6c, 0x54, 0x92, 0xd2, 0xb3,
+	0xc9, 0x60, 0x84, 0x6a, 0xd2, 0x6d, 0x62, 0x8d, 0x50, 0x5d, 0xa2, 0x97, 0x83, 0x93, 0x09, 0x6a,
+	0x28, 0xb7, 0x17, 0xe6, 0x10, 0x35, 0xa5, 0x71, 0x64, 0x0e, 0x51, 0x4b, 0x0a, 0x27, 0xa7, 0x13,
+	0x84, 0xa4, 0xcd, 0x31, 0x87, 0x43, 0xb4, 0x7b, 0xd8, 0x83, 0xca, 0x76, 0xd7, 0xe2, 0x2a, 0x14,
+	0xa7, 0x0e, 0x35, 0x27, 0xfa, 0x52, 0xcf, 0x07, 0x03, 0x79, 0xa9, 0x3a, 0x54, 0xa4, 0x34, 0x33,
+	0xfb, 0x7d, 0x94, 0xc3, 0x18, 0x9a, 0x4a, 0xeb, 0xdb, 0x93, 0x57, 0xd4, 0x1c, 0x3d, 0x75, 0x50,
+	0xfe, 0xf0, 0x19, 0xe4, 0x87, 0xbe, 0x90, 0x47, 0x2c, 0xdb, 0x1a, 0xa0, 0x1d, 0x59, 0x5e, 0xdf,
+	0xb6, 0x9c, 0x9e, 0x69, 0x21, 0x43, 0x06, 0xed, 0xdb, 0x2f, 0x06, 0xb2, 0xae, 0x0a, 0x14, 0x86,
+	0xe6, 0x78, 0x8c, 0xf2, 0xb2, 0x42, 0xd3, 0x9a, 0x9a, 0x4f, 0x06, 0xa8, 0x20, 0xbd, 0xed, 0x33,
+	0x47, 0x29, 0xc5, 0xc3, 0xdf, 0x0c, 0x28, 0xe9, 0x5d, 0x87, 0x5b, 0x50, 0x1b, 0x9a, 0x63, 0x67,
+	0x40, 0x67, 0xaa, 0xda, 0x1d, 0x99, 0x3b, 0x05, 0xd6, 0xa0, 0x47, 0x07, 0x53, 0x07, 0x19, 0xb8,
+	0x09, 0x90, 0xb2, 0x13, 0xfb, 0x7b, 0x94, 0xc3, 0xbb, 0xd0, 0x48, 0xf5, 0xb1, 0x29, 0xbd, 0x50,
+	0x3e, 0x73, 0xec, 0xe9, 0x80, 0x9e, 0x9a, 0x8e, 0xcc, 0xb9, 0x07, 0xad, 0x94, 0x9d, 0x9a, 0x4e,
+	0xff, 0xe9, 0x60, 0x3c, 0x46, 0x45, 0x7c, 0x0f, 0x70, 0x0a, 0xfb, 0x3d, 0xe7, 0xf4, 0x6c, 0x3c,
+	0x9e, 0x51, 0xfb, 0x14, 0x95, 0x32, 0x01, 0x4e, 0xa6, 0x13, 0x19, 0x16, 0x95, 0x33, 0x01, 0x46,
+	0xbd, 0xb3, 0xe9, 0xd4, 0xec, 0x59, 0xa8, 0x92, 0x71, 0x1c, 0xf7, 0xac, 0xfe, 0xb9, 0x3d, 0x45,
+	0xd5, 0xe3, 0x0f, 0x50, 0x54, 0xdb, 0x01, 0x3f, 0x86, 0xf2, 0x13, 0xae, 0x45, 0xa4, 0x37, 0xf5,
+	0xf5, 0x9f, 0x96, 0xfd, 0x66, 0x86, 0x44, 0xc1, 0xa6, 0xb3, 0x83, 0xfb, 0xd0, 0x1a, 0x31, 0x91,
+	0x9d, 0x05, 0x7c, 0x5f, 0xfd, 0x58, 0xdc, 0x9e, 0xce, 0xfd, 0xbb, 0xb7, 0x0d, 0x2a, 0xc8, 0x09,
+	0xfa, 0xe5, 0xe3, 0x81, 0xf1, 0xfb, 0xc7, 0x03, 0xe3, 0xcf, 0x8f, 0x07, 0xc6, 0x4f, 0x7f, 0x1d,
+	0xec, 0xcc, 0x4b, 0xea, 0x9f, 0xd3, 0xb7, 0x7f, 0x0f, 0x00, 0x6f, 0xe6, 0x95, 0x79, 0x48, 0x09,
+	0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// OptimClient is the client API for Optim service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type OptimClient interface {
+	DoOptim(ctx context.Context, in *OptimRequest, opts ...grpc.CallOption) (*OptimReply, error)
+	GetCapabilities(ctx context.Context, in *CapabilitiesRequest, opts ...grpc.CallOption) (*CapabilitiesReply, error)
+}
+
+type optimClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewOptimClient(cc *grpc.ClientConn) OptimClient {
+	return &optimClient{cc}
+}
+
+func (c *optimClient) DoOptim(ctx context.Context, in *OptimRequest, opts ...grpc.CallOption) (*OptimReply, error) {
+	out := new(OptimReply)
+	err := c.cc.Invoke(ctx, "/pb.Optim/DoOptim", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *optimClient) GetCapabilities(ctx context.Context, in *CapabilitiesRequest, opts ...grpc.CallOption) (*CapabilitiesReply, error) {
+	out := new(CapabilitiesReply)
+	err := c.cc.Invoke(ctx, "/pb.Optim/GetCapabilities", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OptimServer is the server API for Optim service.
+type OptimServer interface {
+	DoOptim(context.Context, *OptimRequest) (*OptimReply, error)
+	GetCapabilities(context.Context, *CapabilitiesRequest) (*CapabilitiesReply, error)
+}
+
+// UnimplementedOptimServer can be embedded to have forward compatible implementations.
+type UnimplementedOptimServer struct {
+}
+
+func (*UnimplementedOptimServer) DoOptim(ctx context.Context, req *OptimRequest) (*OptimReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DoOptim not implemented")
+}
+func (*UnimplementedOptimServer) GetCapabilities(ctx context.Context, req *CapabilitiesRequest) (*CapabilitiesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCapabilities not implemented")
+}
+
+func RegisterOptimServer(s *grpc.Server, srv OptimServer) {
+	s.RegisterService(&_Optim_serviceDesc, srv)
+}
+
+func _Optim_DoOptim_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OptimRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OptimServer).DoOptim(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Optim/DoOptim",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OptimServer).DoOptim(ctx, req.(*OptimRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Optim_GetCapabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CapabilitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OptimServer).GetCapabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Optim/GetCapabilities",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OptimServer).GetCapabilities(ctx, req.(*CapabilitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Optim_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.Optim",
+	HandlerType: (*OptimServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DoOptim",
+			Handler:    _Optim_DoOptim_Handler,
+		},
+		{
+			MethodName: "GetCapabilities",
+			Handler:    _Optim_GetCapabilities_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "optim.proto",
+}
+
+func (m *OptimRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *OptimRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OptimRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if m.Source != 0 {
-		n += 1 + sovOptim(uint64(m.Source))
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	l = len(m.Data)
-	if l > 0 {
-		n += 1 + l + sovOptim(uint64(l))
+	if len(m.Operations) > 0 {
+		for iNdEx := len(m.Operations) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Operations[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintOptim(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1
+			i--
+			dAtA[i] = 0xda
+		}
 	}
-	if m.Output != 0 {
-		n += 1 + sovOptim(uint64(m.Output))
+	if m.Filter != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Filter))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0xd0
 	}
-	if m.Quality != 0 {
-		n += 1 + sovOptim(uint64(m.Quality))
+	if m.Rect != nil {
+		{
+			size, err := m.Rect.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintOptim(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0xca
 	}
-	if m.Width != 0 {
-		n += 1 + sovOptim(uint64(m.Width))
+	if m.Focal != nil {
+		{
+			size, err := m.Focal.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintOptim(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0xc2
 	}
-	if m.Height != 0 {
-		n += 1 + sovOptim(uint64(m.Height))
+	if m.WithoutEnlargement {
+		i--
+		if m.WithoutEnlargement {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0xb8
 	}
-	if m.Crop != 0 {
-		n += 1 + sovOptim(uint64(m.Crop))
+	if m.Fit != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Fit))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0xb0
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if m.Page != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Page))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0xa8
+	}
+	if m.Dither {
+		i--
+		if m.Dither {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0xa0
+	}
+	if m.ConvertToSrgb {
+		i--
+		if m.ConvertToSrgb {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x98
+	}
+	if m.Metadata != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Metadata))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x90
+	}
+	if m.DisableAutoOrient {
+		i--
+		if m.DisableAutoOrient {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x88
+	}
+	if len(m.OutputName) > 0 {
+		i -= len(m.OutputName)
+		copy(dAtA[i:], m.OutputName)
+		i = encodeVarintOptim(dAtA, i, uint64(len(m.OutputName)))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x82
+	}
+	if len(m.SourceName) > 0 {
+		i -= len(m.SourceName)
+		copy(dAtA[i:], m.SourceName)
+		i = encodeVarintOptim(dAtA, i, uint64(len(m.SourceName)))
+		i--
+		dAtA[i] = 0x7a
+	}
+	if m.Timeout != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Timeout))
+		i--
+		dAtA[i] = 0x70
+	}
+	if len(m.Candidates) > 0 {
+		dAtA4 := make([]byte, len(m.Candidates)*10)
+		var j3 int
+		for _, num := range m.Candidates {
+			for num >= 1<<7 {
+				dAtA4[j3] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j3++
+			}
+			dAtA4[j3] = uint8(num)
+			j3++
+		}
+		i -= j3
+		copy(dAtA[i:], dAtA4[:j3])
+		i = encodeVarintOptim(dAtA, i, uint64(j3))
+		i--
+		dAtA[i] = 0x6a
+	}
+	if m.Dictionary != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Dictionary))
+		i--
+		dAtA[i] = 0x60
+	}
+	if m.Zstd != nil {
+		{
+			size, err := m.Zstd.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintOptim(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x5a
+	}
+	if m.Crop != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Crop))
+		i--
+		dAtA[i] = 0x50
+	}
+	if m.Height != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Height))
+		i--
+		dAtA[i] = 0x48
+	}
+	if m.Width != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Width))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.Quality != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Quality))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.Output != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Output))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.Data) > 0 {
+		i -= len(m.Data)
+		copy(dAtA[i:], m.Data)
+		i = encodeVarintOptim(dAtA, i, uint64(len(m.Data)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Source != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Source))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ZstdOptions) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ZstdOptions) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ZstdOptions) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Concurrency != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Concurrency))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.DisableChecksum {
+		i--
+		if m.DisableChecksum {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.WindowSize != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.WindowSize))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *FocalPoint) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FocalPoint) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *FocalPoint) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Y != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Y))))
+		i--
+		dAtA[i] = 0x15
+	}
+	if m.X != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.X))))
+		i--
+		dAtA[i] = 0xd
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Operation) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Operation) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Operation) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Value != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Value))))
+		i--
+		dAtA[i] = 0x25
+	}
+	if m.Sigma != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Sigma))))
+		i--
+		dAtA[i] = 0x1d
+	}
+	if m.Angle != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Angle))))
+		i--
+		dAtA[i] = 0x15
+	}
+	if len(m.Op) > 0 {
+		i -= len(m.Op)
+		copy(dAtA[i:], m.Op)
+		i = encodeVarintOptim(dAtA, i, uint64(len(m.Op)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *CropRect) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CropRect) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CropRect) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Height != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Height))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Width != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Width))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Y != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Y))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.X != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.X))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *OptimReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OptimReply) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OptimReply) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Encoder) > 0 {
+		i -= len(m.Encoder)
+		copy(dAtA[i:], m.Encoder)
+		i = encodeVarintOptim(dAtA, i, uint64(len(m.Encoder)))
+		i--
+		dAtA[i] = 0x62
+	}
+	if len(m.OutputName) > 0 {
+		i -= len(m.OutputName)
+		copy(dAtA[i:], m.OutputName)
+		i = encodeVarintOptim(dAtA, i, uint64(len(m.OutputName)))
+		i--
+		dAtA[i] = 0x5a
+	}
+	if m.Dictionary != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Dictionary))
+		i--
+		dAtA[i] = 0x50
+	}
+	if m.Height != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Height))
+		i--
+		dAtA[i] = 0x48
+	}
+	if m.Width != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Width))
+		i--
+		dAtA[i] = 0x40
+	}
+	if len(m.Data) > 0 {
+		i -= len(m.Data)
+		copy(dAtA[i:], m.Data)
+		i = encodeVarintOptim(dAtA, i, uint64(len(m.Data)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Output != 0 {
+		i = encodeVarintOptim(dAtA, i, uint64(m.Output))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *CapabilitiesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CapabilitiesRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CapabilitiesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Tool) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Tool) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Tool) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Version) > 0 {
+		i -= len(m.Version)
+		copy(dAtA[i:], m.Version)
+		i = encodeVarintOptim(dAtA, i, uint64(len(m.Version)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Path) > 0 {
+		i -= len(m.Path)
+		copy(dAtA[i:], m.Path)
+		i = encodeVarintOptim(dAtA, i, uint64(len(m.Path)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Installed {
+		i--
+		if m.Installed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintOptim(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *CapabilitiesReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CapabilitiesReply) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CapabilitiesReply) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Fallback) > 0 {
+		i -= len(m.Fallback)
+		copy(dAtA[i:], m.Fallback)
+		i = encodeVarintOptim(dAtA, i, uint64(len(m.Fallback)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.Tools) > 0 {
+		for iNdEx := len(m.Tools) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Tools[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintOptim(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if len(m.TextOutputs) > 0 {
+		for iNdEx := len(m.TextOutputs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.TextOutputs[iNdEx])
+			copy(dAtA[i:], m.TextOutputs[iNdEx])
+			i = encodeVarintOptim(dAtA, i, uint64(len(m.TextOutputs[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.TextInputs) > 0 {
+		for iNdEx := len(m.TextInputs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.TextInputs[iNdEx])
+			copy(dAtA[i:], m.TextInputs[iNdEx])
+			i = encodeVarintOptim(dAtA, i, uint64(len(m.TextInputs[iNdEx])))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.ImageOutputs) > 0 {
+		for iNdEx := len(m.ImageOutputs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.ImageOutputs[iNdEx])
+			copy(dAtA[i:], m.ImageOutputs[iNdEx])
+			i = encodeVarintOptim(dAtA, i, uint64(len(m.ImageOutputs[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.ImageInputs) > 0 {
+		for iNdEx := len(m.ImageInputs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.ImageInputs[iNdEx])
+			copy(dAtA[i:], m.ImageInputs[iNdEx])
+			i = encodeVarintOptim(dAtA, i, uint64(len(m.ImageInputs[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintOptim(dAtA []byte, offset int, v uint64) int {
+	offset -= sovOptim(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *OptimRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Source != 0 {
+		n += 1 + sovOptim(uint64(m.Source))
+	}
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovOptim(uint64(l))
+	}
+	if m.Output != 0 {
+		n += 1 + sovOptim(uint64(m.Output))
+	}
+	if m.Quality != 0 {
+		n += 1 + sovOptim(uint64(m.Quality))
+	}
+	if m.Width != 0 {
+		n += 1 + sovOptim(uint64(m.Width))
+	}
+	if m.Height != 0 {
+		n += 1 + sovOptim(uint64(m.Height))
+	}
+	if m.Crop != 0 {
+		n += 1 + sovOptim(uint64(m.Crop))
+	}
+	if m.Zstd != nil {
+		l = m.Zstd.Size()
+		n += 1 + l + sovOptim(uint64(l))
+	}
+	if m.Dictionary != 0 {
+		n += 1 + sovOptim(uint64(m.Dictionary))
+	}
+	if len(m.Candidates) > 0 {
+		l = 0
+		for _, e := range m.Candidates {
+			l += sovOptim(uint64(e))
+		}
+		n += 1 + sovOptim(uint64(l)) + l
+	}
+	if m.Timeout != 0 {
+		n += 1 + sovOptim(uint64(m.Timeout))
+	}
+	l = len(m.SourceName)
+	if l > 0 {
+		n += 1 + l + sovOptim(uint64(l))
+	}
+	l = len(m.OutputName)
+	if l > 0 {
+		n += 2 + l + sovOptim(uint64(l))
+	}
+	if m.DisableAutoOrient {
+		n += 3
+	}
+	if m.Metadata != 0 {
+		n += 2 + sovOptim(uint64(m.Metadata))
+	}
+	if m.ConvertToSrgb {
+		n += 3
+	}
+	if m.Dither {
+		n += 3
+	}
+	if m.Page != 0 {
+		n += 2 + sovOptim(uint64(m.Page))
+	}
+	if m.Fit != 0 {
+		n += 2 + sovOptim(uint64(m.Fit))
+	}
+	if m.WithoutEnlargement {
+		n += 3
+	}
+	if m.Focal != nil {
+		l = m.Focal.Size()
+		n += 2 + l + sovOptim(uint64(l))
+	}
+	if m.Rect != nil {
+		l = m.Rect.Size()
+		n += 2 + l + sovOptim(uint64(l))
+	}
+	if m.Filter != 0 {
+		n += 2 + sovOptim(uint64(m.Filter))
+	}
+	if len(m.Operations) > 0 {
+		for _, e := range m.Operations {
+			l = e.Size()
+			n += 2 + l + sovOptim(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ZstdOptions) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.WindowSize != 0 {
+		n += 1 + sovOptim(uint64(m.WindowSize))
+	}
+	if m.DisableChecksum {
+		n += 2
+	}
+	if m.Concurrency != 0 {
+		n += 1 + sovOptim(uint64(m.Concurrency))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *FocalPoint) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.X != 0 {
+		n += 5
+	}
+	if m.Y != 0 {
+		n += 5
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Operation) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Op)
+	if l > 0 {
+		n += 1 + l + sovOptim(uint64(l))
+	}
+	if m.Angle != 0 {
+		n += 5
+	}
+	if m.Sigma != 0 {
+		n += 5
+	}
+	if m.Value != 0 {
+		n += 5
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *CropRect) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.X != 0 {
+		n += 1 + sovOptim(uint64(m.X))
+	}
+	if m.Y != 0 {
+		n += 1 + sovOptim(uint64(m.Y))
+	}
+	if m.Width != 0 {
+		n += 1 + sovOptim(uint64(m.Width))
+	}
+	if m.Height != 0 {
+		n += 1 + sovOptim(uint64(m.Height))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *OptimReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Output != 0 {
+		n += 1 + sovOptim(uint64(m.Output))
+	}
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovOptim(uint64(l))
+	}
+	if m.Width != 0 {
+		n += 1 + sovOptim(uint64(m.Width))
+	}
+	if m.Height != 0 {
+		n += 1 + sovOptim(uint64(m.Height))
+	}
+	if m.Dictionary != 0 {
+		n += 1 + sovOptim(uint64(m.Dictionary))
+	}
+	l = len(m.OutputName)
+	if l > 0 {
+		n += 1 + l + sovOptim(uint64(l))
+	}
+	l = len(m.Encoder)
+	if l > 0 {
+		n += 1 + l + sovOptim(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *CapabilitiesRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Tool) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovOptim(uint64(l))
+	}
+	if m.Installed {
+		n += 2
+	}
+	l = len(m.Path)
+	if l > 0 {
+		n += 1 + l + sovOptim(uint64(l))
+	}
+	l = len(m.Version)
+	if l > 0 {
+		n += 1 + l + sovOptim(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *CapabilitiesReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.ImageInputs) > 0 {
+		for _, s := range m.ImageInputs {
+			l = len(s)
+			n += 1 + l + sovOptim(uint64(l))
+		}
+	}
+	if len(m.ImageOutputs) > 0 {
+		for _, s := range m.ImageOutputs {
+			l = len(s)
+			n += 1 + l + sovOptim(uint64(l))
+		}
+	}
+	if len(m.TextInputs) > 0 {
+		for _, s := range m.TextInputs {
+			l = len(s)
+			n += 1 + l + sovOptim(uint64(l))
+		}
+	}
+	if len(m.TextOutputs) > 0 {
+		for _, s := range m.TextOutputs {
+			l = len(s)
+			n += 1 + l + sovOptim(uint64(l))
+		}
+	}
+	if len(m.Tools) > 0 {
+		for _, e := range m.Tools {
+			l = e.Size()
+			n += 1 + l + sovOptim(uint64(l))
+		}
+	}
+	l = len(m.Fallback)
+	if l > 0 {
+		n += 1 + l + sovOptim(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovOptim(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozOptim(x uint64) (n int) {
+	return sovOptim(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *OptimRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowOptim
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OptimRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OptimRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Source", wireType)
+			}
+			m.Source = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Source |= Type(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Output", wireType)
+			}
+			m.Output = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Output |= Type(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Quality", wireType)
+			}
+			m.Quality = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Quality |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Width", wireType)
+			}
+			m.Width = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Width |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Height", wireType)
+			}
+			m.Height = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Height |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Crop", wireType)
+			}
+			m.Crop = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Crop |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Zstd", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Zstd == nil {
+				m.Zstd = &ZstdOptions{}
+			}
+			if err := m.Zstd.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dictionary", wireType)
+			}
+			m.Dictionary = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Dictionary |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 13:
+			if wireType == 0 {
+				var v Type
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowOptim
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= Type(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Candidates = append(m.Candidates, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowOptim
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthOptim
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthOptim
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				if elementCount != 0 && len(m.Candidates) == 0 {
+					m.Candidates = make([]Type, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v Type
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowOptim
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= Type(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Candidates = append(m.Candidates, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Candidates", wireType)
+			}
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timeout", wireType)
+			}
+			m.Timeout = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timeout |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SourceName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SourceName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 16:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OutputName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.OutputName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 17:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DisableAutoOrient", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.DisableAutoOrient = bool(v != 0)
+		case 18:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", wireType)
+			}
+			m.Metadata = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Metadata |= Metadata(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 19:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConvertToSrgb", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ConvertToSrgb = bool(v != 0)
+		case 20:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dither", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Dither = bool(v != 0)
+		case 21:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Page", wireType)
+			}
+			m.Page = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Page |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 22:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Fit", wireType)
+			}
+			m.Fit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Fit |= Fit(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 23:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field WithoutEnlargement", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.WithoutEnlargement = bool(v != 0)
+		case 24:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Focal", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Focal == nil {
+				m.Focal = &FocalPoint{}
+			}
+			if err := m.Focal.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 25:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rect", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Rect == nil {
+				m.Rect = &CropRect{}
+			}
+			if err := m.Rect.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 26:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Filter", wireType)
+			}
+			m.Filter = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Filter |= Filter(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 27:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Operations", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Operations = append(m.Operations, &Operation{})
+			if err := m.Operations[len(m.Operations)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipOptim(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ZstdOptions) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowOptim
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ZstdOptions: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ZstdOptions: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field WindowSize", wireType)
+			}
+			m.WindowSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.WindowSize |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DisableChecksum", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.DisableChecksum = bool(v != 0)
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Concurrency", wireType)
+			}
+			m.Concurrency = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Concurrency |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipOptim(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FocalPoint) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowOptim
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FocalPoint: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FocalPoint: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field X", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.X = float32(math.Float32frombits(v))
+		case 2:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Y", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Y = float32(math.Float32frombits(v))
+		default:
+			iNdEx = preIndex
+			skippy, err := skipOptim(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Operation) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowOptim
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Operation: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Operation: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Op", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Op = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Angle", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Angle = float32(math.Float32frombits(v))
+		case 3:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sigma", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Sigma = float32(math.Float32frombits(v))
+		case 4:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Value = float32(math.Float32frombits(v))
+		default:
+			iNdEx = preIndex
+			skippy, err := skipOptim(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CropRect) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowOptim
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CropRect: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CropRect: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field X", wireType)
+			}
+			m.X = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.X |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Y", wireType)
+			}
+			m.Y = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Y |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Width", wireType)
+			}
+			m.Width = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Width |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Height", wireType)
+			}
+			m.Height = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Height |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipOptim(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OptimReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowOptim
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OptimReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OptimReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Output", wireType)
+			}
+			m.Output = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Output |= Type(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Width", wireType)
+			}
+			m.Width = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Width |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Height", wireType)
+			}
+			m.Height = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Height |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dictionary", wireType)
+			}
+			m.Dictionary = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Dictionary |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OutputName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.OutputName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Encoder", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Encoder = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipOptim(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CapabilitiesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowOptim
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CapabilitiesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CapabilitiesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipOptim(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
 	}
-	return n
-}
 
-func (m *OptimReply) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.Output != 0 {
-		n += 1 + sovOptim(uint64(m.Output))
-	}
-	l = len(m.Data)
-	if l > 0 {
-		n += 1 + l + sovOptim(uint64(l))
-	}
-	if m.Width != 0 {
-		n += 1 + sovOptim(uint64(m.Width))
-	}
-	if m.Height != 0 {
-		n += 1 + sovOptim(uint64(m.Height))
-	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return n
-}
-
-func sovOptim(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
-}
-func sozOptim(x uint64) (n int) {
-	return sovOptim(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+	return nil
 }
-func (m *OptimRequest) Unmarshal(dAtA []byte) error {
+func (m *Tool) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -576,36 +3670,17 @@ func (m *OptimRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: OptimRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: Tool: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: OptimRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Tool: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Source", wireType)
-			}
-			m.Source = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowOptim
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.Source |= Type(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowOptim
@@ -615,31 +3690,29 @@ func (m *OptimRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthOptim
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthOptim
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
-			if m.Data == nil {
-				m.Data = []byte{}
-			}
+			m.Name = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 6:
+		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Output", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Installed", wireType)
 			}
-			m.Output = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowOptim
@@ -649,16 +3722,17 @@ func (m *OptimRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Output |= Type(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 7:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Quality", wireType)
+			m.Installed = bool(v != 0)
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Path", wireType)
 			}
-			m.Quality = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowOptim
@@ -668,35 +3742,29 @@ func (m *OptimRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Quality |= uint32(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 8:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Width", wireType)
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOptim
 			}
-			m.Width = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowOptim
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.Width |= uint32(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
 			}
-		case 9:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Height", wireType)
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
 			}
-			m.Height = 0
+			m.Path = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowOptim
@@ -706,30 +3774,24 @@ func (m *OptimRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Height |= uint32(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 10:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Crop", wireType)
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOptim
 			}
-			m.Crop = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowOptim
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.Crop |= uint32(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
 			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Version = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipOptim(dAtA[iNdEx:])
@@ -755,7 +3817,7 @@ func (m *OptimRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *OptimReply) Unmarshal(dAtA []byte) error {
+func (m *CapabilitiesReply) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -778,17 +3840,17 @@ func (m *OptimReply) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: OptimReply: wiretype end group for non-group")
+			return fmt.Errorf("proto: CapabilitiesReply: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: OptimReply: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: CapabilitiesReply: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Output", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ImageInputs", wireType)
 			}
-			m.Output = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowOptim
@@ -798,16 +3860,29 @@ func (m *OptimReply) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Output |= Type(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ImageInputs = append(m.ImageInputs, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ImageOutputs", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowOptim
@@ -817,31 +3892,61 @@ func (m *OptimReply) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthOptim
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthOptim
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
-			if m.Data == nil {
-				m.Data = []byte{}
+			m.ImageOutputs = append(m.ImageOutputs, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TextInputs", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
 			}
+			m.TextInputs = append(m.TextInputs, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
-		case 8:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Width", wireType)
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TextOutputs", wireType)
 			}
-			m.Width = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowOptim
@@ -851,16 +3956,29 @@ func (m *OptimReply) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Width |= uint32(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 9:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Height", wireType)
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOptim
 			}
-			m.Height = 0
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TextOutputs = append(m.TextOutputs, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tools", wireType)
+			}
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowOptim
@@ -870,11 +3988,58 @@ func (m *OptimReply) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Height |= uint32(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Tools = append(m.Tools, &Tool{})
+			if err := m.Tools[len(m.Tools)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Fallback", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOptim
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOptim
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOptim
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Fallback = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipOptim(dAtA[iNdEx:])
@@ -16,13 +16,18 @@ package server
 
 import (
 	"context"
+	"errors"
 	"net"
+	"strings"
+	"time"
 
 	"github.com/vicanso/tiny/log"
 	"github.com/vicanso/tiny/pb"
 	"github.com/vicanso/tiny/tiny"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type (
@@ -30,44 +35,90 @@ type (
 	GRPCServer struct{}
 )
 
-// DoOptim do optim
-func (gs *GRPCServer) DoOptim(ctx context.Context, in *pb.OptimRequest) (reply *pb.OptimReply, err error) {
-	var encodeType tiny.EncodeType
-	switch in.Source {
-	case pb.Type_JPEG:
-		encodeType = tiny.EncodeTypeJPEG
-	case pb.Type_PNG:
-		encodeType = tiny.EncodeTypePNG
-	case pb.Type_WEBP:
-		encodeType = tiny.EncodeTypeWEBP
-	default:
-		encodeType = tiny.EncodeTypeUnknown
-	}
-
-	var outputType tiny.EncodeType
-	switch in.Output {
-	case pb.Type_JPEG:
-		outputType = tiny.EncodeTypeJPEG
-	case pb.Type_PNG:
-		outputType = tiny.EncodeTypePNG
-	case pb.Type_AVIF:
-		outputType = tiny.EncodeTypeAVIF
-	case pb.Type_WEBP:
-		outputType = tiny.EncodeTypeWEBP
-	case pb.Type_GZIP:
-		outputType = tiny.EncodeTypeGzip
-	case pb.Type_BR:
-		outputType = tiny.EncodeTypeBr
-	case pb.Type_SNAPPY:
-		outputType = tiny.EncodeTypeSnappy
-	case pb.Type_LZ4:
-		outputType = tiny.EncodeTypeLz4
-	case pb.Type_ZSTD:
-		outputType = tiny.EncodeTypeZstd
-	default:
-		outputType = tiny.EncodeTypeUnknown
+// convertEncodeType convert the type of pb to encode type,
+// the name is used for the custom codec of tiny
+func convertEncodeType(t pb.Type, name string) tiny.EncodeType {
+	if name != "" {
+		return tiny.ConvertToEncodeType(name)
+	}
+	if t == pb.Type_UNKNOWN {
+		return tiny.EncodeTypeUnknown
+	}
+	// pb的类型名称与codec的名称（或别名）一致
+	return tiny.ConvertToEncodeType(strings.ToLower(t.String()))
+}
+
+// convertToPBType convert the encode type to pb type, it returns unknown
+// for the custom codec
+func convertToPBType(t tiny.EncodeType) pb.Type {
+	codec, ok := tiny.GetCodec(t)
+	if !ok {
+		return pb.Type_UNKNOWN
+	}
+	for _, name := range append([]string{codec.Name}, codec.Aliases...) {
+		if v, ok := pb.Type_value[strings.ToUpper(name)]; ok {
+			return pb.Type(v)
+		}
+	}
+	return pb.Type_UNKNOWN
+}
+
+// convertFocalPoint convert the focal point of pb
+func convertFocalPoint(focal *pb.FocalPoint) *tiny.FocalPoint {
+	if focal == nil {
+		return nil
+	}
+	return &tiny.FocalPoint{
+		X: float64(focal.X),
+		Y: float64(focal.Y),
 	}
+}
 
+// convertCropRect convert the crop rectangle of pb
+func convertCropRect(rect *pb.CropRect) *tiny.CropRect {
+	if rect == nil {
+		return nil
+	}
+	return &tiny.CropRect{
+		X:      int(rect.X),
+		Y:      int(rect.Y),
+		Width:  int(rect.Width),
+		Height: int(rect.Height),
+	}
+}
+
+// convertOperations convert the operations of pb
+func convertOperations(operations []*pb.Operation) []*tiny.Operation {
+	if len(operations) == 0 {
+		return nil
+	}
+	result := make([]*tiny.Operation, len(operations))
+	for i, op := range operations {
+		result[i] = &tiny.Operation{
+			Op:    op.Op,
+			Angle: float64(op.Angle),
+			Sigma: float64(op.Sigma),
+			Value: float64(op.Value),
+		}
+	}
+	return result
+}
+
+// convertContextError convert the error of context to grpc status error
+func convertContextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return err
+}
+
+// DoOptim do optim
+func (gs *GRPCServer) DoOptim(ctx context.Context, in *pb.OptimRequest) (reply *pb.OptimReply, err error) {
+	encodeType := convertEncodeType(in.Source, in.SourceName)
+	outputType := convertEncodeType(in.Output, in.OutputName)
 	if outputType == tiny.EncodeTypeUnknown {
 		err = errOutputTypeIsInvalid
 		return
@@ -79,37 +130,119 @@ func (gs *GRPCServer) DoOptim(ctx context.Context, in *pb.OptimRequest) (reply *
 
 	quality := int(in.Quality)
 
-	if encodeType >= tiny.EncodeTypeJPEG && encodeType <= tiny.EncodeTypeWEBP {
+	if tiny.IsImageType(encodeType) {
 		// 图片只能转换为图片
-		if outputType < tiny.EncodeTypeJPEG {
+		if !tiny.IsImageType(outputType) {
 			err = errOutputTypeIsInvalid
 			return
 		}
-		crop := tiny.CropType(in.Crop)
-		imgInfo, err := tiny.ImageOptim(ctx, in.Data, encodeType, outputType, crop, quality, int(in.Width), int(in.Height))
+		imgInfo, err := tiny.ImageOptimWithOptions(ctx, in.Data, &tiny.ImageOptimOptions{
+			Source:            encodeType,
+			Output:            outputType,
+			Crop:              tiny.CropType(in.Crop),
+			Quality:           quality,
+			Width:             int(in.Width),
+			Height:            int(in.Height),
+			DisableAutoOrient: in.DisableAutoOrient,
+			// pb与tiny的元数据策略取值一致
+			Metadata:      tiny.MetadataPolicy(in.Metadata),
+			ConvertToSRGB: in.ConvertToSrgb,
+			Dither:        in.Dither,
+			Page:          int(in.Page),
+			// pb与tiny的适配方式取值一致
+			Fit:                tiny.FitMode(in.Fit),
+			WithoutEnlargement: in.WithoutEnlargement,
+			Focal:              convertFocalPoint(in.Focal),
+			Rect:               convertCropRect(in.Rect),
+			// pb与tiny的滤波器取值一致
+			Filter:     tiny.ResampleFilter(in.Filter),
+			Operations: convertOperations(in.Operations),
+		})
 		if err != nil {
-			return nil, err
+			if errors.Is(err, tiny.ErrQueueIsFull) {
+				err = status.Error(codes.ResourceExhausted, err.Error())
+			}
+			if errors.Is(err, tiny.ErrCropRectIsInvalid) ||
+				errors.Is(err, tiny.ErrOperationIsInvalid) ||
+				errors.Is(err, tiny.ErrImageIsTooLarge) ||
+				errors.Is(err, tiny.ErrPageNotFound) ||
+				errors.Is(err, tiny.ErrPageNotSupported) {
+				err = status.Error(codes.InvalidArgument, err.Error())
+			}
+			return nil, convertContextError(err)
 		}
 		reply = &pb.OptimReply{
-			Output: in.Output,
-			Data:   imgInfo.Data,
-			Width:  uint32(imgInfo.Width),
-			Height: uint32(imgInfo.Height),
+			Output:     convertToPBType(imgInfo.Type),
+			OutputName: imgInfo.Type.String(),
+			Encoder:    imgInfo.Encoder,
+			Data:       imgInfo.Data,
+			Width:      uint32(imgInfo.Width),
+			Height:     uint32(imgInfo.Height),
 		}
 	} else {
-		info, err := tiny.TextOptim(in.Data, outputType, quality)
+		// 文本类型的source为压缩数据的编码，未指定则为原始数据
+		opts := &tiny.TextOptimOptions{
+			Source:     encodeType,
+			Output:     outputType,
+			Quality:    quality,
+			Dictionary: in.Dictionary,
+			Timeout:    time.Duration(in.Timeout) * time.Millisecond,
+		}
+		for _, item := range in.Candidates {
+			opts.Candidates = append(opts.Candidates, convertEncodeType(item, ""))
+		}
+		if in.Zstd != nil {
+			opts.Zstd = &tiny.ZstdOptions{
+				WindowSize:      int(in.Zstd.WindowSize),
+				DisableChecksum: in.Zstd.DisableChecksum,
+				Concurrency:     int(in.Zstd.Concurrency),
+			}
+		}
+		info, err := tiny.TextOptimWithOptions(in.Data, opts)
 		if err != nil {
-			return nil, err
+			if errors.Is(err, tiny.ErrDecodeSizeExceeded) ||
+				errors.Is(err, tiny.ErrCandidateIsInvalid) ||
+				errors.Is(err, tiny.ErrDictionaryNotFound) ||
+				errors.Is(err, tiny.ErrDictionaryNotSupported) ||
+				errors.Is(err, tiny.ErrZstdWindowSizeExceeded) {
+				err = status.Error(codes.InvalidArgument, err.Error())
+			}
+			return nil, convertContextError(err)
 		}
 		reply = &pb.OptimReply{
-			Data:   info.Data,
-			Output: in.Output,
+			Data: info.Data,
+			// auto输出时为实际使用的压缩类型
+			Output:     convertToPBType(info.Type),
+			OutputName: info.Type.String(),
+			Dictionary: info.Dictionary,
 		}
 	}
 
 	return
 }
 
+// GetCapabilities get the capabilities
+func (gs *GRPCServer) GetCapabilities(ctx context.Context, in *pb.CapabilitiesRequest) (*pb.CapabilitiesReply, error) {
+	capabilities := tiny.GetCapabilities()
+	tools := make([]*pb.Tool, len(capabilities.Tools))
+	for index, tool := range capabilities.Tools {
+		tools[index] = &pb.Tool{
+			Name:      tool.Name,
+			Installed: tool.Installed,
+			Path:      tool.Path,
+			Version:   tool.Version,
+		}
+	}
+	return &pb.CapabilitiesReply{
+		ImageInputs:  capabilities.ImageInputs,
+		ImageOutputs: capabilities.ImageOutputs,
+		TextInputs:   capabilities.TextInputs,
+		TextOutputs:  capabilities.TextOutputs,
+		Tools:        tools,
+		Fallback:     capabilities.Fallback,
+	}, nil
+}
+
 // NewGRPCServer new a grpc server
 func NewGRPCServer(address string) error {
 	ln, err := net.Listen("tcp", address)
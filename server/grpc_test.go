@@ -16,10 +16,16 @@ package server
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vicanso/tiny/pb"
+	"github.com/vicanso/tiny/tiny"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestDoOptim(t *testing.T) {
@@ -70,6 +76,8 @@ func TestDoOptim(t *testing.T) {
 		reply, err := gs.DoOptim(ctx, req)
 		assert.Nil(err)
 		assert.Equal(pb.Type_JPEG, reply.Output)
+		// cjpeg不可用时使用image/jpeg兜底
+		assert.Contains([]string{tiny.EncoderCjpeg, tiny.EncoderGoJPEG}, reply.Encoder)
 		assert.NotNil(reply.Data)
 	})
 
@@ -103,6 +111,23 @@ func TestDoOptim(t *testing.T) {
 		assert.NotNil(reply.Data)
 	})
 
+	t.Run("disable auto orient", func(t *testing.T) {
+		assert := assert.New(t)
+		req := &pb.OptimRequest{
+			Source:            pb.Type_JPEG,
+			Output:            pb.Type_WEBP,
+			Data:              jpegData,
+			Quality:           10,
+			DisableAutoOrient: true,
+			Metadata:          pb.Metadata_KEEP,
+		}
+		ctx := context.Background()
+		reply, err := gs.DoOptim(ctx, req)
+		assert.Nil(err)
+		assert.Equal(pb.Type_WEBP, reply.Output)
+		assert.NotNil(reply.Data)
+	})
+
 	t.Run("optim to gzip", func(t *testing.T) {
 		assert := assert.New(t)
 		req := &pb.OptimRequest{
@@ -170,4 +195,132 @@ func TestDoOptim(t *testing.T) {
 		assert.Equal(pb.Type_ZSTD, reply.Output)
 		assert.NotNil(reply.Data)
 	})
+
+	t.Run("gzip to zstd", func(t *testing.T) {
+		assert := assert.New(t)
+		data, _ := tiny.GzipEncode([]byte("abcd"), 0)
+		req := &pb.OptimRequest{
+			Source: pb.Type_GZIP,
+			Output: pb.Type_ZSTD,
+			Data:   data,
+		}
+		ctx := context.Background()
+		reply, err := gs.DoOptim(ctx, req)
+		assert.Nil(err)
+		assert.Equal(pb.Type_ZSTD, reply.Output)
+		buf, err := tiny.ZstdDecode(reply.Data)
+		assert.Nil(err)
+		assert.Equal("abcd", string(buf))
+	})
+
+	t.Run("output name", func(t *testing.T) {
+		assert := assert.New(t)
+		req := &pb.OptimRequest{
+			OutputName: tiny.Snappy,
+			Data:       []byte("abcd"),
+		}
+		ctx := context.Background()
+		reply, err := gs.DoOptim(ctx, req)
+		assert.Nil(err)
+		assert.Equal(pb.Type_SNAPPY, reply.Output)
+		assert.Equal(tiny.Snappy, reply.OutputName)
+		buf, err := tiny.SnappyDecode(reply.Data)
+		assert.Nil(err)
+		assert.Equal("abcd", string(buf))
+
+		req.OutputName = "not-exists"
+		_, err = gs.DoOptim(ctx, req)
+		assert.Equal(errOutputTypeIsInvalid, err)
+	})
+
+	t.Run("auto", func(t *testing.T) {
+		assert := assert.New(t)
+		// 数据较短时snappy更小，因此使用较长的数据
+		data := strings.Repeat("abcd", 100)
+		req := &pb.OptimRequest{
+			Output:     pb.Type_AUTO,
+			Data:       []byte(data),
+			Candidates: []pb.Type{pb.Type_SNAPPY, pb.Type_BR},
+		}
+		ctx := context.Background()
+		reply, err := gs.DoOptim(ctx, req)
+		assert.Nil(err)
+		assert.Equal(pb.Type_BR, reply.Output)
+		buf, err := tiny.BrotliDecode(reply.Data)
+		assert.Nil(err)
+		assert.Equal(data, string(buf))
+
+		// 候选类型非文本类型
+		req.Candidates = []pb.Type{pb.Type_PNG}
+		_, err = gs.DoOptim(ctx, req)
+		assert.Equal(codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("zstd with options", func(t *testing.T) {
+		assert := assert.New(t)
+		req := &pb.OptimRequest{
+			Output:  pb.Type_ZSTD,
+			Data:    []byte("abcd"),
+			Quality: 19,
+			Zstd: &pb.ZstdOptions{
+				WindowSize:      1024,
+				DisableChecksum: true,
+			},
+		}
+		ctx := context.Background()
+		reply, err := gs.DoOptim(ctx, req)
+		assert.Nil(err)
+		buf, err := tiny.ZstdDecode(reply.Data)
+		assert.Nil(err)
+		assert.Equal("abcd", string(buf))
+	})
+
+	t.Run("zstd with dictionary", func(t *testing.T) {
+		assert := assert.New(t)
+		samples := make([][]byte, 0)
+		for i := 0; i < 100; i++ {
+			samples = append(samples, []byte(`{"id":`+strconv.Itoa(i)+`,"name":"user","status":"active"}`))
+		}
+		dict, err := tiny.TrainZstdDictionary(samples, 0)
+		assert.Nil(err)
+		id, err := tiny.AddDictionary(dict)
+		assert.Nil(err)
+		defer tiny.RemoveDictionary(id)
+
+		req := &pb.OptimRequest{
+			Output:     pb.Type_ZSTD,
+			Data:       []byte(`{"id":1000,"name":"user","status":"active"}`),
+			Dictionary: id,
+		}
+		ctx := context.Background()
+		reply, err := gs.DoOptim(ctx, req)
+		assert.Nil(err)
+		assert.Equal(id, reply.Dictionary)
+		buf, err := tiny.ZstdDecode(reply.Data)
+		assert.Nil(err)
+		assert.Equal(`{"id":1000,"name":"user","status":"active"}`, string(buf))
+
+		req.Dictionary = 1
+		_, err = gs.DoOptim(ctx, req)
+		assert.Equal(codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestConvertContextError(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(codes.DeadlineExceeded, status.Code(convertContextError(context.DeadlineExceeded)))
+	assert.Equal(codes.Canceled, status.Code(convertContextError(context.Canceled)))
+	err := errors.New("abc")
+	assert.Equal(err, convertContextError(err))
+}
+
+func TestGetCapabilities(t *testing.T) {
+	assert := assert.New(t)
+	gs := &GRPCServer{}
+	reply, err := gs.GetCapabilities(context.Background(), &pb.CapabilitiesRequest{})
+	assert.Nil(err)
+	assert.Contains(reply.ImageOutputs, tiny.WEBP)
+	assert.Contains(reply.TextOutputs, tiny.Gzip)
+	assert.Equal(4, len(reply.Tools))
+	assert.NotEmpty(reply.Fallback)
 }
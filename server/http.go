@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"mime"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -43,11 +44,53 @@ type (
 		Quality int           `json:"quality,omitempty"`
 		Width   int           `json:"width,omitempty"`
 		Height  int           `json:"height,omitempty"`
+		// 不根据exif的方向旋转图片
+		DisableAutoOrient bool `json:"disableAutoOrient,omitempty"`
+		// 元数据的处理方式：strip、keep、keep-icc与keep-copyright
+		Metadata string `json:"metadata,omitempty"`
+		// 根据图片的icc将像素转换为sRGB
+		ConvertToSRGB bool `json:"convertToSRGB,omitempty"`
+		// 输出为gif时使用抖动
+		Dither bool `json:"dither,omitempty"`
+		// 多页图片（tiff）的页码，从0开始
+		Page int `json:"page,omitempty"`
+		// 缩放的适配方式：contain、cover、fill、inside与outside
+		Fit string `json:"fit,omitempty"`
+		// 不放大小于指定尺寸的图片
+		WithoutEnlargement bool `json:"withoutEnlargement,omitempty"`
+		// 缩放后按焦点（0-1）裁剪
+		Focal *tiny.FocalPoint `json:"focal,omitempty"`
+		// 缩放前裁剪的区域
+		Rect *tiny.CropRect `json:"rect,omitempty"`
+		// 缩放的重采样滤波器：nearest、box、linear、catmull-rom与lanczos等
+		Filter string `json:"filter,omitempty"`
+		// 缩放后按顺序执行的操作，如旋转、锐化等
+		Operations []*tiny.Operation `json:"operations,omitempty"`
 	}
 	optimTextParams struct {
-		Data    string `json:"data,omitempty"`
-		Output  string `json:"output,omitempty"`
-		Quality int    `json:"quality,omitempty"`
+		// 如果指定了source，则data为base64编码的压缩数据
+		Data    string            `json:"data,omitempty"`
+		Source  string            `json:"source,omitempty"`
+		Output  string            `json:"output,omitempty"`
+		Quality int               `json:"quality,omitempty"`
+		Zstd    *tiny.ZstdOptions `json:"zstd,omitempty"`
+		// 压缩使用的字典id
+		Dictionary uint32 `json:"dictionary,omitempty"`
+		// auto输出的候选类型
+		Candidates []string `json:"candidates,omitempty"`
+		// auto输出的时间限制（毫秒）
+		Timeout int `json:"timeout,omitempty"`
+	}
+	trainDictionaryParams struct {
+		// 样本数据（base64）
+		Samples [][]byte `json:"samples,omitempty"`
+		// 字典的最大长度
+		Size int `json:"size,omitempty"`
+	}
+	// Dictionary dictionary info
+	Dictionary struct {
+		ID   uint32 `json:"id,omitempty"`
+		Data []byte `json:"data,omitempty"`
 	}
 	// Text optim text info
 	Text struct {
@@ -64,6 +107,20 @@ var (
 			newConvertResponseToError(),
 		},
 	})
+	// 流式处理时不可设置总超时，只限制等待响应头的时长
+	streamClient = &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: 10 * time.Second,
+		},
+	}
+)
+
+const (
+	headerDictionaryID = "X-Dictionary-Id"
+	headerEncoder      = "X-Encoder"
+
+	textStreamPath = "/texts/optim/stream"
 )
 
 func init() {
@@ -89,12 +146,101 @@ func getIntValue(c *elton.Context, key string) int {
 	return i
 }
 
+// getZstdOptions get zstd options from query, it returns nil if no option is set
+func getZstdOptions(c *elton.Context) *tiny.ZstdOptions {
+	opts := &tiny.ZstdOptions{
+		WindowSize:      getIntValue(c, "zstdWindowSize"),
+		DisableChecksum: c.QueryParam("zstdDisableChecksum") == "true",
+		Concurrency:     getIntValue(c, "zstdConcurrency"),
+	}
+	if opts.WindowSize == 0 && !opts.DisableChecksum && opts.Concurrency == 0 {
+		return nil
+	}
+	return opts
+}
+
+// getFocalPoint get the focal point from query, it returns nil if not set
+func getFocalPoint(c *elton.Context) *tiny.FocalPoint {
+	x := c.QueryParam("focalX")
+	y := c.QueryParam("focalY")
+	if x == "" && y == "" {
+		return nil
+	}
+	focal := &tiny.FocalPoint{}
+	focal.X, _ = strconv.ParseFloat(x, 64)
+	focal.Y, _ = strconv.ParseFloat(y, 64)
+	return focal
+}
+
+// getCropRect get the crop rectangle from query, it returns nil if not set
+func getCropRect(c *elton.Context) *tiny.CropRect {
+	rect := &tiny.CropRect{
+		X:      getIntValue(c, "rectX"),
+		Y:      getIntValue(c, "rectY"),
+		Width:  getIntValue(c, "rectWidth"),
+		Height: getIntValue(c, "rectHeight"),
+	}
+	if *rect == (tiny.CropRect{}) {
+		return nil
+	}
+	return rect
+}
+
+func getDictionaryID(c *elton.Context) uint32 {
+	v, _ := strconv.ParseUint(c.QueryParam("dictionary"), 10, 32)
+	return uint32(v)
+}
+
+// convertCandidates convert the candidates of auto output to encode types
+func convertCandidates(candidates []string) []tiny.EncodeType {
+	if len(candidates) == 0 {
+		return nil
+	}
+	types := make([]tiny.EncodeType, len(candidates))
+	for index, item := range candidates {
+		types[index] = tiny.ConvertToEncodeType(strings.TrimSpace(item))
+	}
+	return types
+}
+
+// getCandidates get the candidates of auto output from query, e.g. candidates=gzip,br
+func getCandidates(c *elton.Context) []tiny.EncodeType {
+	v := c.QueryParam("candidates")
+	if v == "" {
+		return nil
+	}
+	return convertCandidates(strings.Split(v, ","))
+}
+
+// setDictionaryHeader set the id of dictionary to response header
+func setDictionaryHeader(c *elton.Context, id uint32) {
+	if id == 0 {
+		return
+	}
+	c.SetHeader(headerDictionaryID, strconv.FormatUint(uint64(id), 10))
+}
+
+// setContentEncoding set the content encoding to response header,
+// the brotli data encoded with dictionary is not standard brotli
+func setContentEncoding(c *elton.Context, encoding string, t tiny.EncodeType, dictionary uint32) {
+	if t == tiny.EncodeTypeBr && dictionary != 0 {
+		encoding = tiny.BrotliDictionary
+	}
+	c.SetHeader(elton.HeaderContentEncoding, encoding)
+}
+
 func optimImageFromURL(c *elton.Context) (err error) {
 	url := c.QueryParam("url")
 	if url == "" {
 		err = errURLIsNil
 		return
 	}
+	// 操作无效时无需获取资源文件
+	operations, err := tiny.ParseOperations(c.QueryParam("operations"))
+	if err != nil {
+		err = convertOptimError(err)
+		return
+	}
 	// 获取资源文件
 	resp, err := ins.Get(url)
 	if err != nil {
@@ -112,24 +258,41 @@ func optimImageFromURL(c *elton.Context) (err error) {
 		return
 	}
 	encodeType := tiny.ConvertToEncodeType(arr[1])
-	if encodeType == tiny.EncodeTypeUnknown {
+	if !tiny.IsImageType(encodeType) {
 		err = errContentTypeIsNotSupported
 		return
 	}
 	outputType := tiny.ConvertToEncodeType(c.QueryParam("output"))
-	// 如果未指定或不支持类型，则按保持不变
+	// 如果未指定或不支持类型，则按保持不变（仅支持解码的类型则为png）
 	if outputType == tiny.EncodeTypeUnknown {
-		outputType = encodeType
+		outputType = tiny.DefaultImageOutput(encodeType)
 	}
-	width := getIntValue(c, "width")
-	height := getIntValue(c, "height")
-	quality := getIntValue(c, "quality")
-	cropType := tiny.CropType(getIntValue(c, "crop"))
-	imgInfo, err := tiny.ImageOptim(c.Context(), resp.Data, encodeType, outputType, cropType, quality, width, height)
+	imgInfo, err := tiny.ImageOptimWithOptions(c.Context(), resp.Data, &tiny.ImageOptimOptions{
+		Source:             encodeType,
+		Output:             outputType,
+		Crop:               tiny.CropType(getIntValue(c, "crop")),
+		Quality:            getIntValue(c, "quality"),
+		Width:              getIntValue(c, "width"),
+		Height:             getIntValue(c, "height"),
+		DisableAutoOrient:  c.QueryParam("disableAutoOrient") == "true",
+		Metadata:           tiny.ConvertToMetadataPolicy(c.QueryParam("metadata")),
+		ConvertToSRGB:      c.QueryParam("convertToSRGB") == "true",
+		Dither:             c.QueryParam("dither") == "true",
+		Page:               getIntValue(c, "page"),
+		Fit:                tiny.ConvertToFitMode(c.QueryParam("fit")),
+		WithoutEnlargement: c.QueryParam("withoutEnlargement") == "true",
+		Focal:              getFocalPoint(c),
+		Rect:               getCropRect(c),
+		Filter:             tiny.ConvertToResampleFilter(c.QueryParam("filter")),
+		Operations:         operations,
+	})
 	if err != nil {
+		err = convertOptimError(err)
 		return
 	}
-	c.SetContentTypeByExt("." + outputType.String())
+	// 兜底编码时输出类型可能不一致（如avif转为webp）
+	c.SetContentTypeByExt("." + imgInfo.Type.String())
+	c.SetHeader(headerEncoder, imgInfo.Encoder)
 	c.BodyBuffer = bytes.NewBuffer(imgInfo.Data)
 	return
 }
@@ -151,16 +314,35 @@ func optimImageFromData(c *elton.Context) (err error) {
 		return
 	}
 	encodeType := tiny.ConvertToEncodeType(params.Source)
-	if encodeType == tiny.EncodeTypeUnknown {
+	if !tiny.IsImageType(encodeType) {
 		err = errContentTypeIsNotSupported
 		return
 	}
 	outputType := tiny.ConvertToEncodeType(params.Output)
 	if outputType == tiny.EncodeTypeUnknown {
-		outputType = encodeType
+		outputType = tiny.DefaultImageOutput(encodeType)
 	}
-	imgInfo, err := tiny.ImageOptim(c.Context(), data, encodeType, outputType, params.Crop, params.Quality, params.Width, params.Height)
+	imgInfo, err := tiny.ImageOptimWithOptions(c.Context(), data, &tiny.ImageOptimOptions{
+		Source:             encodeType,
+		Output:             outputType,
+		Crop:               params.Crop,
+		Quality:            params.Quality,
+		Width:              params.Width,
+		Height:             params.Height,
+		DisableAutoOrient:  params.DisableAutoOrient,
+		Metadata:           tiny.ConvertToMetadataPolicy(params.Metadata),
+		ConvertToSRGB:      params.ConvertToSRGB,
+		Dither:             params.Dither,
+		Page:               params.Page,
+		Fit:                tiny.ConvertToFitMode(params.Fit),
+		WithoutEnlargement: params.WithoutEnlargement,
+		Focal:              params.Focal,
+		Rect:               params.Rect,
+		Filter:             tiny.ConvertToResampleFilter(params.Filter),
+		Operations:         params.Operations,
+	})
 	if err != nil {
+		err = convertOptimError(err)
 		return
 	}
 
@@ -185,18 +367,137 @@ func optimTextFromURL(c *elton.Context) (err error) {
 		return
 	}
 	quality := getIntValue(c, "quality")
+	// 未指定数据源类型时，根据响应头的Content-Encoding判断
+	source := c.QueryParam("source")
+	if source == "" {
+		source = resp.Headers.Get(elton.HeaderContentEncoding)
+	}
+	sourceType, err := getTextSourceType(source)
+	if err != nil {
+		return
+	}
 
-	info, err := tiny.TextOptim(resp.Data, outputType, quality)
+	info, err := tiny.TextOptimWithOptions(resp.Data, &tiny.TextOptimOptions{
+		Source:     sourceType,
+		Output:     outputType,
+		Quality:    quality,
+		Zstd:       getZstdOptions(c),
+		Dictionary: getDictionaryID(c),
+		Candidates: getCandidates(c),
+		Timeout:    time.Duration(getIntValue(c, "timeout")) * time.Millisecond,
+	})
 	if err != nil {
+		err = convertTextError(err)
 		return
 	}
+	setDictionaryHeader(c, info.Dictionary)
 
 	c.SetHeader(elton.HeaderContentType, resp.Headers.Get(elton.HeaderContentType))
-	c.SetHeader(elton.HeaderContentEncoding, info.Type.String())
+	setContentEncoding(c, info.Type.String(), info.Type, info.Dictionary)
 	c.BodyBuffer = bytes.NewBuffer(info.Data)
 	return
 }
 
+func getTextSourceType(source string) (tiny.EncodeType, error) {
+	// 使用字典压缩的brotli数据，解压时根据header获取字典
+	if source == tiny.BrotliDictionary {
+		return tiny.EncodeTypeBr, nil
+	}
+	sourceType := tiny.ConvertToEncodeType(source)
+	if sourceType != tiny.EncodeTypeUnknown && !tiny.IsTextType(sourceType) {
+		return tiny.EncodeTypeUnknown, errContentTypeIsNotSupported
+	}
+	return sourceType, nil
+}
+
+func optimTextStreamFromURL(c *elton.Context) (err error) {
+	url := c.QueryParam("url")
+	if url == "" {
+		err = errURLIsNil
+		return
+	}
+	outputType := tiny.ConvertToEncodeType(c.QueryParam("output"))
+	if outputType == tiny.EncodeTypeUnknown {
+		err = errOutputTypeIsInvalid
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Context(), http.MethodGet, url, nil)
+	if err != nil {
+		err = hes.Wrap(err)
+		return
+	}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		err = errors.New("http request fail")
+		return
+	}
+	source := c.QueryParam("source")
+	if source == "" {
+		source = resp.Header.Get(elton.HeaderContentEncoding)
+	}
+	sourceType, err := getTextSourceType(source)
+	if err != nil {
+		resp.Body.Close()
+		return
+	}
+	// 响应数据在读取的同时压缩，无需缓存完整数据
+	dictionary := getDictionaryID(c)
+	r, err := tiny.NewTextOptimReader(resp.Body, &tiny.TextOptimOptions{
+		Source:     sourceType,
+		Output:     outputType,
+		Quality:    getIntValue(c, "quality"),
+		Zstd:       getZstdOptions(c),
+		Dictionary: dictionary,
+	})
+	if err != nil {
+		resp.Body.Close()
+		return
+	}
+	c.SetHeader(elton.HeaderContentType, resp.Header.Get(elton.HeaderContentType))
+	setContentEncoding(c, tiny.StreamContentEncoding(outputType), outputType, dictionary)
+	setDictionaryHeader(c, dictionary)
+	c.Body = r
+	return
+}
+
+func optimTextStreamFromData(c *elton.Context) (err error) {
+	outputType := tiny.ConvertToEncodeType(c.QueryParam("output"))
+	if outputType == tiny.EncodeTypeUnknown {
+		err = errOutputTypeIsInvalid
+		return
+	}
+	sourceType, err := getTextSourceType(c.QueryParam("source"))
+	if err != nil {
+		return
+	}
+	// 该路由不经过body parser，直接读取请求数据
+	body := c.Request.Body
+	if body == nil || body == http.NoBody {
+		err = errTextIsNil
+		return
+	}
+	dictionary := getDictionaryID(c)
+	r, err := tiny.NewTextOptimReader(body, &tiny.TextOptimOptions{
+		Source:     sourceType,
+		Output:     outputType,
+		Quality:    getIntValue(c, "quality"),
+		Zstd:       getZstdOptions(c),
+		Dictionary: dictionary,
+	})
+	if err != nil {
+		return
+	}
+	c.SetHeader(elton.HeaderContentType, c.Request.Header.Get(elton.HeaderContentType))
+	setContentEncoding(c, tiny.StreamContentEncoding(outputType), outputType, dictionary)
+	setDictionaryHeader(c, dictionary)
+	c.Body = r
+	return
+}
+
 func optimTextFromData(c *elton.Context) (err error) {
 	params := &optimTextParams{}
 	err = json.Unmarshal(c.RequestBody, params)
@@ -213,8 +514,34 @@ func optimTextFromData(c *elton.Context) (err error) {
 		return
 	}
 	data := []byte(params.Data)
-	info, err := tiny.TextOptim(data, outputType, params.Quality)
+	sourceType := tiny.EncodeTypeUnknown
+	if params.Source != "" {
+		sourceType, err = getTextSourceType(params.Source)
+		if err == nil && sourceType == tiny.EncodeTypeUnknown {
+			err = errContentTypeIsNotSupported
+		}
+		if err != nil {
+			return
+		}
+		// 压缩数据以base64的形式提交
+		buf, e := base64.StdEncoding.DecodeString(params.Data)
+		if e != nil {
+			err = hes.Wrap(e)
+			return
+		}
+		data = buf
+	}
+	info, err := tiny.TextOptimWithOptions(data, &tiny.TextOptimOptions{
+		Source:     sourceType,
+		Output:     outputType,
+		Quality:    params.Quality,
+		Zstd:       params.Zstd,
+		Dictionary: params.Dictionary,
+		Candidates: convertCandidates(params.Candidates),
+		Timeout:    time.Duration(params.Timeout) * time.Millisecond,
+	})
 	if err != nil {
+		err = convertTextError(err)
 		return
 	}
 	c.Body = info
@@ -222,6 +549,45 @@ func optimTextFromData(c *elton.Context) (err error) {
 	return
 }
 
+func trainDictionary(c *elton.Context) (err error) {
+	params := &trainDictionaryParams{}
+	err = json.Unmarshal(c.RequestBody, params)
+	if err != nil {
+		return
+	}
+	if len(params.Samples) == 0 {
+		err = errSamplesIsNil
+		return
+	}
+	data, err := tiny.TrainZstdDictionary(params.Samples, params.Size)
+	if err != nil {
+		err = convertDictionaryError(err)
+		return
+	}
+	id, err := tiny.AddDictionary(data)
+	if err != nil {
+		// 字典已存在或数量超出限制
+		err = convertDictionaryError(err)
+		return
+	}
+	if dictionaryPath != "" {
+		err = tiny.SaveDictionary(dictionaryPath, id)
+		if err != nil {
+			return
+		}
+	}
+	c.Body = &Dictionary{
+		ID:   id,
+		Data: data,
+	}
+	return
+}
+
+func getCapabilities(c *elton.Context) (err error) {
+	c.Body = tiny.GetCapabilities()
+	return
+}
+
 // NewHTTPServer new a http server
 func NewHTTPServer(address string) error {
 	d := elton.New()
@@ -269,6 +635,11 @@ func NewHTTPServer(address string) error {
 	}
 	bodyparserConf := M.BodyParserConfig{
 		Limit: limit,
+		// 流式处理的数据不限制大小，也不可预先读取
+		Skipper: func(c *elton.Context) bool {
+			return c.Request.Method == http.MethodPost &&
+				c.Request.URL.Path == textStreamPath
+		},
 	}
 	bodyparserConf.AddDecoder(M.NewJSONDecoder())
 
@@ -292,6 +663,19 @@ func NewHTTPServer(address string) error {
 
 	d.SetFunctionName(optimTextFromData, "optim-text-data")
 	d.POST("/texts/optim", optimTextFromData)
+
+	d.SetFunctionName(optimTextStreamFromURL, "optim-text-stream-url")
+	d.GET(textStreamPath, optimTextStreamFromURL)
+
+	d.SetFunctionName(optimTextStreamFromData, "optim-text-stream-data")
+	d.POST(textStreamPath, optimTextStreamFromData)
+
+	d.SetFunctionName(trainDictionary, "train-dictionary")
+	d.POST("/dictionaries", trainDictionary)
+
+	d.SetFunctionName(getCapabilities, "capabilities")
+	d.GET("/capabilities", getCapabilities)
+
 	log.Default().Info().
 		Str("adddress", address).
 		Msg("http server is listening")
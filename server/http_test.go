@@ -15,15 +15,22 @@
 package server
 
 import (
+	"bytes"
 	"encoding/base64"
+	"image/jpeg"
+	"image/png"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vicanso/elton"
 	"github.com/vicanso/go-axios"
+	"github.com/vicanso/tiny/tiny"
+	"golang.org/x/image/bmp"
 )
 
 var (
@@ -70,7 +77,7 @@ func TestOptimImageFromURL(t *testing.T) {
 	t.Run("content type is not supported", func(t *testing.T) {
 		assert := assert.New(t)
 		headers := make(http.Header)
-		headers.Set(elton.HeaderContentType, "image/bmp")
+		headers.Set(elton.HeaderContentType, "image/svg+xml")
 		done := ins.Mock(&axios.Response{
 			Headers: headers,
 		})
@@ -98,6 +105,31 @@ func TestOptimImageFromURL(t *testing.T) {
 		assert.Nil(err)
 		assert.NotNil(c.BodyBuffer)
 	})
+
+	t.Run("optim bmp", func(t *testing.T) {
+		assert := assert.New(t)
+		img, err := jpeg.Decode(bytes.NewReader(jpegData))
+		assert.Nil(err)
+		buffer := new(bytes.Buffer)
+		err = bmp.Encode(buffer, img)
+		assert.Nil(err)
+		headers := make(http.Header)
+		headers.Set(elton.HeaderContentType, "image/bmp")
+
+		done := ins.Mock(&axios.Response{
+			Headers: headers,
+			Data:    buffer.Bytes(),
+		})
+		defer done()
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/?url=http://www.baidu.com/", nil)
+		c := elton.NewContext(resp, req)
+		err = optimImageFromURL(c)
+		assert.Nil(err)
+		// bmp不支持编码，默认输出为png
+		_, err = png.Decode(bytes.NewReader(c.BodyBuffer.Bytes()))
+		assert.Nil(err)
+	})
 }
 
 func TestOptimImageFromData(t *testing.T) {
@@ -206,6 +238,191 @@ func TestOptimTextFromURL(t *testing.T) {
 		assert.Equal("br", c.GetHeader(elton.HeaderContentEncoding))
 		assert.NotNil(c.BodyBuffer)
 	})
+
+	t.Run("gzip to br", func(t *testing.T) {
+		assert := assert.New(t)
+		data, _ := tiny.GzipEncode([]byte("abcd"), 0)
+		req := httptest.NewRequest("GET", "/?url=http://www.baidu.com/&output=br&source=gzip", nil)
+		done := ins.Mock(&axios.Response{
+			Data: data,
+		})
+		defer done()
+		resp := httptest.NewRecorder()
+		c := elton.NewContext(resp, req)
+		err := optimTextFromURL(c)
+		assert.Nil(err)
+		assert.Equal("br", c.GetHeader(elton.HeaderContentEncoding))
+		buf, err := tiny.BrotliDecode(c.BodyBuffer.Bytes())
+		assert.Nil(err)
+		assert.Equal("abcd", string(buf))
+	})
+
+	t.Run("source from content encoding", func(t *testing.T) {
+		assert := assert.New(t)
+		data, _ := tiny.GzipEncode([]byte("abcd"), 0)
+		headers := make(http.Header)
+		headers.Set(elton.HeaderContentEncoding, "gzip")
+		req := httptest.NewRequest("GET", "/?url=http://www.baidu.com/&output=zstd", nil)
+		done := ins.Mock(&axios.Response{
+			Headers: headers,
+			Data:    data,
+		})
+		defer done()
+		resp := httptest.NewRecorder()
+		c := elton.NewContext(resp, req)
+		err := optimTextFromURL(c)
+		assert.Nil(err)
+		assert.Equal("zstd", c.GetHeader(elton.HeaderContentEncoding))
+		buf, err := tiny.ZstdDecode(c.BodyBuffer.Bytes())
+		assert.Nil(err)
+		assert.Equal("abcd", string(buf))
+	})
+
+	t.Run("br with dictionary", func(t *testing.T) {
+		assert := assert.New(t)
+		samples := make([][]byte, 0)
+		for i := 0; i < 100; i++ {
+			samples = append(samples, []byte(`{"id":`+strconv.Itoa(i)+`,"name":"user","status":"active"}`))
+		}
+		dict, err := tiny.TrainZstdDictionary(samples, 0)
+		assert.Nil(err)
+		id, err := tiny.AddDictionary(dict)
+		assert.Nil(err)
+		defer tiny.RemoveDictionary(id)
+
+		doc := `{"id":1000,"name":"user","status":"active"}`
+		req := httptest.NewRequest("GET", "/?url=http://www.baidu.com/&output=br&dictionary="+strconv.Itoa(int(id)), nil)
+		done := ins.Mock(&axios.Response{
+			Data: []byte(doc),
+		})
+		defer done()
+		c := elton.NewContext(httptest.NewRecorder(), req)
+		err = optimTextFromURL(c)
+		assert.Nil(err)
+		assert.Equal(tiny.BrotliDictionary, c.GetHeader(elton.HeaderContentEncoding))
+		assert.Equal(strconv.Itoa(int(id)), c.GetHeader(headerDictionaryID))
+		data := c.BodyBuffer.Bytes()
+		buf, err := tiny.BrotliDecode(data)
+		assert.Nil(err)
+		assert.Equal(doc, string(buf))
+
+		// 根据Content-Encoding解压
+		headers := make(http.Header)
+		headers.Set(elton.HeaderContentEncoding, tiny.BrotliDictionary)
+		req = httptest.NewRequest("GET", "/?url=http://www.baidu.com/&output=gzip", nil)
+		done = ins.Mock(&axios.Response{
+			Headers: headers,
+			Data:    data,
+		})
+		defer done()
+		c = elton.NewContext(httptest.NewRecorder(), req)
+		err = optimTextFromURL(c)
+		assert.Nil(err)
+		assert.Equal("gzip", c.GetHeader(elton.HeaderContentEncoding))
+		buf, err = tiny.GzipDecode(c.BodyBuffer.Bytes())
+		assert.Nil(err)
+		assert.Equal(doc, string(buf))
+	})
+}
+
+func TestOptimTextStreamFromURL(t *testing.T) {
+	originalData := bytes.Repeat([]byte("abcd"), 1024)
+	gzipData, _ := tiny.GzipEncode(originalData, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fail":
+			w.WriteHeader(http.StatusBadRequest)
+		case "/gzip":
+			w.Header().Set(elton.HeaderContentType, "application/gzip")
+			_, _ = w.Write(gzipData)
+		default:
+			w.Header().Set(elton.HeaderContentType, "text/plain")
+			_, _ = w.Write(originalData)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("url is nil", func(t *testing.T) {
+		assert := assert.New(t)
+		c := elton.NewContext(nil, httptest.NewRequest("GET", "/", nil))
+		err := optimTextStreamFromURL(c)
+		assert.Equal(errURLIsNil, err)
+	})
+
+	t.Run("invalid output", func(t *testing.T) {
+		assert := assert.New(t)
+		c := elton.NewContext(nil, httptest.NewRequest("GET", "/?url="+server.URL, nil))
+		err := optimTextStreamFromURL(c)
+		assert.Equal(errOutputTypeIsInvalid, err)
+	})
+
+	t.Run("get data fail", func(t *testing.T) {
+		assert := assert.New(t)
+		c := elton.NewContext(nil, httptest.NewRequest("GET", "/?output=br&url="+server.URL+"/fail", nil))
+		err := optimTextStreamFromURL(c)
+		assert.Equal("http request fail", err.Error())
+	})
+
+	t.Run("br text", func(t *testing.T) {
+		assert := assert.New(t)
+		resp := httptest.NewRecorder()
+		c := elton.NewContext(resp, httptest.NewRequest("GET", "/?output=br&url="+server.URL, nil))
+		err := optimTextStreamFromURL(c)
+		assert.Nil(err)
+		assert.Equal("br", c.GetHeader(elton.HeaderContentEncoding))
+		assert.Equal("text/plain", c.GetHeader(elton.HeaderContentType))
+		buf, err := io.ReadAll(c.Body.(io.Reader))
+		assert.Nil(err)
+		data, err := tiny.BrotliDecode(buf)
+		assert.Nil(err)
+		assert.Equal(originalData, data)
+	})
+
+	t.Run("gzip to zstd", func(t *testing.T) {
+		assert := assert.New(t)
+		resp := httptest.NewRecorder()
+		c := elton.NewContext(resp, httptest.NewRequest("GET", "/?output=zstd&source=gzip&url="+server.URL+"/gzip", nil))
+		err := optimTextStreamFromURL(c)
+		assert.Nil(err)
+		assert.Equal("zstd", c.GetHeader(elton.HeaderContentEncoding))
+		buf, err := io.ReadAll(c.Body.(io.Reader))
+		assert.Nil(err)
+		data, err := tiny.ZstdDecode(buf)
+		assert.Nil(err)
+		assert.Equal(originalData, data)
+	})
+}
+
+func TestOptimTextStreamFromData(t *testing.T) {
+	originalData := bytes.Repeat([]byte("abcd"), 1024)
+
+	t.Run("invalid output", func(t *testing.T) {
+		assert := assert.New(t)
+		c := elton.NewContext(nil, httptest.NewRequest("POST", "/", bytes.NewReader(originalData)))
+		err := optimTextStreamFromData(c)
+		assert.Equal(errOutputTypeIsInvalid, err)
+	})
+
+	t.Run("invalid source", func(t *testing.T) {
+		assert := assert.New(t)
+		c := elton.NewContext(nil, httptest.NewRequest("POST", "/?output=br&source=png", bytes.NewReader(originalData)))
+		err := optimTextStreamFromData(c)
+		assert.Equal(errContentTypeIsNotSupported, err)
+	})
+
+	t.Run("gzip text", func(t *testing.T) {
+		assert := assert.New(t)
+		resp := httptest.NewRecorder()
+		c := elton.NewContext(resp, httptest.NewRequest("POST", "/?output=gzip", bytes.NewReader(originalData)))
+		err := optimTextStreamFromData(c)
+		assert.Nil(err)
+		assert.Equal("gzip", c.GetHeader(elton.HeaderContentEncoding))
+		buf, err := io.ReadAll(c.Body.(io.Reader))
+		assert.Nil(err)
+		data, err := tiny.GzipDecode(buf)
+		assert.Nil(err)
+		assert.Equal(originalData, data)
+	})
 }
 
 func TestOptimTextFromData(t *testing.T) {
@@ -251,4 +468,122 @@ func TestOptimTextFromData(t *testing.T) {
 		assert.Nil(err)
 		assert.NotNil(c.Body)
 	})
+
+	t.Run("zstd with options", func(t *testing.T) {
+		assert := assert.New(t)
+		c := elton.NewContext(nil, httptest.NewRequest("GET", "/", nil))
+		c.RequestBody = []byte(`{
+			"data": "abce",
+			"output": "zstd",
+			"quality": 19,
+			"zstd": {
+				"windowSize": 1024,
+				"disableChecksum": true
+			}
+		}`)
+		err := optimTextFromData(c)
+		assert.Nil(err)
+		info := c.Body.(*tiny.Text)
+		buf, err := tiny.ZstdDecode(info.Data)
+		assert.Nil(err)
+		assert.Equal("abce", string(buf))
+	})
+
+	t.Run("auto", func(t *testing.T) {
+		assert := assert.New(t)
+		c := elton.NewContext(nil, httptest.NewRequest("GET", "/", nil))
+		c.RequestBody = []byte(`{
+			"data": "abcdabcdabcdabcdabcdabcdabcdabcdabcdabcd",
+			"output": "auto",
+			"candidates": ["gzip", "zstd"],
+			"timeout": 1000
+		}`)
+		err := optimTextFromData(c)
+		assert.Nil(err)
+		info := c.Body.(*tiny.Text)
+		assert.Contains([]tiny.EncodeType{tiny.EncodeTypeGzip, tiny.EncodeTypeZstd}, info.Type)
+		buf, err := tiny.TextDecode(info.Data, info.Type)
+		assert.Nil(err)
+		assert.Equal("abcdabcdabcdabcdabcdabcdabcdabcdabcdabcd", string(buf))
+	})
+
+	t.Run("invalid source", func(t *testing.T) {
+		assert := assert.New(t)
+		c := elton.NewContext(nil, httptest.NewRequest("GET", "/", nil))
+		c.RequestBody = []byte(`{
+			"data": "abce",
+			"source": "jpeg",
+			"output": "br"
+		}`)
+		err := optimTextFromData(c)
+		assert.Equal(errContentTypeIsNotSupported, err)
+	})
+
+	t.Run("gzip to brotli", func(t *testing.T) {
+		assert := assert.New(t)
+		data, _ := tiny.GzipEncode([]byte("abcd"), 0)
+		c := elton.NewContext(nil, httptest.NewRequest("GET", "/", nil))
+		c.RequestBody = []byte(`{
+			"data": "` + base64.StdEncoding.EncodeToString(data) + `",
+			"source": "gzip",
+			"output": "br"
+		}`)
+		err := optimTextFromData(c)
+		assert.Nil(err)
+		info := c.Body.(*tiny.Text)
+		assert.Equal(tiny.EncodeTypeBr, info.Type)
+		buf, err := tiny.BrotliDecode(info.Data)
+		assert.Nil(err)
+		assert.Equal("abcd", string(buf))
+	})
+}
+
+func TestTrainDictionary(t *testing.T) {
+	t.Run("samples is nil", func(t *testing.T) {
+		assert := assert.New(t)
+		c := elton.NewContext(nil, httptest.NewRequest("POST", "/", nil))
+		c.RequestBody = []byte(`{}`)
+		err := trainDictionary(c)
+		assert.Equal(errSamplesIsNil, err)
+	})
+
+	t.Run("train and compress", func(t *testing.T) {
+		assert := assert.New(t)
+		samples := make([]string, 0)
+		for i := 0; i < 100; i++ {
+			sample := `{"id":` + strconv.Itoa(i) + `,"name":"user","status":"active","roles":["reader","writer"]}`
+			samples = append(samples, `"`+base64.StdEncoding.EncodeToString([]byte(sample))+`"`)
+		}
+		c := elton.NewContext(nil, httptest.NewRequest("POST", "/", nil))
+		c.RequestBody = []byte(`{"samples": [` + strings.Join(samples, ",") + `]}`)
+		err := trainDictionary(c)
+		assert.Nil(err)
+		dict := c.Body.(*Dictionary)
+		assert.NotEqual(uint32(0), dict.ID)
+		defer tiny.RemoveDictionary(dict.ID)
+
+		c = elton.NewContext(nil, httptest.NewRequest("POST", "/", nil))
+		c.RequestBody = []byte(`{
+			"data": "{\"id\":1000,\"name\":\"user\",\"status\":\"active\"}",
+			"output": "zstd",
+			"dictionary": ` + strconv.Itoa(int(dict.ID)) + `
+		}`)
+		err = optimTextFromData(c)
+		assert.Nil(err)
+		info := c.Body.(*tiny.Text)
+		assert.Equal(dict.ID, info.Dictionary)
+		buf, err := tiny.ZstdDecode(info.Data)
+		assert.Nil(err)
+		assert.Equal(`{"id":1000,"name":"user","status":"active"}`, string(buf))
+	})
+}
+
+func TestGetCapabilitiesFromHTTP(t *testing.T) {
+	assert := assert.New(t)
+	c := elton.NewContext(nil, httptest.NewRequest("GET", "/", nil))
+	err := getCapabilities(c)
+	assert.Nil(err)
+	capabilities := c.Body.(*tiny.Capabilities)
+	assert.Contains(capabilities.ImageInputs, tiny.JPEG)
+	assert.Contains(capabilities.TextInputs, tiny.Zstd)
 }
@@ -14,7 +14,35 @@
 
 package server
 
-import "github.com/vicanso/hes"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vicanso/hes"
+	"github.com/vicanso/tiny/log"
+	"github.com/vicanso/tiny/tiny"
+)
+
+// 外部编码工具不可用时的兜底策略：none、native或webp
+var fallbackPolicy = os.Getenv("TINY_FALLBACK")
+
+// 严格模式下外部编码工具不可用则启动失败，true表示所有工具，或指定工具列表如cjpeg,pngquant
+var strictTools = os.Getenv("TINY_STRICT")
+
+// 编码的并发限制，格式为类型=并发数/队列长度，如avif=2/20,webp=8/80
+var encodeLimits = os.Getenv("TINY_ENCODE_LIMITS")
+
+// 字典保存的目录，启动时加载该目录下的字典，训练的字典也保存至该目录
+var dictionaryPath = os.Getenv("TINY_DICTIONARY_PATH")
+
+// 最多保存的字典数量（默认128），小于等于0则不限制
+var dictionaryLimit = os.Getenv("TINY_DICTIONARY_LIMIT")
 
 var (
 	errOutputTypeIsInvalid       = hes.New("output type is not supported")
@@ -25,4 +53,246 @@ var (
 	errImageIsNil                = hes.New("image data can not be nil")
 	errTextIsNil                 = hes.New("text data can not be nil")
 	errDataIsNil                 = hes.New("data can not be nil")
+	errSamplesIsNil              = hes.New("samples can not be nil")
+	errCropRectIsInvalid         = hes.New("crop rectangle is invalid")
+	errImageIsTooLarge           = hes.New("the pixels of image exceed the limit")
+	errPageNotFound              = hes.New("page of image is not found")
+	errPageNotSupported          = hes.New("not support page of the source type")
+	errCandidateIsInvalid        = hes.New("candidate of auto output should be text encode type")
+	errDictionaryNotFound        = hes.New("dictionary is not found")
+	errDictionaryNotSupported    = hes.New("dictionary is not supported by the output type")
+	errZstdWindowSizeExceeded    = hes.New("the window size of zstd exceeds the limit")
+	errDictionaryExists          = hes.NewWithStatusCode("dictionary of the same id exists", http.StatusConflict)
+	errDictionaryLimitExceeded   = hes.New("the count of dictionaries exceeds the limit")
+	errSamplesIsTooLarge         = hes.NewWithStatusCode("the size of samples or dictionary exceeds the limit", http.StatusRequestEntityTooLarge)
+	errTextIsTooLarge            = hes.NewWithStatusCode("the size of decoded data exceeds the limit", http.StatusRequestEntityTooLarge)
+	errEncodeQueueIsFull         = hes.NewWithStatusCode("the server is busy, please try again later", http.StatusServiceUnavailable)
+	errOptimTimeout              = hes.NewWithStatusCode("optim timeout", http.StatusGatewayTimeout)
 )
+
+// convertOptimError convert the error of image optim to http error
+func convertOptimError(err error) error {
+	if errors.Is(err, tiny.ErrQueueIsFull) {
+		return errEncodeQueueIsFull
+	}
+	// 等待编码队列或处理超时
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errOptimTimeout
+	}
+	if errors.Is(err, tiny.ErrCropRectIsInvalid) {
+		return errCropRectIsInvalid
+	}
+	if errors.Is(err, tiny.ErrImageIsTooLarge) {
+		return errImageIsTooLarge
+	}
+	if errors.Is(err, tiny.ErrPageNotFound) {
+		return errPageNotFound
+	}
+	if errors.Is(err, tiny.ErrPageNotSupported) {
+		return errPageNotSupported
+	}
+	// 保留无效的操作名称
+	if errors.Is(err, tiny.ErrOperationIsInvalid) {
+		return hes.Wrap(err)
+	}
+	return convertToolError(err)
+}
+
+// convertTextError convert the error of text optim to http error
+func convertTextError(err error) error {
+	if errors.Is(err, tiny.ErrDecodeSizeExceeded) {
+		return errTextIsTooLarge
+	}
+	if errors.Is(err, tiny.ErrCandidateIsInvalid) {
+		return errCandidateIsInvalid
+	}
+	if errors.Is(err, tiny.ErrDictionaryNotFound) {
+		return errDictionaryNotFound
+	}
+	if errors.Is(err, tiny.ErrDictionaryNotSupported) {
+		return errDictionaryNotSupported
+	}
+	if errors.Is(err, tiny.ErrZstdWindowSizeExceeded) {
+		return errZstdWindowSizeExceeded
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errOptimTimeout
+	}
+	return err
+}
+
+// convertDictionaryError convert the error of dictionary training to http error
+func convertDictionaryError(err error) error {
+	if errors.Is(err, tiny.ErrDictionaryExists) {
+		return errDictionaryExists
+	}
+	if errors.Is(err, tiny.ErrDictionaryLimitExceeded) {
+		return errDictionaryLimitExceeded
+	}
+	if errors.Is(err, tiny.ErrSamplesIsTooLarge) {
+		return errSamplesIsTooLarge
+	}
+	// 保留无法生成字典的原因
+	if errors.Is(err, tiny.ErrSamplesIsInvalid) {
+		return hes.Wrap(err)
+	}
+	return err
+}
+
+// InitEncodeLimits set the encode limits of TINY_ENCODE_LIMITS
+func InitEncodeLimits() error {
+	if encodeLimits == "" {
+		return nil
+	}
+	for _, item := range strings.Split(encodeLimits, ",") {
+		arr := strings.Split(strings.TrimSpace(item), "=")
+		if len(arr) != 2 {
+			return fmt.Errorf("encode limit(%s) is invalid", item)
+		}
+		encodeType := tiny.ConvertToEncodeType(arr[0])
+		if encodeType == tiny.EncodeTypeUnknown {
+			return fmt.Errorf("encode type(%s) is invalid", arr[0])
+		}
+		values := strings.Split(arr[1], "/")
+		concurrency, err := strconv.Atoi(values[0])
+		if err != nil {
+			return err
+		}
+		// 未指定队列长度则为并发数的10倍
+		queueSize := concurrency * 10
+		if len(values) > 1 {
+			queueSize, err = strconv.Atoi(values[1])
+			if err != nil {
+				return err
+			}
+		}
+		tiny.SetEncodeLimit(encodeType, concurrency, queueSize)
+		log.Default().Info().
+			Str("type", encodeType.String()).
+			Int("concurrency", concurrency).
+			Int("queueSize", queueSize).
+			Msg("set encode limit")
+	}
+	return nil
+}
+
+// convertToolError convert the error of external tool to http error,
+// the tool, exit code and stderr are added to extra
+func convertToolError(err error) error {
+	toolErr := &tiny.ToolError{}
+	if !errors.As(err, &toolErr) {
+		return err
+	}
+	he := hes.NewWithErrorStatusCode(err, http.StatusInternalServerError)
+	he.Category = "tool"
+	he.Exception = true
+	he.AddExtra("tool", toolErr.Tool)
+	he.AddExtra("exitCode", toolErr.ExitCode)
+	he.AddExtra("stderr", toolErr.Stderr)
+	return he
+}
+
+// getRequiredTools get the required tools of strict mode
+func getRequiredTools() []string {
+	switch strictTools {
+	case "", "false":
+		return nil
+	case "true":
+		tools := make([]string, 0)
+		for _, tool := range tiny.GetTools() {
+			tools = append(tools, tool.Name)
+		}
+		return tools
+	default:
+		return strings.Split(strictTools, ",")
+	}
+}
+
+// ProbeTools probe the external tools of encoders and log their versions,
+// it returns error if the required tool is missing in strict mode
+func ProbeTools() error {
+	installedTools := make(map[string]bool)
+	for _, tool := range tiny.ProbeTools(context.Background()) {
+		installedTools[tool.Name] = tool.Installed
+		if !tool.Installed {
+			log.Default().Warn().
+				Str("tool", tool.Name).
+				Msg("tool is not installed")
+			continue
+		}
+		log.Default().Info().
+			Str("tool", tool.Name).
+			Str("path", tool.Path).
+			Str("version", tool.Version).
+			Msg("tool is installed")
+	}
+	for _, name := range getRequiredTools() {
+		name = strings.TrimSpace(name)
+		if !installedTools[name] {
+			return fmt.Errorf("tool %s is required but not installed", name)
+		}
+	}
+	return nil
+}
+
+// LoadDictionaries set the limit of TINY_DICTIONARY_LIMIT and
+// load the dictionaries of TINY_DICTIONARY_PATH
+func LoadDictionaries() error {
+	if dictionaryLimit != "" {
+		limit, err := strconv.Atoi(dictionaryLimit)
+		if err != nil {
+			return fmt.Errorf("dictionary limit(%s) is invalid", dictionaryLimit)
+		}
+		tiny.SetDictionaryLimit(limit)
+	}
+	if dictionaryPath == "" {
+		return nil
+	}
+	ids, err := tiny.LoadDictionaries(dictionaryPath)
+	if err != nil {
+		return err
+	}
+	log.Default().Info().
+		Str("path", dictionaryPath).
+		Interface("ids", ids).
+		Msg("load dictionaries success")
+	return nil
+}
+
+// InitFallbackPolicy set the fallback policy of image encode from TINY_FALLBACK
+func InitFallbackPolicy() {
+	if fallbackPolicy != "" {
+		tiny.SetFallbackPolicy(tiny.ConvertToFallbackPolicy(fallbackPolicy))
+	}
+	log.Default().Info().
+		Str("policy", tiny.GetFallbackPolicy().String()).
+		Msg("image encode fallback policy")
+}
+
+func cleanTempFiles() {
+	count, err := tiny.CleanTempFiles(tiny.DefaultTempFileMaxAge)
+	if err != nil {
+		log.Default().Error().
+			Err(err).
+			Msg("clean temp files fail")
+		return
+	}
+	if count != 0 {
+		log.Default().Info().
+			Int("count", count).
+			Msg("clean orphan temp files")
+	}
+}
+
+// StartTempFileCleaner clean the orphan temp files of external tools,
+// and then clean them periodically
+func StartTempFileCleaner() {
+	cleanTempFiles()
+	go func() {
+		ticker := time.NewTicker(tiny.DefaultTempFileMaxAge)
+		defer ticker.Stop()
+		for range ticker.C {
+			cleanTempFiles()
+		}
+	}()
+}
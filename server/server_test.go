@@ -0,0 +1,103 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vicanso/hes"
+	"github.com/vicanso/tiny/tiny"
+)
+
+func TestConvertToolError(t *testing.T) {
+	assert := assert.New(t)
+
+	err := errors.New("abcd")
+	assert.Equal(err, convertToolError(err))
+
+	err = convertToolError(&tiny.ToolError{
+		Tool:     tiny.EncoderCavif,
+		ExitCode: 1,
+		Stderr:   "crash",
+		Err:      errors.New("exit status 1"),
+	})
+	he, ok := err.(*hes.Error)
+	assert.True(ok)
+	assert.Equal("tool", he.Category)
+	assert.Equal(500, he.StatusCode)
+	assert.Equal(tiny.EncoderCavif, he.Extra["tool"])
+	assert.Equal(1, he.Extra["exitCode"])
+	assert.Equal("crash", he.Extra["stderr"])
+}
+
+func TestInitEncodeLimits(t *testing.T) {
+	assert := assert.New(t)
+	// 恢复全局的编码限制
+	for _, encodeType := range []tiny.EncodeType{tiny.EncodeTypeAVIF, tiny.EncodeTypeWEBP} {
+		encodeType := encodeType
+		concurrency, queueSize := tiny.GetEncodeLimit(encodeType)
+		t.Cleanup(func() {
+			tiny.SetEncodeLimit(encodeType, concurrency, queueSize)
+		})
+	}
+	defer func() {
+		encodeLimits = ""
+	}()
+
+	encodeLimits = "avif"
+	assert.NotNil(InitEncodeLimits())
+
+	encodeLimits = "abcd=1/10"
+	assert.NotNil(InitEncodeLimits())
+
+	encodeLimits = "avif=2/20, webp=8"
+	assert.Nil(InitEncodeLimits())
+	concurrency, queueSize := tiny.GetEncodeLimit(tiny.EncodeTypeAVIF)
+	assert.Equal(2, concurrency)
+	assert.Equal(20, queueSize)
+}
+
+func TestConvertOptimError(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(errEncodeQueueIsFull, convertOptimError(tiny.ErrQueueIsFull))
+	assert.Equal(errImageIsTooLarge, convertOptimError(tiny.ErrImageIsTooLarge))
+	assert.Equal(errPageNotFound, convertOptimError(tiny.ErrPageNotFound))
+	assert.Equal(errPageNotSupported, convertOptimError(tiny.ErrPageNotSupported))
+	assert.Equal(errOptimTimeout, convertOptimError(fmt.Errorf("wait encode: %w", context.DeadlineExceeded)))
+}
+
+func TestConvertTextError(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(errTextIsTooLarge, convertTextError(tiny.ErrDecodeSizeExceeded))
+	assert.Equal(errOptimTimeout, convertTextError(context.DeadlineExceeded))
+	assert.Equal(errCandidateIsInvalid, convertTextError(tiny.ErrCandidateIsInvalid))
+	assert.Equal(errDictionaryNotFound, convertTextError(tiny.ErrDictionaryNotFound))
+	assert.Equal(errDictionaryNotSupported, convertTextError(tiny.ErrDictionaryNotSupported))
+	assert.Equal(errZstdWindowSizeExceeded, convertTextError(tiny.ErrZstdWindowSizeExceeded))
+}
+
+func TestConvertDictionaryError(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(errDictionaryExists, convertDictionaryError(tiny.ErrDictionaryExists))
+	assert.Equal(errDictionaryLimitExceeded, convertDictionaryError(tiny.ErrDictionaryLimitExceeded))
+	assert.Equal(errSamplesIsTooLarge, convertDictionaryError(tiny.ErrSamplesIsTooLarge))
+	he := convertDictionaryError(fmt.Errorf("%w: 0 sequences", tiny.ErrSamplesIsInvalid)).(*hes.Error)
+	assert.Equal(http.StatusBadRequest, he.StatusCode)
+}
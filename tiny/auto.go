@@ -0,0 +1,86 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCandidateIsInvalid the candidate of auto output is not text encode type
+var ErrCandidateIsInvalid = errors.New("candidate of auto output should be text encode type")
+
+// defaultAutoCandidates the default candidates of auto output,
+// the faster encode type is tried first
+var defaultAutoCandidates = []EncodeType{
+	EncodeTypeSnappy,
+	EncodeTypeLz4,
+	EncodeTypeZstd,
+	EncodeTypeGzip,
+	EncodeTypeBr,
+}
+
+// textAutoEncode encode the data with all candidates and
+// return the smallest result
+func textAutoEncode(data []byte, opts *TextOptimOptions, zstdOpts *ZstdOptions) (info *Text, err error) {
+	candidates := opts.Candidates
+	if len(candidates) == 0 {
+		candidates = defaultAutoCandidates
+	}
+	for _, t := range candidates {
+		if !IsTextType(t) {
+			err = ErrCandidateIsInvalid
+			return
+		}
+	}
+	encodeOpts := &EncodeOptions{
+		Quality:    opts.Quality,
+		Zstd:       zstdOpts,
+		Dictionary: opts.Dictionary,
+	}
+	start := time.Now()
+	for index, t := range candidates {
+		// 超时后不再尝试其它类型（至少有一个结果），
+		// 编码过程无法中断，因此实际耗时可能超出
+		if index != 0 && opts.Timeout > 0 && time.Since(start) >= opts.Timeout {
+			break
+		}
+		buf, e := textEncode(data, t, encodeOpts)
+		if e != nil {
+			err = e
+			return
+		}
+		if info != nil && len(buf) >= len(info.Data) {
+			continue
+		}
+		info = &Text{
+			Data: buf,
+			Type: t,
+		}
+	}
+	if info.Type == EncodeTypeZstd || info.Type == EncodeTypeBr {
+		info.Dictionary = opts.Dictionary
+	}
+	return
+}
+
+func containsEncodeType(types []EncodeType, t EncodeType) bool {
+	for _, item := range types {
+		if item == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextAutoEncode(t *testing.T) {
+	originalData := []byte(strings.Repeat(`{"name": "tiny", "description": "compress text and image"}`, 100))
+
+	t.Run("smallest of all", func(t *testing.T) {
+		assert := assert.New(t)
+		info, err := TextOptimWithOptions(originalData, &TextOptimOptions{
+			Output: EncodeTypeAuto,
+		})
+		assert.Nil(err)
+		for _, encodeType := range defaultAutoCandidates {
+			buf, err := textEncode(originalData, encodeType, &EncodeOptions{})
+			assert.Nil(err)
+			assert.LessOrEqual(len(info.Data), len(buf))
+		}
+		data, err := TextDecode(info.Data, info.Type)
+		assert.Nil(err)
+		assert.Equal(originalData, data)
+	})
+
+	t.Run("candidates", func(t *testing.T) {
+		assert := assert.New(t)
+		info, err := TextOptimWithOptions(originalData, &TextOptimOptions{
+			Output:     EncodeTypeAuto,
+			Candidates: []EncodeType{EncodeTypeSnappy, EncodeTypeGzip},
+		})
+		assert.Nil(err)
+		assert.Equal(EncodeTypeGzip, info.Type)
+
+		_, err = TextOptimWithOptions(originalData, &TextOptimOptions{
+			Output:     EncodeTypeAuto,
+			Candidates: []EncodeType{EncodeTypePNG},
+		})
+		assert.Equal(ErrCandidateIsInvalid, err)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		assert := assert.New(t)
+		// 超时后只使用第一个类型
+		info, err := TextOptimWithOptions(originalData, &TextOptimOptions{
+			Output:     EncodeTypeAuto,
+			Candidates: []EncodeType{EncodeTypeSnappy, EncodeTypeBr},
+			Timeout:    time.Nanosecond,
+		})
+		assert.Nil(err)
+		assert.Equal(EncodeTypeSnappy, info.Type)
+	})
+
+	t.Run("dictionary", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := TextOptimWithOptions(originalData, &TextOptimOptions{
+			Output:     EncodeTypeAuto,
+			Candidates: []EncodeType{EncodeTypeGzip},
+			Dictionary: 1,
+		})
+		assert.Equal(ErrDictionaryNotSupported, err)
+	})
+}
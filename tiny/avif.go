@@ -22,6 +22,7 @@ import (
 	"strconv"
 )
 
+// AVIFEncode avif encode
 func AVIFEncode(ctx context.Context, img image.Image, quality int) (data []byte, err error) {
 	if quality <= minAvifQuality || quality > maxAvifQuality {
 		quality = defaultAvifQuality
@@ -31,6 +32,7 @@ func AVIFEncode(ctx context.Context, img image.Image, quality int) (data []byte,
 	if err != nil {
 		return
 	}
+	// cavif不支持stdin/stdout，使用临时文件
 	fn := func(originalFile, targetFile string) []string {
 		// cavif --quality 80 --output ./test.avif -q optim
 		return []string{
@@ -50,3 +52,49 @@ func AVIFEncode(ctx context.Context, img image.Image, quality int) (data []byte,
 	data = fileBuffer.Bytes()
 	return
 }
+
+// AVIFDecode avif decode by avifdec of libavif
+func AVIFDecode(ctx context.Context, data []byte) (image.Image, error) {
+	// avifdec根据输出文件的后缀选择格式
+	fn := func(originalFile, targetFile string) []string {
+		// avifdec --depth 8 ./test.avif ./test.png
+		return []string{
+			DecoderAvifdec,
+			"--depth",
+			"8",
+			originalFile,
+			targetFile,
+		}
+	}
+	fileBuffer := new(bytes.Buffer)
+	err := doCommandConvertWithExt(ctx, data, ".png", fn, fileBuffer)
+	if err != nil {
+		return nil, err
+	}
+	return png.Decode(fileBuffer)
+}
+
+// avifEncode avif encode, the image will be encoded to webp if cavif
+// is not found and the fallback policy is webp
+func avifEncode(ctx context.Context, img image.Image, quality int) (*Image, error) {
+	data, err := AVIFEncode(ctx, img, quality)
+	if err == nil {
+		return &Image{
+			Data:    data,
+			Type:    EncodeTypeAVIF,
+			Encoder: EncoderCavif,
+		}, nil
+	}
+	if !isToolNotFound(err) || GetFallbackPolicy() != FallbackWEBP {
+		return nil, err
+	}
+	data, err = WEBPEncode(img, quality)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{
+		Data:    data,
+		Type:    EncodeTypeWEBP,
+		Encoder: EncoderLibwebp,
+	}, nil
+}
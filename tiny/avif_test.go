@@ -0,0 +1,47 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAVIFDecode(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	if _, err := exec.LookPath(DecoderAvifdec); err != nil {
+		_, err = AVIFDecode(ctx, newTestAVIF([]byte("av01 data")))
+		assert.True(isToolNotFound(err))
+		return
+	}
+	if _, err := exec.LookPath(EncoderCavif); err != nil {
+		t.Skip("cavif is not installed")
+	}
+	img := getTestImage()
+	data, err := AVIFEncode(ctx, img, 80)
+	assert.Nil(err)
+
+	result, err := ImageOptimWithOptions(ctx, data, &ImageOptimOptions{
+		Source: EncodeTypeAVIF,
+		Output: EncodeTypeWEBP,
+		Width:  img.Bounds().Dx() / 2,
+	})
+	assert.Nil(err)
+	assert.Equal(img.Bounds().Dx()/2, result.Width)
+}
@@ -15,19 +15,198 @@
 package tiny
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
 
 	"github.com/andybalholm/brotli"
 )
 
-// BrotliEncode brotli encode
-func BrotliEncode(buf []byte, quality int) ([]byte, error) {
+// BrotliDictionary the content encoding of brotli data encoded with dictionary,
+// which can only be decoded by tiny with the same dictionary
+const BrotliDictionary = "x-brotli-dictionary"
+
+// brotliDictionaryMagic the magic of brotli data encoded with dictionary,
+// it is the reserved window bits of brotli(RFC 7932), so the standard
+// brotli stream never starts with it
+var brotliDictionaryMagic = []byte{0x11, 0xff}
+
+// magic(2) + dictionary id(4) + quality(1)
+const brotliDictionaryHeaderSize = 7
+
+var errBrotliDictionaryHeaderIsInvalid = errors.New("header of brotli dictionary data is invalid")
+
+// brotliPrefix the brotli data of dictionary content which is flushed,
+// it is the same for the dictionary and quality
+type brotliPrefix struct {
+	data []byte
+	size int
+}
+
+type brotliPrefixKey struct {
+	id      uint32
+	quality int
+}
+
+// 解压时需要字典内容的压缩数据，缓存避免每次重新压缩
+var brotliPrefixes sync.Map
+
+// removeBrotliPrefixes remove the cached prefixes of dictionary
+func removeBrotliPrefixes(id uint32) {
+	brotliPrefixes.Range(func(key, _ interface{}) bool {
+		if key.(brotliPrefixKey).id == id {
+			brotliPrefixes.Delete(key)
+		}
+		return true
+	})
+}
+
+// prefixWriter write the prefix before the first write
+type prefixWriter struct {
+	w      io.Writer
+	prefix []byte
+}
+
+func (pw *prefixWriter) Write(p []byte) (int, error) {
+	if len(pw.prefix) != 0 {
+		_, err := pw.w.Write(pw.prefix)
+		if err != nil {
+			return 0, err
+		}
+		pw.prefix = nil
+	}
+	return pw.w.Write(p)
+}
+
+func getBrotliQuality(quality int) int {
 	if quality <= 0 || quality > maxBrotliQuality {
-		quality = defauttBrotliQuality
+		return defauttBrotliQuality
 	}
+	return quality
+}
+
+// NewBrotliWriter create a brotli writer
+func NewBrotliWriter(w io.Writer, quality int) (io.WriteCloser, error) {
+	return brotli.NewWriterLevel(w, getBrotliQuality(quality)), nil
+}
+
+// NewBrotliWriterWithDictionary create a brotli writer with the dictionary of store.
+// The content of dictionary is written and flushed first as the history of data,
+// and only the data after it is output with the header(magic, id and quality),
+// so it can only be decoded by NewBrotliReader with the same dictionary.
+func NewBrotliWriterWithDictionary(w io.Writer, quality int, id uint32) (io.WriteCloser, error) {
+	content, err := getDictionaryContent(id)
+	if err != nil {
+		return nil, err
+	}
+	quality = getBrotliQuality(quality)
 	buffer := new(bytes.Buffer)
-	w := brotli.NewWriterLevel(buffer, quality)
-	_, err := w.Write(buf)
+	pw := &prefixWriter{
+		w: buffer,
+	}
+	bw := brotli.NewWriterLevel(pw, quality)
+	_, err = bw.Write(content)
+	if err != nil {
+		return nil, err
+	}
+	// flush后为完整的字节，后续的数据可直接拼接
+	err = bw.Flush()
+	if err != nil {
+		return nil, err
+	}
+	brotliPrefixes.Store(brotliPrefixKey{
+		id:      id,
+		quality: quality,
+	}, &brotliPrefix{
+		data: buffer.Bytes(),
+		size: len(content),
+	})
+
+	header := make([]byte, brotliDictionaryHeaderSize)
+	copy(header, brotliDictionaryMagic)
+	binary.LittleEndian.PutUint32(header[2:], id)
+	header[6] = byte(quality)
+	// 延迟写入header，避免创建时阻塞（如pipe）
+	pw.w = w
+	pw.prefix = header
+	return bw, nil
+}
+
+// getBrotliPrefix get the brotli prefix of dictionary and quality
+func getBrotliPrefix(id uint32, quality int) (*brotliPrefix, error) {
+	key := brotliPrefixKey{
+		id:      id,
+		quality: quality,
+	}
+	if v, ok := brotliPrefixes.Load(key); ok {
+		return v.(*brotliPrefix), nil
+	}
+	// 使用与压缩相同的方式生成
+	_, err := NewBrotliWriterWithDictionary(io.Discard, quality, id)
+	if err != nil {
+		return nil, err
+	}
+	v, _ := brotliPrefixes.Load(key)
+	return v.(*brotliPrefix), nil
+}
+
+// newBrotliDictionaryReader create a reader of brotli data encoded with dictionary
+func newBrotliDictionaryReader(r io.Reader) (io.ReadCloser, error) {
+	header := make([]byte, brotliDictionaryHeaderSize)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, errBrotliDictionaryHeaderIsInvalid
+	}
+	quality := int(header[6])
+	if quality <= 0 || quality > maxBrotliQuality {
+		return nil, errBrotliDictionaryHeaderIsInvalid
+	}
+	prefix, err := getBrotliPrefix(binary.LittleEndian.Uint32(header[2:]), quality)
+	if err != nil {
+		return nil, err
+	}
+	br := brotli.NewReader(io.MultiReader(bytes.NewReader(prefix.data), r))
+	// 跳过字典的内容
+	_, err = io.CopyN(io.Discard, br, int64(prefix.size))
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(br), nil
+}
+
+// NewBrotliReader create a brotli reader,
+// the data encoded with dictionary is also supported
+func NewBrotliReader(r io.Reader) (io.ReadCloser, error) {
+	reader := bufio.NewReader(r)
+	magic, err := reader.Peek(len(brotliDictionaryMagic))
+	if err == nil && bytes.Equal(magic, brotliDictionaryMagic) {
+		return newBrotliDictionaryReader(reader)
+	}
+	return io.NopCloser(brotli.NewReader(reader)), nil
+}
+
+// BrotliEncode brotli encode
+func BrotliEncode(buf []byte, quality int) ([]byte, error) {
+	return BrotliEncodeWithDictionary(buf, quality, 0)
+}
+
+// BrotliEncodeWithDictionary brotli encode with the dictionary of id, 0 means no dictionary
+func BrotliEncodeWithDictionary(buf []byte, quality int, id uint32) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	var w io.WriteCloser
+	var err error
+	if id != 0 {
+		w, err = NewBrotliWriterWithDictionary(buffer, quality, id)
+	} else {
+		w, err = NewBrotliWriter(buffer, quality)
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(buf)
 	if err != nil {
 		return nil, err
 	}
@@ -37,3 +216,12 @@ func BrotliEncode(buf []byte, quality int) ([]byte, error) {
 	}
 	return buffer.Bytes(), nil
 }
+
+// BrotliDecode brotli decode, the dictionary is found by the id of header
+func BrotliDecode(buf []byte) ([]byte, error) {
+	r, err := NewBrotliReader(bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	return readAllWithLimit(r)
+}
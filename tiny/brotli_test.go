@@ -27,3 +27,13 @@ func TestBrotliEncode(t *testing.T) {
 	assert.Nil(err)
 	assert.NotNil(buf)
 }
+
+func TestBrotliDecode(t *testing.T) {
+	assert := assert.New(t)
+	originalBuf := []byte("abcdabcdabcdabcdabcdabcdabcdabcd")
+	buf, err := BrotliEncode(originalBuf, 0)
+	assert.Nil(err)
+	data, err := BrotliDecode(buf)
+	assert.Nil(err)
+	assert.Equal(originalBuf, data)
+}
@@ -0,0 +1,422 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"sync"
+
+	"golang.org/x/image/bmp"
+)
+
+// 自定义编码类型的起始值，避免与内置类型冲突
+const minCustomEncodeType EncodeType = 1000
+
+var (
+	errCodecIsInvalid     = errors.New("codec should have text or image encoder/decoder")
+	errCodecNameIsNil     = errors.New("codec name can not be nil")
+	errCodecNameIsExisted = errors.New("codec name is used by other encode type")
+)
+
+type (
+	// EncodeOptions the options of encoder
+	EncodeOptions struct {
+		// Quality the quality of encoder
+		Quality int
+		// Zstd the options of zstd encoder
+		Zstd *ZstdOptions
+		// Dictionary the id of dictionary for brotli encoder,
+		// zstd uses the dictionary of zstd options
+		Dictionary uint32
+		// Dither use floyd-steinberg dithering for palette image
+		Dither bool
+	}
+	// Encoder text encoder
+	Encoder interface {
+		Encode(data []byte, opts *EncodeOptions) ([]byte, error)
+	}
+	// Decoder text decoder
+	Decoder interface {
+		Decode(data []byte) ([]byte, error)
+	}
+	// StreamEncoder the optional interface of text encoder which supports stream
+	StreamEncoder interface {
+		NewWriter(w io.Writer, opts *EncodeOptions) (io.WriteCloser, error)
+	}
+	// StreamDecoder the optional interface of text decoder which supports stream
+	StreamDecoder interface {
+		NewReader(r io.Reader) (io.ReadCloser, error)
+	}
+	// ImageEncoder image encoder, the type of result can be different from
+	// the codec when the encoder falls back to other type
+	ImageEncoder interface {
+		Encode(ctx context.Context, img image.Image, opts *EncodeOptions) (*Image, error)
+	}
+	// ImageDecoder image decoder
+	ImageDecoder interface {
+		Decode(data []byte) (image.Image, error)
+	}
+	// Animation the animated image, all frames have the same size
+	Animation struct {
+		Frames []image.Image
+		// Delays the delay of frames in milliseconds
+		Delays []int
+		// LoopCount the number of times to play, 0 means infinite
+		LoopCount int
+	}
+	// AnimationEncoder the optional interface of image encoder which supports animation
+	AnimationEncoder interface {
+		EncodeAnimation(ctx context.Context, anim *Animation, opts *EncodeOptions) (*Image, error)
+	}
+	// ContextDecoder the optional interface of image decoder which supports context,
+	// it's used for the decoder of external tool, the tool will be killed if the context is done
+	ContextDecoder interface {
+		DecodeContext(ctx context.Context, data []byte) (image.Image, error)
+	}
+	// PageDecoder the optional interface of image decoder which supports multiple pages
+	PageDecoder interface {
+		DecodePage(data []byte, page int) (image.Image, error)
+	}
+	// AnimationDecoder the optional interface of image decoder which supports animation
+	AnimationDecoder interface {
+		DecodeAnimation(data []byte) (*Animation, error)
+	}
+	// Codec the codec of encode type, it should be text codec(Encoder/Decoder)
+	// or image codec(ImageEncoder/ImageDecoder)
+	Codec struct {
+		// Type the encode type, a new type will be allocated if it is 0
+		Type EncodeType
+		// Name the name of encode type, e.g. gzip
+		Name string
+		// Aliases the other names of encode type
+		Aliases []string
+		// Tool the external tool which is required by image encoder
+		Tool string
+		// Fallback the min fallback policy to encode without tool
+		Fallback FallbackPolicy
+		// DecodeTool the external tool which is required by image decoder
+		DecodeTool string
+
+		Encoder      Encoder
+		Decoder      Decoder
+		ImageEncoder ImageEncoder
+		ImageDecoder ImageDecoder
+	}
+)
+
+var codecs = struct {
+	sync.RWMutex
+	types map[EncodeType]*Codec
+	names map[string]EncodeType
+	next  EncodeType
+}{
+	types: make(map[EncodeType]*Codec),
+	names: make(map[string]EncodeType),
+	next:  minCustomEncodeType,
+}
+
+// IsText check the codec is text codec
+func (c *Codec) IsText() bool {
+	return c.Encoder != nil || c.Decoder != nil
+}
+
+// IsImage check the codec is image codec
+func (c *Codec) IsImage() bool {
+	return c.ImageEncoder != nil || c.ImageDecoder != nil
+}
+
+// RegisterCodec register the codec, the codec of the same type will be replaced.
+// It returns the encode type of codec.
+func RegisterCodec(codec *Codec) (EncodeType, error) {
+	if codec.Name == "" {
+		return EncodeTypeUnknown, errCodecNameIsNil
+	}
+	if codec.IsText() == codec.IsImage() {
+		return EncodeTypeUnknown, errCodecIsInvalid
+	}
+	codecs.Lock()
+	defer codecs.Unlock()
+	t := codec.Type
+	if t == EncodeTypeUnknown {
+		t = codecs.next
+	}
+	names := append([]string{codec.Name}, codec.Aliases...)
+	for _, name := range names {
+		if name == Auto {
+			return EncodeTypeUnknown, errCodecNameIsExisted
+		}
+		if v, ok := codecs.names[name]; ok && v != t {
+			return EncodeTypeUnknown, errCodecNameIsExisted
+		}
+	}
+	// 替换时删除原有的名称
+	if prev, ok := codecs.types[t]; ok {
+		delete(codecs.names, prev.Name)
+		for _, name := range prev.Aliases {
+			delete(codecs.names, name)
+		}
+	}
+	c := *codec
+	c.Type = t
+	codecs.types[t] = &c
+	for _, name := range names {
+		codecs.names[name] = t
+	}
+	if t >= codecs.next {
+		codecs.next = t + 1
+	}
+	return t, nil
+}
+
+// GetCodec get the codec of encode type
+func GetCodec(t EncodeType) (*Codec, bool) {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	c, ok := codecs.types[t]
+	return c, ok
+}
+
+// getCodecType get the encode type of codec name
+func getCodecType(name string) EncodeType {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	return codecs.names[name]
+}
+
+// IsTextType check the encode type is text type
+func IsTextType(t EncodeType) bool {
+	c, ok := GetCodec(t)
+	return ok && c.IsText()
+}
+
+// IsImageType check the encode type is image type
+func IsImageType(t EncodeType) bool {
+	c, ok := GetCodec(t)
+	return ok && c.IsImage()
+}
+
+// DefaultImageOutput get the default output type of the image source type,
+// it is the source type, or png if the source type only supports decoding(e.g. bmp and tiff)
+func DefaultImageOutput(t EncodeType) EncodeType {
+	c, ok := GetCodec(t)
+	if !ok || c.ImageEncoder == nil {
+		return EncodeTypePNG
+	}
+	return t
+}
+
+type (
+	gzipCodec   struct{}
+	brotliCodec struct{}
+	snappyCodec struct{}
+	lz4Codec    struct{}
+	zstdCodec   struct{}
+	jpegCodec   struct{}
+	pngCodec    struct{}
+	webpCodec   struct{}
+	avifCodec   struct{}
+	gifCodec    struct{}
+	bmpCodec    struct{}
+	tiffCodec   struct{}
+)
+
+func (gzipCodec) Encode(data []byte, opts *EncodeOptions) ([]byte, error) {
+	return GzipEncode(data, opts.Quality)
+}
+func (gzipCodec) Decode(data []byte) ([]byte, error) {
+	return GzipDecode(data)
+}
+func (gzipCodec) NewWriter(w io.Writer, opts *EncodeOptions) (io.WriteCloser, error) {
+	return NewGzipWriter(w, opts.Quality)
+}
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return NewGzipReader(r)
+}
+
+func (brotliCodec) Encode(data []byte, opts *EncodeOptions) ([]byte, error) {
+	return BrotliEncodeWithDictionary(data, opts.Quality, opts.Dictionary)
+}
+func (brotliCodec) Decode(data []byte) ([]byte, error) {
+	return BrotliDecode(data)
+}
+func (brotliCodec) NewWriter(w io.Writer, opts *EncodeOptions) (io.WriteCloser, error) {
+	if opts.Dictionary != 0 {
+		return NewBrotliWriterWithDictionary(w, opts.Quality, opts.Dictionary)
+	}
+	return NewBrotliWriter(w, opts.Quality)
+}
+func (brotliCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return NewBrotliReader(r)
+}
+
+func (snappyCodec) Encode(data []byte, opts *EncodeOptions) ([]byte, error) {
+	return SnappyEncode(data)
+}
+func (snappyCodec) Decode(data []byte) ([]byte, error) {
+	return SnappyDecode(data)
+}
+func (snappyCodec) NewWriter(w io.Writer, opts *EncodeOptions) (io.WriteCloser, error) {
+	return NewSnappyWriter(w)
+}
+func (snappyCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return NewSnappyReader(r)
+}
+
+func (lz4Codec) Encode(data []byte, opts *EncodeOptions) ([]byte, error) {
+	return Lz4Encode(data, opts.Quality)
+}
+func (lz4Codec) Decode(data []byte) ([]byte, error) {
+	return Lz4Decode(data)
+}
+func (lz4Codec) NewWriter(w io.Writer, opts *EncodeOptions) (io.WriteCloser, error) {
+	return NewLz4Writer(w, opts.Quality)
+}
+func (lz4Codec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return NewLz4Reader(r)
+}
+
+func (zstdCodec) Encode(data []byte, opts *EncodeOptions) ([]byte, error) {
+	return ZstdEncodeWithOptions(data, opts.Quality, opts.Zstd)
+}
+func (zstdCodec) Decode(data []byte) ([]byte, error) {
+	return ZstdDecode(data)
+}
+func (zstdCodec) NewWriter(w io.Writer, opts *EncodeOptions) (io.WriteCloser, error) {
+	return NewZstdWriter(w, opts.Quality, opts.Zstd)
+}
+func (zstdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return NewZstdReader(r)
+}
+
+func (jpegCodec) Encode(ctx context.Context, img image.Image, opts *EncodeOptions) (*Image, error) {
+	return jpegEncode(ctx, img, opts.Quality)
+}
+func (jpegCodec) Decode(data []byte) (image.Image, error) {
+	return jpegDecode(data)
+}
+
+func (pngCodec) Encode(ctx context.Context, img image.Image, opts *EncodeOptions) (*Image, error) {
+	return pngEncode(ctx, img, opts.Quality)
+}
+func (pngCodec) Decode(data []byte) (image.Image, error) {
+	return png.Decode(bytes.NewReader(data))
+}
+
+func (webpCodec) Encode(ctx context.Context, img image.Image, opts *EncodeOptions) (*Image, error) {
+	data, err := WEBPEncode(img, opts.Quality)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{
+		Data:    data,
+		Type:    EncodeTypeWEBP,
+		Encoder: EncoderLibwebp,
+	}, nil
+}
+func (webpCodec) Decode(data []byte) (image.Image, error) {
+	return WebpDecode(bytes.NewReader(data))
+}
+func (webpCodec) EncodeAnimation(ctx context.Context, anim *Animation, opts *EncodeOptions) (*Image, error) {
+	data, err := WEBPEncodeAnimation(anim, opts.Quality)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{
+		Data:    data,
+		Type:    EncodeTypeWEBP,
+		Encoder: EncoderLibwebp,
+	}, nil
+}
+
+func (avifCodec) Encode(ctx context.Context, img image.Image, opts *EncodeOptions) (*Image, error) {
+	return avifEncode(ctx, img, opts.Quality)
+}
+func (c avifCodec) Decode(data []byte) (image.Image, error) {
+	return c.DecodeContext(context.Background(), data)
+}
+func (avifCodec) DecodeContext(ctx context.Context, data []byte) (image.Image, error) {
+	return AVIFDecode(ctx, data)
+}
+
+func (gifCodec) Encode(ctx context.Context, img image.Image, opts *EncodeOptions) (*Image, error) {
+	data, err := GIFEncode(img, opts.Quality, opts.Dither)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{
+		Data:    data,
+		Type:    EncodeTypeGIF,
+		Encoder: EncoderGoGIF,
+	}, nil
+}
+func (gifCodec) EncodeAnimation(ctx context.Context, anim *Animation, opts *EncodeOptions) (*Image, error) {
+	data, err := GIFEncodeAnimation(anim, opts.Quality, opts.Dither)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{
+		Data:    data,
+		Type:    EncodeTypeGIF,
+		Encoder: EncoderGoGIF,
+	}, nil
+}
+func (gifCodec) Decode(data []byte) (image.Image, error) {
+	return GIFDecode(data)
+}
+func (gifCodec) DecodeAnimation(data []byte) (*Animation, error) {
+	return GIFDecodeAnimation(data)
+}
+
+func (bmpCodec) Decode(data []byte) (image.Image, error) {
+	return bmp.Decode(bytes.NewReader(data))
+}
+
+func (tiffCodec) Decode(data []byte) (image.Image, error) {
+	return TIFFDecodePage(data, 0)
+}
+func (tiffCodec) DecodePage(data []byte, page int) (image.Image, error) {
+	return TIFFDecodePage(data, page)
+}
+
+func init() {
+	builtinCodecs := []*Codec{
+		{Type: EncodeTypeGzip, Name: Gzip, Encoder: gzipCodec{}, Decoder: gzipCodec{}},
+		{Type: EncodeTypeBr, Name: Br, Encoder: brotliCodec{}, Decoder: brotliCodec{}},
+		// pb中的名称为snappy
+		{Type: EncodeTypeSnappy, Name: Snappy, Aliases: []string{"snappy"}, Encoder: snappyCodec{}, Decoder: snappyCodec{}},
+		{Type: EncodeTypeLz4, Name: Lz4, Encoder: lz4Codec{}, Decoder: lz4Codec{}},
+		{Type: EncodeTypeZstd, Name: Zstd, Encoder: zstdCodec{}, Decoder: zstdCodec{}},
+		{Type: EncodeTypeJPEG, Name: JPEG, Tool: EncoderCjpeg, Fallback: FallbackNative, ImageEncoder: jpegCodec{}, ImageDecoder: jpegCodec{}},
+		{Type: EncodeTypePNG, Name: PNG, Tool: EncoderPngquant, Fallback: FallbackNative, ImageEncoder: pngCodec{}, ImageDecoder: pngCodec{}},
+		{Type: EncodeTypeWEBP, Name: WEBP, ImageEncoder: webpCodec{}, ImageDecoder: webpCodec{}},
+		// avif的解码依赖avifdec
+		{Type: EncodeTypeAVIF, Name: AVIF, Tool: EncoderCavif, Fallback: FallbackWEBP, DecodeTool: DecoderAvifdec, ImageEncoder: avifCodec{}, ImageDecoder: avifCodec{}},
+		{Type: EncodeTypeGIF, Name: GIF, ImageEncoder: gifCodec{}, ImageDecoder: gifCodec{}},
+		// bmp与tiff仅支持解码
+		{Type: EncodeTypeBMP, Name: BMP, Aliases: []string{"x-ms-bmp"}, ImageDecoder: bmpCodec{}},
+		{Type: EncodeTypeTIFF, Name: TIFF, Aliases: []string{"tif"}, ImageDecoder: tiffCodec{}},
+	}
+	for _, codec := range builtinCodecs {
+		_, err := RegisterCodec(codec)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type base64Codec struct{}
+
+func (base64Codec) Encode(data []byte, opts *EncodeOptions) ([]byte, error) {
+	return []byte(base64.StdEncoding.EncodeToString(data)), nil
+}
+
+func (base64Codec) Decode(data []byte) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(string(data))
+}
+
+type bestPNGCodec struct{}
+
+func (bestPNGCodec) Encode(ctx context.Context, img image.Image, opts *EncodeOptions) (*Image, error) {
+	buffer := new(bytes.Buffer)
+	encoder := &png.Encoder{
+		CompressionLevel: png.BestCompression,
+	}
+	err := encoder.Encode(buffer, img)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{
+		Data: buffer.Bytes(),
+	}, nil
+}
+
+type contextPNGCodec struct{}
+
+func (c contextPNGCodec) Decode(data []byte) (image.Image, error) {
+	return c.DecodeContext(context.Background(), data)
+}
+
+func (contextPNGCodec) DecodeContext(ctx context.Context, data []byte) (image.Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return png.Decode(bytes.NewReader(data))
+}
+
+func TestRegisterCodec(t *testing.T) {
+	t.Run("invalid codec", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := RegisterCodec(&Codec{
+			Encoder: base64Codec{},
+		})
+		assert.Equal(errCodecNameIsNil, err)
+
+		_, err = RegisterCodec(&Codec{
+			Name: "base64",
+		})
+		assert.Equal(errCodecIsInvalid, err)
+
+		_, err = RegisterCodec(&Codec{
+			Name:         "base64",
+			Encoder:      base64Codec{},
+			ImageEncoder: bestPNGCodec{},
+		})
+		assert.Equal(errCodecIsInvalid, err)
+
+		_, err = RegisterCodec(&Codec{
+			Name:    Gzip,
+			Encoder: base64Codec{},
+		})
+		assert.Equal(errCodecNameIsExisted, err)
+	})
+
+	t.Run("text codec", func(t *testing.T) {
+		assert := assert.New(t)
+		encodeType, err := RegisterCodec(&Codec{
+			Name:    "base64",
+			Encoder: base64Codec{},
+			Decoder: base64Codec{},
+		})
+		assert.Nil(err)
+		assert.GreaterOrEqual(encodeType, minCustomEncodeType)
+		assert.Equal("base64", encodeType.String())
+		assert.Equal(encodeType, ConvertToEncodeType("base64"))
+		assert.True(IsTextType(encodeType))
+		assert.False(IsImageType(encodeType))
+
+		info, err := TextOptim([]byte("abcd"), encodeType, 0)
+		assert.Nil(err)
+		assert.Equal(encodeType, info.Type)
+		assert.Equal("YWJjZA==", string(info.Data))
+
+		// 转换为gzip
+		info, err = TextOptimWithOptions(info.Data, &TextOptimOptions{
+			Source: encodeType,
+			Output: EncodeTypeGzip,
+		})
+		assert.Nil(err)
+		data, err := GzipDecode(info.Data)
+		assert.Nil(err)
+		assert.Equal("abcd", string(data))
+
+		// 不支持流式处理
+		_, err = NewTextWriter(new(bytes.Buffer), &TextOptimOptions{
+			Output: encodeType,
+		})
+		assert.Equal(errStreamNotSupported, err)
+	})
+
+	t.Run("image codec", func(t *testing.T) {
+		assert := assert.New(t)
+		encodeType, err := RegisterCodec(&Codec{
+			Name:         "png-best",
+			ImageEncoder: bestPNGCodec{},
+		})
+		assert.Nil(err)
+		assert.True(IsImageType(encodeType))
+		assert.Equal(encodeType, DefaultImageOutput(encodeType))
+
+		img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+		img.Set(1, 1, color.White)
+		buffer := new(bytes.Buffer)
+		err = png.Encode(buffer, img)
+		assert.Nil(err)
+		info, err := ImageOptim(context.Background(), buffer.Bytes(), EncodeTypePNG, encodeType, CropNone, 0, 0, 0)
+		assert.Nil(err)
+		assert.Equal(encodeType, info.Type)
+		assert.Equal("png-best", info.Encoder)
+		_, err = png.Decode(bytes.NewReader(info.Data))
+		assert.Nil(err)
+	})
+
+	t.Run("context decoder", func(t *testing.T) {
+		assert := assert.New(t)
+		encodeType, err := RegisterCodec(&Codec{
+			Name:         "png-context",
+			ImageDecoder: contextPNGCodec{},
+		})
+		assert.Nil(err)
+
+		buffer := new(bytes.Buffer)
+		err = png.Encode(buffer, image.NewRGBA(image.Rect(0, 0, 10, 10)))
+		assert.Nil(err)
+		_, err = imageDecode(buffer.Bytes(), encodeType)
+		assert.Nil(err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = imageDecodeContext(ctx, buffer.Bytes(), encodeType)
+		assert.Equal(context.Canceled, err)
+	})
+
+	t.Run("replace codec", func(t *testing.T) {
+		assert := assert.New(t)
+		c, ok := GetCodec(EncodeTypeLz4)
+		assert.True(ok)
+		defer func() {
+			_, _ = RegisterCodec(c)
+		}()
+		encodeType, err := RegisterCodec(&Codec{
+			Type:    EncodeTypeLz4,
+			Name:    Lz4,
+			Encoder: base64Codec{},
+		})
+		assert.Nil(err)
+		assert.Equal(EncodeTypeLz4, encodeType)
+		info, err := TextOptim([]byte("abcd"), EncodeTypeLz4, 0)
+		assert.Nil(err)
+		assert.Equal("YWJjZA==", string(info.Data))
+	})
+}
+
+func TestDefaultImageOutput(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(EncodeTypeJPEG, DefaultImageOutput(EncodeTypeJPEG))
+	assert.Equal(EncodeTypeWEBP, DefaultImageOutput(EncodeTypeWEBP))
+	// 仅支持解码的类型
+	assert.Equal(EncodeTypePNG, DefaultImageOutput(EncodeTypeBMP))
+	assert.Equal(EncodeTypePNG, DefaultImageOutput(EncodeTypeTIFF))
+}
@@ -0,0 +1,368 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// 与zstd命令行训练的默认大小一致
+	defaultDictionarySize = 110 * 1024
+	dictionarySegmentSize = 64
+	dictionaryKmerSize    = 8
+	// 用户自定义字典的id范围，0-32767为zstd保留
+	minDictionaryID = 32768
+	maxDictionaryID = 1<<31 - 1
+	// 字典内容的最大长度，与字典数量共同限制内存与磁盘的占用
+	maxDictionarySize = 1024 * 1024
+	// 训练样本的最大总长度
+	maxDictionarySamplesSize = 32 * 1024 * 1024
+	// 默认最多保存的字典数量
+	defaultMaxDictionaries = 128
+	// zstd.BuildDict要求样本至少生成512个sequence，否则除零panic
+	minDictionarySequences = 512
+
+	dictionaryExt = ".dict"
+)
+
+var (
+	errDictionaryIDIsInvalid = errors.New("dictionary id is invalid")
+	errSamplesIsEmpty        = errors.New("samples can not be empty")
+	errTooFewSequences       = errors.New("the samples yield too few sequences")
+)
+
+var (
+	// ErrDictionaryNotFound the dictionary of id is not found
+	ErrDictionaryNotFound = errors.New("dictionary is not found")
+	// ErrDictionaryNotSupported the output type does not support dictionary
+	ErrDictionaryNotSupported = errors.New("dictionary is only supported by zstd and brotli")
+	// ErrDictionaryExists the dictionary of the same id exists
+	ErrDictionaryExists = errors.New("dictionary of the same id exists")
+	// ErrDictionaryLimitExceeded the count of dictionaries exceeds the limit
+	ErrDictionaryLimitExceeded = errors.New("the count of dictionaries exceeds the limit")
+	// ErrSamplesIsTooLarge the size of samples or dictionary exceeds the limit
+	ErrSamplesIsTooLarge = errors.New("the size of samples or dictionary exceeds the limit")
+	// ErrSamplesIsInvalid the dictionary can not be built from the samples
+	ErrSamplesIsInvalid = errors.New("can not build dictionary from the samples")
+)
+
+var dictionaries = struct {
+	sync.RWMutex
+	m     map[uint32][]byte
+	limit int
+}{
+	m:     make(map[uint32][]byte),
+	limit: defaultMaxDictionaries,
+}
+
+// SetDictionaryLimit set the max count of dictionaries,
+// the limit will be removed if count <= 0
+func SetDictionaryLimit(count int) {
+	dictionaries.Lock()
+	defer dictionaries.Unlock()
+	dictionaries.limit = count
+}
+
+// AddDictionary add the zstd dictionary to store, it returns the id of dictionary.
+// It returns ErrDictionaryExists if the dictionary of the same id exists,
+// ErrDictionaryLimitExceeded if the count exceeds the limit,
+// and ErrSamplesIsTooLarge if the content of dictionary exceeds 1MB.
+func AddDictionary(data []byte) (uint32, error) {
+	info, err := zstd.InspectDictionary(data)
+	if err != nil {
+		return 0, err
+	}
+	id := info.ID()
+	if id == 0 {
+		return 0, errDictionaryIDIsInvalid
+	}
+	if info.ContentSize() > maxDictionarySize {
+		return 0, ErrSamplesIsTooLarge
+	}
+	dictionaries.Lock()
+	defer dictionaries.Unlock()
+	if current, ok := dictionaries.m[id]; ok {
+		// 相同的字典则忽略
+		if bytes.Equal(current, data) {
+			return id, nil
+		}
+		return 0, ErrDictionaryExists
+	}
+	if dictionaries.limit > 0 && len(dictionaries.m) >= dictionaries.limit {
+		return 0, ErrDictionaryLimitExceeded
+	}
+	dictionaries.m[id] = data
+	return id, nil
+}
+
+// GetDictionary get the dictionary by id
+func GetDictionary(id uint32) ([]byte, error) {
+	dictionaries.RLock()
+	defer dictionaries.RUnlock()
+	data, ok := dictionaries.m[id]
+	if !ok {
+		return nil, ErrDictionaryNotFound
+	}
+	return data, nil
+}
+
+// getDictionaryContent get the content of dictionary,
+// it is used as the history of brotli
+func getDictionaryContent(id uint32) ([]byte, error) {
+	data, err := GetDictionary(id)
+	if err != nil {
+		return nil, err
+	}
+	info, err := zstd.InspectDictionary(data)
+	if err != nil {
+		return nil, err
+	}
+	return info.Content(), nil
+}
+
+// RemoveDictionary remove the dictionary from store
+func RemoveDictionary(id uint32) {
+	dictionaries.Lock()
+	defer dictionaries.Unlock()
+	delete(dictionaries.m, id)
+	removeBrotliPrefixes(id)
+}
+
+// listDictionaries list all dictionaries of store
+func listDictionaries() [][]byte {
+	dictionaries.RLock()
+	defer dictionaries.RUnlock()
+	result := make([][]byte, 0, len(dictionaries.m))
+	for _, data := range dictionaries.m {
+		result = append(result, data)
+	}
+	return result
+}
+
+// LoadDictionaries load the dictionaries(*.dict) of the path to store
+func LoadDictionaries(path string) ([]uint32, error) {
+	files, err := filepath.Glob(filepath.Join(path, "*"+dictionaryExt))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint32, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		id, err := AddDictionary(data)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// SaveDictionary save the dictionary of store to the path, the file name is id.dict
+func SaveDictionary(path string, id uint32) error {
+	data, err := GetDictionary(id)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(path, strconv.FormatUint(uint64(id), 10)+dictionaryExt)
+	return os.WriteFile(file, data, 0600)
+}
+
+// dictionarySegment the candidate segment of dictionary
+type dictionarySegment struct {
+	data  []byte
+	score int
+}
+
+type dictionarySegments []*dictionarySegment
+
+func (s dictionarySegments) Len() int            { return len(s) }
+func (s dictionarySegments) Less(i, j int) bool  { return s[i].score > s[j].score }
+func (s dictionarySegments) Swap(i, j int)       { s[i], s[j] = s[j], s[i] }
+func (s *dictionarySegments) Push(x interface{}) { *s = append(*s, x.(*dictionarySegment)) }
+func (s *dictionarySegments) Pop() interface{} {
+	old := *s
+	n := len(old)
+	item := old[n-1]
+	*s = old[:n-1]
+	return item
+}
+
+// kmerScore get the score of the segment, the kmer which has been
+// selected or only exists in one sample is ignored
+func kmerScore(data []byte, frequencies map[uint64]int) int {
+	score := 0
+	for i := 0; i+dictionaryKmerSize <= len(data); i++ {
+		count := frequencies[binary.LittleEndian.Uint64(data[i:])]
+		if count > 1 {
+			score += count
+		}
+	}
+	return score
+}
+
+// buildDictionaryHistory select the segments which are common in samples
+// as the content of dictionary
+func buildDictionaryHistory(samples [][]byte, size int) []byte {
+	// 统计各kmer出现在多少个样本中
+	frequencies := make(map[uint64]int)
+	for _, sample := range samples {
+		seen := make(map[uint64]struct{})
+		for i := 0; i+dictionaryKmerSize <= len(sample); i++ {
+			key := binary.LittleEndian.Uint64(sample[i:])
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			frequencies[key]++
+		}
+	}
+
+	segments := make(dictionarySegments, 0)
+	for _, sample := range samples {
+		for i := 0; i+dictionaryKmerSize <= len(sample); i += dictionarySegmentSize {
+			end := i + dictionarySegmentSize
+			if end > len(sample) {
+				end = len(sample)
+			}
+			data := sample[i:end]
+			score := kmerScore(data, frequencies)
+			if score != 0 {
+				segments = append(segments, &dictionarySegment{
+					data:  data,
+					score: score,
+				})
+			}
+		}
+	}
+	heap.Init(&segments)
+
+	selected := make([][]byte, 0)
+	total := 0
+	for segments.Len() != 0 && total < size {
+		item := heap.Pop(&segments).(*dictionarySegment)
+		// 已选择的kmer不再计分，分数变化则重新排序
+		score := kmerScore(item.data, frequencies)
+		if score == 0 {
+			continue
+		}
+		if score != item.score {
+			item.score = score
+			heap.Push(&segments, item)
+			continue
+		}
+		for i := 0; i+dictionaryKmerSize <= len(item.data); i++ {
+			delete(frequencies, binary.LittleEndian.Uint64(item.data[i:]))
+		}
+		selected = append(selected, item.data)
+		total += len(item.data)
+	}
+
+	// 越靠后的内容匹配的偏移越小，因此分数高的放在最后
+	history := make([]byte, 0, total)
+	for i := len(selected) - 1; i >= 0; i-- {
+		history = append(history, selected[i]...)
+	}
+	if len(history) > size {
+		history = history[len(history)-size:]
+	}
+	return history
+}
+
+// buildZstdDictionary build the zstd dictionary,
+// it returns errTooFewSequences instead of panic(divide by zero)
+func buildZstdDictionary(opts zstd.BuildDictOptions) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			data = nil
+			err = errTooFewSequences
+		}
+	}()
+	data, err = zstd.BuildDict(opts)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrSamplesIsInvalid, err.Error())
+	}
+	return
+}
+
+// TrainZstdDictionary train a zstd dictionary from the samples,
+// the size is the max size of dictionary content, 0 means 110KB.
+// The total size of samples should not exceed 32MB, and the size should not exceed 1MB.
+// It returns ErrSamplesIsInvalid if the dictionary can not be built from the samples.
+func TrainZstdDictionary(samples [][]byte, size int) ([]byte, error) {
+	if len(samples) == 0 {
+		return nil, errSamplesIsEmpty
+	}
+	if size <= 0 {
+		size = defaultDictionarySize
+	}
+	total := 0
+	for _, sample := range samples {
+		total += len(sample)
+	}
+	if total > maxDictionarySamplesSize || size > maxDictionarySize {
+		return nil, ErrSamplesIsTooLarge
+	}
+	history := buildDictionaryHistory(samples, size)
+	// 如果样本之间无相同的内容，则使用样本的数据
+	if len(history) < dictionaryKmerSize {
+		for _, sample := range samples {
+			history = append(history, sample...)
+		}
+		if len(history) > size {
+			history = history[len(history)-size:]
+		}
+	}
+	id := uint32(minDictionaryID + rand.Int63n(maxDictionaryID-minDictionaryID))
+	opts := zstd.BuildDictOptions{
+		ID:       id,
+		Contents: samples,
+		History:  history,
+		// zstd默认的repeat offsets
+		Offsets: [3]int{1, 4, 8},
+		// 默认的best compression每个样本都需要重置较大的hash表，
+		// 统计的结果与better相近但慢数十倍
+		Level: zstd.SpeedBetterCompression,
+	}
+	data, err := buildZstdDictionary(opts)
+	// 样本较少时重复样本以满足sequence的数量，各统计值的比例不变，
+	// 每次重复的次数翻倍，重复后的总长度也需要在限制内
+	for times := 2; err == errTooFewSequences; times *= 2 {
+		if times > minDictionarySequences || total*times > maxDictionarySamplesSize {
+			return nil, ErrSamplesIsInvalid
+		}
+		contents := make([][]byte, 0, len(samples)*times)
+		for i := 0; i < times; i++ {
+			contents = append(contents, samples...)
+		}
+		opts.Contents = contents
+		data, err = buildZstdDictionary(opts)
+	}
+	return data, err
+}
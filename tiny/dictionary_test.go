@@ -0,0 +1,259 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestSamples() [][]byte {
+	samples := make([][]byte, 0)
+	for i := 0; i < 200; i++ {
+		samples = append(samples, []byte(fmt.Sprintf(`{"id":%d,"name":"user-%d","email":"user-%d@example.com","status":"active","roles":["reader","writer"],"createdAt":"2024-01-%02dT10:00:00Z"}`, i, i, i, i%28+1)))
+	}
+	return samples
+}
+
+func TestDictionary(t *testing.T) {
+	assert := assert.New(t)
+	samples := getTestSamples()
+
+	_, err := TrainZstdDictionary(nil, 0)
+	assert.Equal(errSamplesIsEmpty, err)
+
+	dict, err := TrainZstdDictionary(samples, 4096)
+	assert.Nil(err)
+	id, err := AddDictionary(dict)
+	assert.Nil(err)
+	assert.True(id >= minDictionaryID)
+	defer RemoveDictionary(id)
+
+	data, err := GetDictionary(id)
+	assert.Nil(err)
+	assert.Equal(dict, data)
+
+	_, err = GetDictionary(1)
+	assert.Equal(ErrDictionaryNotFound, err)
+
+	_, err = AddDictionary([]byte("abcd"))
+	assert.NotNil(err)
+
+	doc := []byte(`{"id":1000,"name":"user-1000","email":"user-1000@example.com","status":"active","roles":["reader","writer"],"createdAt":"2024-02-01T10:00:00Z"}`)
+	info, err := TextOptimWithOptions(doc, &TextOptimOptions{
+		Output:     EncodeTypeZstd,
+		Dictionary: id,
+	})
+	assert.Nil(err)
+	assert.Equal(id, info.Dictionary)
+	withoutDict, err := ZstdEncode(doc, 0)
+	assert.Nil(err)
+	assert.True(len(info.Data) < len(withoutDict))
+
+	buf, err := ZstdDecode(info.Data)
+	assert.Nil(err)
+	assert.Equal(doc, buf)
+
+	// 转换时使用字典解压
+	result, err := TextOptimWithOptions(info.Data, &TextOptimOptions{
+		Source: EncodeTypeZstd,
+		Output: EncodeTypeGzip,
+	})
+	assert.Nil(err)
+	buf, err = GzipDecode(result.Data)
+	assert.Nil(err)
+	assert.Equal(doc, buf)
+
+	_, err = TextOptimWithOptions(doc, &TextOptimOptions{
+		Output:     EncodeTypeGzip,
+		Dictionary: id,
+	})
+	assert.Equal(ErrDictionaryNotSupported, err)
+
+	_, err = TextOptimWithOptions(doc, &TextOptimOptions{
+		Output:     EncodeTypeZstd,
+		Dictionary: 1,
+	})
+	assert.Equal(ErrDictionaryNotFound, err)
+}
+
+func TestSaveLoadDictionaries(t *testing.T) {
+	assert := assert.New(t)
+	dict, err := TrainZstdDictionary(getTestSamples(), 0)
+	assert.Nil(err)
+	id, err := AddDictionary(dict)
+	assert.Nil(err)
+
+	dir := t.TempDir()
+	err = SaveDictionary(dir, id)
+	assert.Nil(err)
+	RemoveDictionary(id)
+
+	ids, err := LoadDictionaries(dir)
+	assert.Nil(err)
+	assert.Equal([]uint32{id}, ids)
+	defer RemoveDictionary(id)
+	data, err := GetDictionary(id)
+	assert.Nil(err)
+	assert.Equal(dict, data)
+}
+
+func TestDictionaryLimit(t *testing.T) {
+	assert := assert.New(t)
+	samples := getTestSamples()
+	dict, err := TrainZstdDictionary(samples, 4096)
+	assert.Nil(err)
+	id, err := AddDictionary(dict)
+	assert.Nil(err)
+	defer RemoveDictionary(id)
+
+	// 相同的字典可重复添加，id相同但内容不同则出错
+	_, err = AddDictionary(dict)
+	assert.Nil(err)
+	other := append([]byte{}, dict...)
+	other[len(other)-1]++
+	_, err = AddDictionary(other)
+	assert.Equal(ErrDictionaryExists, err)
+
+	SetDictionaryLimit(1)
+	defer SetDictionaryLimit(defaultMaxDictionaries)
+	// 修改字典的id
+	binary.LittleEndian.PutUint32(other[4:], id+1)
+	_, err = AddDictionary(other)
+	assert.Equal(ErrDictionaryLimitExceeded, err)
+
+	_, err = TrainZstdDictionary(samples, maxDictionarySize+1)
+	assert.Equal(ErrSamplesIsTooLarge, err)
+	_, err = TrainZstdDictionary([][]byte{make([]byte, maxDictionarySamplesSize+1)}, 0)
+	assert.Equal(ErrSamplesIsTooLarge, err)
+}
+
+func TestTrainZstdDictionaryFewSamples(t *testing.T) {
+	assert := assert.New(t)
+	// 样本较少时sequence少于512，不会panic
+	samples := make([][]byte, 0)
+	for i := 0; i < 100; i++ {
+		samples = append(samples, []byte(`{"id":`+strconv.Itoa(i)+`,"name":"user","status":"active"}`))
+	}
+	dict, err := TrainZstdDictionary(samples, 0)
+	assert.Nil(err)
+	id, err := AddDictionary(dict)
+	assert.Nil(err)
+	defer RemoveDictionary(id)
+	doc := []byte(`{"id":1000,"name":"user","status":"active"}`)
+	info, err := TextOptimWithOptions(doc, &TextOptimOptions{
+		Output:     EncodeTypeZstd,
+		Dictionary: id,
+	})
+	assert.Nil(err)
+	buf, err := ZstdDecode(info.Data)
+	assert.Nil(err)
+	assert.Equal(doc, buf)
+
+	// 样本太短无法生成字典
+	_, err = TrainZstdDictionary([][]byte{[]byte(`{"id":1}`), []byte(`{"id":2}`)}, 0)
+	assert.ErrorIs(err, ErrSamplesIsInvalid)
+	_, err = TrainZstdDictionary([][]byte{[]byte("a"), []byte("b")}, 0)
+	assert.ErrorIs(err, ErrSamplesIsInvalid)
+}
+
+func TestAddDictionarySizeLimit(t *testing.T) {
+	assert := assert.New(t)
+	dict, err := TrainZstdDictionary(getTestSamples(), 4096)
+	assert.Nil(err)
+	// 字典的内容在最后，追加数据使内容超出限制
+	dict = append(dict, bytes.Repeat([]byte("a"), maxDictionarySize)...)
+	_, err = AddDictionary(dict)
+	assert.Equal(ErrSamplesIsTooLarge, err)
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "1.dict"), dict, 0600)
+	assert.Nil(err)
+	_, err = LoadDictionaries(dir)
+	assert.Equal(ErrSamplesIsTooLarge, err)
+}
+
+func TestBrotliDictionary(t *testing.T) {
+	assert := assert.New(t)
+	dict, err := TrainZstdDictionary(getTestSamples(), 4096)
+	assert.Nil(err)
+	id, err := AddDictionary(dict)
+	assert.Nil(err)
+	defer RemoveDictionary(id)
+
+	doc := []byte(`{"id":1000,"name":"user-1000","email":"user-1000@example.com","status":"active","roles":["reader","writer"],"createdAt":"2024-02-01T10:00:00Z"}`)
+	info, err := TextOptimWithOptions(doc, &TextOptimOptions{
+		Output:     EncodeTypeBr,
+		Quality:    11,
+		Dictionary: id,
+	})
+	assert.Nil(err)
+	assert.Equal(id, info.Dictionary)
+	assert.Equal(brotliDictionaryMagic, info.Data[:2])
+	withoutDict, err := BrotliEncode(doc, 11)
+	assert.Nil(err)
+	assert.Less(len(info.Data), len(withoutDict))
+
+	buf, err := BrotliDecode(info.Data)
+	assert.Nil(err)
+	assert.Equal(doc, buf)
+
+	// 无缓存时重新生成字典的压缩数据
+	removeBrotliPrefixes(id)
+	buf, err = BrotliDecode(info.Data)
+	assert.Nil(err)
+	assert.Equal(doc, buf)
+
+	// 流式压缩与解压
+	buffer := new(bytes.Buffer)
+	w, err := NewTextWriter(buffer, &TextOptimOptions{
+		Output:     EncodeTypeBr,
+		Dictionary: id,
+	})
+	assert.Nil(err)
+	_, err = w.Write(doc)
+	assert.Nil(err)
+	assert.Nil(w.Close())
+	r, err := NewTextReader(buffer, EncodeTypeBr)
+	assert.Nil(err)
+	buf, err = io.ReadAll(r)
+	assert.Nil(err)
+	assert.Equal(doc, buf)
+
+	// 自动选择时也可使用
+	info, err = TextOptimWithOptions(doc, &TextOptimOptions{
+		Output:     EncodeTypeAuto,
+		Candidates: []EncodeType{EncodeTypeGzip, EncodeTypeBr},
+		Dictionary: id,
+	})
+	assert.Nil(err)
+	assert.Equal(EncodeTypeBr, info.Type)
+	assert.Equal(id, info.Dictionary)
+
+	// 未添加的字典无法解压
+	RemoveDictionary(id)
+	_, err = BrotliDecode(info.Data)
+	assert.Equal(ErrDictionaryNotFound, err)
+	_, err = BrotliDecode(append([]byte{}, brotliDictionaryMagic...))
+	assert.Equal(errBrotliDictionaryHeaderIsInvalid, err)
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// stderr只保留前4KB
+	maxToolStderrSize = 4 * 1024
+	// pngquant无法满足最低质量时的退出码
+	pngquantQualityTooLowExitCode = 99
+)
+
+// ToolError the error of external tool
+type ToolError struct {
+	// Tool the name of tool
+	Tool string
+	// Args the args of tool
+	Args []string
+	// ExitCode the exit code of tool, -1 means the tool is not exited normally(e.g. not found or killed)
+	ExitCode int
+	// Stderr the output of stderr
+	Stderr string
+	// Err the original error
+	Err error
+}
+
+// newToolError create a tool error from the error of command
+func newToolError(args []string, err error, stderr []byte) *ToolError {
+	if len(stderr) > maxToolStderrSize {
+		stderr = stderr[:maxToolStderrSize]
+	}
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+	return &ToolError{
+		Tool:     args[0],
+		Args:     args[1:],
+		ExitCode: exitCode,
+		Stderr:   strings.TrimSpace(string(stderr)),
+		Err:      err,
+	}
+}
+
+func (e *ToolError) Error() string {
+	msg := fmt.Sprintf("%s(exit code: %d): %s", e.Tool, e.ExitCode, e.Err.Error())
+	if e.Stderr != "" {
+		msg += ", " + e.Stderr
+	}
+	return msg
+}
+
+// Unwrap return the original error
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// IsQualityTooLow check the error is caused by pngquant could not meet the quality
+func IsQualityTooLow(err error) bool {
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) {
+		return false
+	}
+	return toolErr.Tool == EncoderPngquant && toolErr.ExitCode == pngquantQualityTooLowExitCode
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToolError(t *testing.T) {
+	assert := assert.New(t)
+	args := []string{"sh", "-c", "echo quality too low >&2; exit 99"}
+	err := doCommandPipe(context.Background(), nil, args, new(bytes.Buffer))
+	toolErr := &ToolError{}
+	assert.True(errors.As(err, &toolErr))
+	assert.Equal("sh", toolErr.Tool)
+	assert.Equal(args[1:], toolErr.Args)
+	assert.Equal(99, toolErr.ExitCode)
+	assert.Equal("quality too low", toolErr.Stderr)
+	assert.Equal("sh(exit code: 99): exit status 99, quality too low", err.Error())
+	// 仅pngquant的99为质量过低
+	assert.False(IsQualityTooLow(err))
+	toolErr.Tool = EncoderPngquant
+	assert.True(IsQualityTooLow(err))
+
+	err = doCommandPipe(context.Background(), nil, []string{"tiny-not-found"}, new(bytes.Buffer))
+	assert.True(errors.As(err, &toolErr))
+	assert.Equal(-1, toolErr.ExitCode)
+	assert.True(isToolNotFound(err))
+}
+
+func TestImageOptimQualityTooLow(t *testing.T) {
+	// 模拟pngquant无法满足质量要求
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, EncoderPngquant), []byte("#!/bin/sh\nexit 99\n"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	img.Set(1, 1, color.White)
+	buffer := new(bytes.Buffer)
+	err = png.Encode(buffer, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buffer.Bytes()
+
+	t.Run("original", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := PNGEncode(context.Background(), img, 80)
+		assert.True(IsQualityTooLow(err))
+
+		info, err := ImageOptim(context.Background(), data, EncodeTypePNG, EncodeTypePNG, CropNone, 80, 0, 0)
+		assert.Nil(err)
+		assert.Equal(EncoderOriginal, info.Encoder)
+		assert.Equal(data, info.Data)
+	})
+
+	t.Run("lossless", func(t *testing.T) {
+		assert := assert.New(t)
+		info, err := ImageOptim(context.Background(), data, EncodeTypePNG, EncodeTypePNG, CropNone, 80, 5, 5)
+		assert.Nil(err)
+		assert.Equal(EncoderGoPNG, info.Encoder)
+		assert.Equal(5, info.Width)
+	})
+}
@@ -0,0 +1,221 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"encoding/binary"
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	exifOrientationTag = 0x0112
+	exifArtistTag      = 0x013b
+	exifCopyrightTag   = 0x8298
+
+	exifTypeASCII     = 2
+	exifTypeShort     = 3
+	exifTypeUndefined = 7
+
+	// OrientationNormal the orientation of image is normal
+	OrientationNormal = 1
+)
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// exifEntry the entry of exif ifd
+type exifEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// getExifByteOrder get the byte order of exif(tiff) data
+func getExifByteOrder(exif []byte) binary.ByteOrder {
+	if len(exif) < 8 {
+		return nil
+	}
+	switch string(exif[0:2]) {
+	case "II":
+		return binary.LittleEndian
+	case "MM":
+		return binary.BigEndian
+	default:
+		return nil
+	}
+}
+
+// findExifEntry find the entry of ifd0, it returns the offset of entry or -1 if not found
+func findExifEntry(exif []byte, tag uint16) (int, binary.ByteOrder) {
+	order := getExifByteOrder(exif)
+	if order == nil {
+		return -1, nil
+	}
+	ifdOffset := int(order.Uint32(exif[4:]))
+	if ifdOffset < 0 || ifdOffset+2 > len(exif) {
+		return -1, nil
+	}
+	count := int(order.Uint16(exif[ifdOffset:]))
+	for i := 0; i < count; i++ {
+		entry := ifdOffset + 2 + i*12
+		if entry+12 > len(exif) {
+			return -1, nil
+		}
+		if order.Uint16(exif[entry:]) == tag {
+			return entry, order
+		}
+	}
+	return -1, nil
+}
+
+// readExifOrientation read the orientation from exif(tiff) data, it returns 0 if not found
+func readExifOrientation(exif []byte) int {
+	entry, order := findExifEntry(exif, exifOrientationTag)
+	if entry < 0 {
+		return 0
+	}
+	// 类型为SHORT，数据保存在value的前两个字节
+	v := int(order.Uint16(exif[entry+8:]))
+	if v < 1 || v > 8 {
+		return 0
+	}
+	return v
+}
+
+// setExifOrientation set the orientation of exif(tiff) data, it returns a new exif data
+func setExifOrientation(exif []byte, orientation int) []byte {
+	entry, order := findExifEntry(exif, exifOrientationTag)
+	if entry < 0 {
+		return exif
+	}
+	result := append([]byte{}, exif...)
+	order.PutUint16(result[entry+8:], uint16(orientation))
+	return result
+}
+
+// readExifASCII read the ascii value of ifd0
+func readExifASCII(exif []byte, tag uint16) []byte {
+	return readExifBytes(exif, tag, exifTypeASCII)
+}
+
+// readExifBytes read the value of ifd0 whose type is byte based(ascii, undefined)
+func readExifBytes(exif []byte, tag, typ uint16) []byte {
+	entry, order := findExifEntry(exif, tag)
+	if entry < 0 || order.Uint16(exif[entry+2:]) != typ {
+		return nil
+	}
+	count := int(order.Uint32(exif[entry+4:]))
+	// 不超过4字节则保存在value中，否则为数据的偏移
+	offset := entry + 8
+	if count > 4 {
+		offset = int(order.Uint32(exif[entry+8:]))
+	}
+	if count <= 0 || offset < 0 || offset+count > len(exif) {
+		return nil
+	}
+	return exif[offset : offset+count]
+}
+
+// newExif create the exif(tiff) data with the entries of ifd0
+func newExif(entries []*exifEntry) []byte {
+	order := binary.BigEndian
+	header := make([]byte, 8)
+	copy(header, "MM")
+	order.PutUint16(header[2:], 42)
+	order.PutUint32(header[4:], 8)
+	// ifd之后为超过4字节的数据
+	dataOffset := 8 + 2 + len(entries)*12 + 4
+	ifd := make([]byte, 2, dataOffset-8)
+	order.PutUint16(ifd, uint16(len(entries)))
+	values := make([]byte, 0)
+	for _, e := range entries {
+		buf := make([]byte, 12)
+		order.PutUint16(buf, e.tag)
+		order.PutUint16(buf[2:], e.typ)
+		order.PutUint32(buf[4:], e.count)
+		if len(e.value) <= 4 {
+			copy(buf[8:], e.value)
+		} else {
+			order.PutUint32(buf[8:], uint32(dataOffset+len(values)))
+			values = append(values, e.value...)
+			// 数据偏移需要为偶数
+			if len(values)%2 != 0 {
+				values = append(values, 0)
+			}
+		}
+		ifd = append(ifd, buf...)
+	}
+	// 无下一个ifd
+	ifd = append(ifd, 0, 0, 0, 0)
+	result := append(header, ifd...)
+	return append(result, values...)
+}
+
+// newCopyrightExif create the exif data which only has the artist and copyright,
+// it returns nil if both of them are not found
+func newCopyrightExif(exif []byte) []byte {
+	entries := make([]*exifEntry, 0, 2)
+	// ifd中的tag需要按升序排列
+	for _, tag := range []uint16{exifArtistTag, exifCopyrightTag} {
+		value := readExifASCII(exif, tag)
+		if len(value) == 0 {
+			continue
+		}
+		entries = append(entries, &exifEntry{
+			tag:   tag,
+			typ:   exifTypeASCII,
+			count: uint32(len(value)),
+			value: value,
+		})
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return newExif(entries)
+}
+
+// getExif get the exif data of image
+func getExif(data []byte, t EncodeType) []byte {
+	return readMetadata(data, t).Exif
+}
+
+// GetOrientation get the exif orientation of image, it returns 0 if not found
+func GetOrientation(data []byte, t EncodeType) int {
+	return readExifOrientation(getExif(data, t))
+}
+
+// ImageOrient transform the image to normal orientation by exif orientation
+func ImageOrient(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	default:
+		return img
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestExif create the exif(tiff) data with orientation
+func newTestExif(orientation int) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("MM")
+	_ = binary.Write(buf, binary.BigEndian, uint16(42))
+	_ = binary.Write(buf, binary.BigEndian, uint32(8))
+	// ifd0: count + entry + next ifd
+	_ = binary.Write(buf, binary.BigEndian, uint16(1))
+	_ = binary.Write(buf, binary.BigEndian, uint16(exifOrientationTag))
+	_ = binary.Write(buf, binary.BigEndian, uint16(3))
+	_ = binary.Write(buf, binary.BigEndian, uint32(1))
+	_ = binary.Write(buf, binary.BigEndian, uint16(orientation))
+	_ = binary.Write(buf, binary.BigEndian, uint16(0))
+	_ = binary.Write(buf, binary.BigEndian, uint32(0))
+	return buf.Bytes()
+}
+
+// newTestJPEGWithOrientation create the jpeg with exif orientation
+func newTestJPEGWithOrientation(orientation int) []byte {
+	buf := &bytes.Buffer{}
+	_ = jpeg.Encode(buf, getTestImage(), nil)
+	data := buf.Bytes()
+	segment := append(append([]byte{}, exifHeader...), newTestExif(orientation)...)
+	app1 := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	result := append([]byte{}, data[:2]...)
+	result = append(result, app1...)
+	result = append(result, segment...)
+	return append(result, data[2:]...)
+}
+
+func TestGetOrientation(t *testing.T) {
+	assert := assert.New(t)
+	data := newTestJPEGWithOrientation(6)
+	assert.Equal(6, GetOrientation(data, EncodeTypeJPEG))
+	// 未指定类型时根据数据判断
+	assert.Equal(6, GetOrientation(data, EncodeTypeUnknown))
+
+	assert.Equal(0, GetOrientation([]byte("abcd"), EncodeTypeJPEG))
+	assert.Equal(0, readExifOrientation(newTestExif(9)))
+	assert.Equal(3, readExifOrientation(newTestExif(3)))
+}
+
+func TestImageOrient(t *testing.T) {
+	assert := assert.New(t)
+	img := getTestImage()
+	for _, orientation := range []int{0, 1, 2, 3, 4} {
+		result := ImageOrient(img, orientation)
+		assert.Equal(pngWidth, result.Bounds().Dx())
+		assert.Equal(pngHeight, result.Bounds().Dy())
+	}
+	for _, orientation := range []int{5, 6, 7, 8} {
+		result := ImageOrient(img, orientation)
+		assert.Equal(pngHeight, result.Bounds().Dx())
+		assert.Equal(pngWidth, result.Bounds().Dy())
+	}
+}
+
+func TestImageOptimAutoOrient(t *testing.T) {
+	data := newTestJPEGWithOrientation(6)
+	t.Run("auto orient", func(t *testing.T) {
+		assert := assert.New(t)
+		img, err := ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+			Source: EncodeTypeJPEG,
+			Output: EncodeTypePNG,
+		})
+		assert.Nil(err)
+		assert.Equal(pngHeight, img.Width)
+		assert.Equal(pngWidth, img.Height)
+	})
+
+	t.Run("orient before resize", func(t *testing.T) {
+		assert := assert.New(t)
+		img, err := ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+			Source: EncodeTypeJPEG,
+			Output: EncodeTypeJPEG,
+			Width:  20,
+		})
+		assert.Nil(err)
+		assert.Equal(20, img.Width)
+		assert.Equal(40, img.Height)
+	})
+
+	t.Run("disable auto orient", func(t *testing.T) {
+		assert := assert.New(t)
+		img, err := ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+			Source:            EncodeTypeJPEG,
+			Output:            EncodeTypePNG,
+			DisableAutoOrient: true,
+		})
+		assert.Nil(err)
+		assert.Equal(pngWidth, img.Width)
+		assert.Equal(pngHeight, img.Height)
+	})
+}
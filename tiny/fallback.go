@@ -0,0 +1,101 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"errors"
+	"os/exec"
+	"sync/atomic"
+)
+
+// FallbackPolicy the policy of image encode when the external tool is not found
+type FallbackPolicy int32
+
+const (
+	// FallbackNone return the error of external tool
+	FallbackNone FallbackPolicy = iota
+	// FallbackNative encode jpeg and png with the encoder of go,
+	// avif is not supported
+	FallbackNative
+	// FallbackWEBP same as FallbackNative, and avif will be encoded to webp
+	FallbackWEBP
+)
+
+const (
+	// EncoderCjpeg cjpeg of mozjpeg
+	EncoderCjpeg = "cjpeg"
+	// EncoderPngquant pngquant
+	EncoderPngquant = "pngquant"
+	// EncoderCavif cavif
+	EncoderCavif = "cavif"
+	// DecoderAvifdec avifdec of libavif
+	DecoderAvifdec = "avifdec"
+	// EncoderLibwebp libwebp
+	EncoderLibwebp = "libwebp"
+	// EncoderGoJPEG image/jpeg of go
+	EncoderGoJPEG = "image/jpeg"
+	// EncoderGoPNG image/png of go
+	EncoderGoPNG = "image/png"
+	// EncoderGoGIF image/gif of go
+	EncoderGoGIF = "image/gif"
+	// EncoderOriginal the original image is returned
+	EncoderOriginal = "original"
+)
+
+// 默认使用go的编码器兜底
+var fallbackPolicy = int32(FallbackNative)
+
+func (p FallbackPolicy) String() string {
+	switch p {
+	case FallbackNone:
+		return "none"
+	case FallbackWEBP:
+		return "webp"
+	default:
+		return "native"
+	}
+}
+
+// ConvertToFallbackPolicy convert to fallback policy, native is returned for unknown value
+func ConvertToFallbackPolicy(v string) FallbackPolicy {
+	switch v {
+	case "none":
+		return FallbackNone
+	case "webp":
+		return FallbackWEBP
+	default:
+		return FallbackNative
+	}
+}
+
+// SetFallbackPolicy set the fallback policy of image encode
+func SetFallbackPolicy(p FallbackPolicy) {
+	atomic.StoreInt32(&fallbackPolicy, int32(p))
+}
+
+// GetFallbackPolicy get the fallback policy of image encode
+func GetFallbackPolicy() FallbackPolicy {
+	return FallbackPolicy(atomic.LoadInt32(&fallbackPolicy))
+}
+
+// isToolNotFound check the error is caused by the external tool is not found
+func isToolNotFound(err error) bool {
+	return errors.Is(err, exec.ErrNotFound)
+}
+
+// shouldFallback check the encode should fallback to native encoder
+func shouldFallback(err error) bool {
+	return isToolNotFound(err) && GetFallbackPolicy() != FallbackNone
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFallbackPolicy(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(FallbackNone, ConvertToFallbackPolicy("none"))
+	assert.Equal(FallbackWEBP, ConvertToFallbackPolicy("webp"))
+	assert.Equal(FallbackNative, ConvertToFallbackPolicy(""))
+	assert.Equal("webp", FallbackWEBP.String())
+
+	defer SetFallbackPolicy(GetFallbackPolicy())
+	SetFallbackPolicy(FallbackNone)
+	assert.Equal(FallbackNone, GetFallbackPolicy())
+}
+
+func TestImageFallback(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	img.Set(1, 1, color.White)
+	ctx := context.Background()
+	defer SetFallbackPolicy(GetFallbackPolicy())
+
+	t.Run("jpeg", func(t *testing.T) {
+		if _, err := exec.LookPath(EncoderCjpeg); err == nil {
+			t.Skip("cjpeg is installed")
+		}
+		assert := assert.New(t)
+		SetFallbackPolicy(FallbackNone)
+		_, err := jpegEncode(ctx, img, 80)
+		assert.True(isToolNotFound(err))
+
+		SetFallbackPolicy(FallbackNative)
+		info, err := jpegEncode(ctx, img, 80)
+		assert.Nil(err)
+		assert.Equal(EncodeTypeJPEG, info.Type)
+		assert.Equal(EncoderGoJPEG, info.Encoder)
+	})
+
+	t.Run("png", func(t *testing.T) {
+		if _, err := exec.LookPath(EncoderPngquant); err == nil {
+			t.Skip("pngquant is installed")
+		}
+		assert := assert.New(t)
+		SetFallbackPolicy(FallbackNone)
+		_, err := pngEncode(ctx, img, 80)
+		assert.True(isToolNotFound(err))
+
+		SetFallbackPolicy(FallbackNative)
+		info, err := pngEncode(ctx, img, 80)
+		assert.Nil(err)
+		assert.Equal(EncodeTypePNG, info.Type)
+		assert.Equal(EncoderGoPNG, info.Encoder)
+	})
+
+	t.Run("avif", func(t *testing.T) {
+		if _, err := exec.LookPath(EncoderCavif); err == nil {
+			t.Skip("cavif is installed")
+		}
+		assert := assert.New(t)
+		SetFallbackPolicy(FallbackNative)
+		_, err := avifEncode(ctx, img, 80)
+		assert.True(isToolNotFound(err))
+
+		SetFallbackPolicy(FallbackWEBP)
+		info, err := avifEncode(ctx, img, 80)
+		assert.Nil(err)
+		assert.Equal(EncodeTypeWEBP, info.Type)
+		assert.Equal(EncoderLibwebp, info.Encoder)
+	})
+}
@@ -0,0 +1,412 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"sort"
+	"sync/atomic"
+
+	"github.com/disintegration/imaging"
+)
+
+const maxGIFColors = 256
+
+const (
+	// 默认画布最大为5000万像素(NRGBA为200MB)
+	defaultGIFMaxPixels = 50 * 1000 * 1000
+	// 默认所有帧的像素总和最大为2亿(NRGBA为800MB)
+	defaultGIFMaxAnimationPixels = 200 * 1000 * 1000
+)
+
+// ErrImageIsTooLarge the pixels of image exceed the limit
+var ErrImageIsTooLarge = errors.New("the pixels of image exceed the limit")
+
+var (
+	gifMaxPixels          int64 = defaultGIFMaxPixels
+	gifMaxAnimationPixels int64 = defaultGIFMaxAnimationPixels
+)
+
+// SetGIFDecodeLimit set the max pixels of canvas and the max pixels of all frames,
+// the width and height of gif are untrusted, so the limit is checked before
+// the canvas is allocated. The max pixels also limits the image enlarged by
+// operations(rotate). The default limit is used if the value <= 0.
+func SetGIFDecodeLimit(maxPixels, maxAnimationPixels int64) {
+	if maxPixels <= 0 {
+		maxPixels = defaultGIFMaxPixels
+	}
+	if maxAnimationPixels <= 0 {
+		maxAnimationPixels = defaultGIFMaxAnimationPixels
+	}
+	atomic.StoreInt64(&gifMaxPixels, maxPixels)
+	atomic.StoreInt64(&gifMaxAnimationPixels, maxAnimationPixels)
+}
+
+// checkGIFPixels check the pixels of canvas and all frames
+func checkGIFPixels(width, height, frames int) error {
+	pixels := int64(width) * int64(height)
+	if pixels > atomic.LoadInt64(&gifMaxPixels) {
+		return ErrImageIsTooLarge
+	}
+	if pixels*int64(frames) > atomic.LoadInt64(&gifMaxAnimationPixels) {
+		return ErrImageIsTooLarge
+	}
+	return nil
+}
+
+// colorCount the color and the count of pixels
+type colorCount struct {
+	rgb   [3]uint8
+	count int
+}
+
+// colorBox the box of median cut
+type colorBox []*colorCount
+
+// channelRange get the channel which has the largest range
+func (b colorBox) channelRange() (channel int, size int) {
+	for i := 0; i < 3; i++ {
+		min, max := uint8(255), uint8(0)
+		for _, c := range b {
+			if c.rgb[i] < min {
+				min = c.rgb[i]
+			}
+			if c.rgb[i] > max {
+				max = c.rgb[i]
+			}
+		}
+		if int(max)-int(min) > size {
+			channel = i
+			size = int(max) - int(min)
+		}
+	}
+	return
+}
+
+// split split the box by the weighted median of the largest channel
+func (b colorBox) split() (colorBox, colorBox) {
+	channel, _ := b.channelRange()
+	sort.Slice(b, func(i, j int) bool {
+		return b[i].rgb[channel] < b[j].rgb[channel]
+	})
+	total := 0
+	for _, c := range b {
+		total += c.count
+	}
+	sum := 0
+	index := 1
+	for i, c := range b[:len(b)-1] {
+		sum += c.count
+		index = i + 1
+		if sum*2 >= total {
+			break
+		}
+	}
+	return b[:index], b[index:]
+}
+
+// average get the weighted average color of box
+func (b colorBox) average() color.Color {
+	var r, g, bl, total int
+	for _, c := range b {
+		r += int(c.rgb[0]) * c.count
+		g += int(c.rgb[1]) * c.count
+		bl += int(c.rgb[2]) * c.count
+		total += c.count
+	}
+	return color.RGBA{
+		R: uint8((r + total/2) / total),
+		G: uint8((g + total/2) / total),
+		B: uint8((bl + total/2) / total),
+		A: 255,
+	}
+}
+
+// getGIFColors get the palette size of quality, 0 means the max colors
+func getGIFColors(quality int) int {
+	if quality <= 0 || quality >= 100 {
+		return maxGIFColors
+	}
+	colors := quality * maxGIFColors / 100
+	if colors < 2 {
+		colors = 2
+	}
+	return colors
+}
+
+// ImageQuantize quantize the image to palette image by median cut,
+// the alpha of gif is binary, so the pixels whose alpha less than 128 are transparent
+func ImageQuantize(img image.Image, colors int, dither bool) *image.Paletted {
+	if colors <= 0 || colors > maxGIFColors {
+		colors = maxGIFColors
+	}
+	src := imaging.Clone(img)
+	hist := make(map[[3]uint8]*colorCount)
+	hasTransparent := false
+	for i := 0; i+3 < len(src.Pix); i += 4 {
+		if src.Pix[i+3] < 128 {
+			hasTransparent = true
+			src.Pix[i], src.Pix[i+1], src.Pix[i+2], src.Pix[i+3] = 0, 0, 0, 0
+			continue
+		}
+		src.Pix[i+3] = 255
+		rgb := [3]uint8{src.Pix[i], src.Pix[i+1], src.Pix[i+2]}
+		c, ok := hist[rgb]
+		if !ok {
+			c = &colorCount{
+				rgb: rgb,
+			}
+			hist[rgb] = c
+		}
+		c.count++
+	}
+	palette := make(color.Palette, 0, colors)
+	if hasTransparent {
+		palette = append(palette, color.Transparent)
+		colors--
+	}
+	all := make(colorBox, 0, len(hist))
+	for _, c := range hist {
+		all = append(all, c)
+	}
+	// 保证结果一致
+	sort.Slice(all, func(i, j int) bool {
+		a, b := all[i].rgb, all[j].rgb
+		return a[0] < b[0] || (a[0] == b[0] && (a[1] < b[1] || (a[1] == b[1] && a[2] < b[2])))
+	})
+
+	if len(all) <= colors {
+		// 颜色数量不超过调色板则直接使用
+		for _, c := range all {
+			palette = append(palette, color.RGBA{c.rgb[0], c.rgb[1], c.rgb[2], 255})
+		}
+	} else {
+		boxes := []colorBox{all}
+		for len(boxes) < colors {
+			// 选择颜色范围最大的box拆分
+			index := -1
+			maxSize := 0
+			for i, b := range boxes {
+				if len(b) < 2 {
+					continue
+				}
+				if _, size := b.channelRange(); size > maxSize {
+					index = i
+					maxSize = size
+				}
+			}
+			if index < 0 {
+				break
+			}
+			left, right := boxes[index].split()
+			boxes[index] = left
+			boxes = append(boxes, right)
+		}
+		for _, b := range boxes {
+			palette = append(palette, b.average())
+		}
+	}
+	if len(palette) == 0 {
+		palette = append(palette, color.Black)
+	}
+
+	dst := image.NewPaletted(src.Bounds(), palette)
+	if dither {
+		draw.FloydSteinberg.Draw(dst, dst.Bounds(), src, src.Bounds().Min)
+	} else {
+		draw.Draw(dst, dst.Bounds(), src, src.Bounds().Min, draw.Src)
+	}
+	return dst
+}
+
+// GIFEncode quantize the image and encode to gif
+func GIFEncode(img image.Image, quality int, dither bool) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := gif.Encode(buf, ImageQuantize(img, getGIFColors(quality), dither), nil)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GIFEncodeAnimation encode the animation to animated gif,
+// each frame has its own palette
+func GIFEncodeAnimation(anim *Animation, quality int, dither bool) ([]byte, error) {
+	g := &gif.GIF{
+		Image:    make([]*image.Paletted, 0, len(anim.Frames)),
+		Delay:    make([]int, 0, len(anim.Frames)),
+		Disposal: make([]byte, 0, len(anim.Frames)),
+	}
+	// 与解码时的转换相反
+	switch {
+	case anim.LoopCount == 1:
+		g.LoopCount = -1
+	case anim.LoopCount > 1:
+		g.LoopCount = anim.LoopCount - 1
+	}
+	colors := getGIFColors(quality)
+	for i, frame := range anim.Frames {
+		g.Image = append(g.Image, ImageQuantize(frame, colors, dither))
+		delay := 0
+		if i < len(anim.Delays) {
+			delay = anim.Delays[i] / 10
+		}
+		g.Delay = append(g.Delay, delay)
+		// 每帧均为完整的画布，需要清除上一帧（透明区域）
+		g.Disposal = append(g.Disposal, gif.DisposalBackground)
+	}
+	buf := &bytes.Buffer{}
+	err := gif.EncodeAll(buf, g)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// skipGIFSubBlocks skip the data sub-blocks, returns -1 if the data is truncated
+func skipGIFSubBlocks(data []byte, offset int) int {
+	for offset < len(data) {
+		size := int(data[offset])
+		offset++
+		if size == 0 {
+			return offset
+		}
+		offset += size
+	}
+	return -1
+}
+
+// countGIFFrames count the image descriptors of gif without decoding the frames,
+// the truncated data returns the count of frames which have been found
+func countGIFFrames(data []byte) int {
+	// header(6) + logical screen descriptor(7)
+	offset := 13
+	if len(data) < offset {
+		return 0
+	}
+	// 全局调色板
+	if data[10]&0x80 != 0 {
+		offset += 3 << (data[10]&0x07 + 1)
+	}
+	frames := 0
+	for offset > 0 && offset < len(data) {
+		switch data[offset] {
+		case 0x21:
+			// extension: introducer + label + sub-blocks
+			offset = skipGIFSubBlocks(data, offset+2)
+		case 0x2C:
+			frames++
+			// image descriptor(10) + local color table + lzw min code size + sub-blocks
+			if offset+10 > len(data) {
+				return frames
+			}
+			flags := data[offset+9]
+			offset += 10
+			if flags&0x80 != 0 {
+				offset += 3 << (flags&0x07 + 1)
+			}
+			offset = skipGIFSubBlocks(data, offset+1)
+		default:
+			// trailer或无效数据
+			return frames
+		}
+	}
+	return frames
+}
+
+// GIFDecode decode the first frame of gif
+func GIFDecode(data []byte) (image.Image, error) {
+	config, err := gif.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	err = checkGIFPixels(config.Width, config.Height, 1)
+	if err != nil {
+		return nil, err
+	}
+	frame, err := gif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	// 帧可能小于画布，需要绘制至画布中
+	canvas := image.NewNRGBA(image.Rect(0, 0, config.Width, config.Height))
+	draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+	return canvas, nil
+}
+
+// GIFDecodeAnimation decode all frames of gif, each frame is composed to a full canvas
+func GIFDecodeAnimation(data []byte) (*Animation, error) {
+	config, err := gif.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	// 解码时会分配所有帧，因此先统计帧数再检查
+	// 每帧均生成完整的画布
+	err = checkGIFPixels(config.Width, config.Height, countGIFFrames(data))
+	if err != nil {
+		return nil, err
+	}
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	anim := &Animation{
+		Frames: make([]image.Image, 0, len(g.Image)),
+		Delays: make([]int, 0, len(g.Image)),
+	}
+	// gif的0为无限循环，-1为只播放一次，其它则为重复次数
+	switch {
+	case g.LoopCount < 0:
+		anim.LoopCount = 1
+	case g.LoopCount > 0:
+		anim.LoopCount = g.LoopCount + 1
+	}
+	canvas := image.NewNRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+	for i, frame := range g.Image {
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		var previous *image.NRGBA
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewNRGBA(canvas.Rect)
+			copy(previous.Pix, canvas.Pix)
+		}
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		current := image.NewNRGBA(canvas.Rect)
+		copy(current.Pix, canvas.Pix)
+		anim.Frames = append(anim.Frames, current)
+		delay := 0
+		if i < len(g.Delay) {
+			// gif的延时单位为1/100秒
+			delay = g.Delay[i] * 10
+		}
+		anim.Delays = append(anim.Delays, delay)
+
+		// 根据处置方式处理画布，用于下一帧的绘制
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return anim, nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testGIFColors = []color.Color{
+	color.RGBA{255, 0, 0, 255},
+	color.RGBA{0, 255, 0, 255},
+	color.RGBA{0, 0, 255, 255},
+}
+
+// newTestGIF create the animated gif, each frame is filled with a color
+func newTestGIF(loopCount int) []byte {
+	palette := append([]color.Color{color.Transparent}, testGIFColors...)
+	g := &gif.GIF{
+		LoopCount: loopCount,
+	}
+	for i := range testGIFColors {
+		frame := image.NewPaletted(image.Rect(0, 0, 40, 20), palette)
+		for j := range frame.Pix {
+			frame.Pix[j] = uint8(i + 1)
+		}
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, (i+1)*10)
+	}
+	buf := &bytes.Buffer{}
+	err := gif.EncodeAll(buf, g)
+	if err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGIFDecodeAnimation(t *testing.T) {
+	assert := assert.New(t)
+
+	anim, err := GIFDecodeAnimation(newTestGIF(0))
+	assert.Nil(err)
+	assert.Equal(3, len(anim.Frames))
+	assert.Equal([]int{100, 200, 300}, anim.Delays)
+	assert.Equal(0, anim.LoopCount)
+	for i, frame := range anim.Frames {
+		r, g, b, _ := frame.At(10, 10).RGBA()
+		er, eg, eb, _ := testGIFColors[i].RGBA()
+		assert.Equal([]uint32{er, eg, eb}, []uint32{r, g, b})
+	}
+
+	anim, err = GIFDecodeAnimation(newTestGIF(2))
+	assert.Nil(err)
+	assert.Equal(3, anim.LoopCount)
+
+	img, err := GIFDecode(newTestGIF(0))
+	assert.Nil(err)
+	assert.Equal(image.Rect(0, 0, 40, 20), img.Bounds())
+}
+
+func TestGIFDecodeLimit(t *testing.T) {
+	assert := assert.New(t)
+	defer SetGIFDecodeLimit(0, 0)
+
+	// 画布超出限制
+	SetGIFDecodeLimit(40*20-1, 0)
+	_, err := GIFDecode(newTestGIF(0))
+	assert.Equal(ErrImageIsTooLarge, err)
+	_, err = GIFDecodeAnimation(newTestGIF(0))
+	assert.Equal(ErrImageIsTooLarge, err)
+
+	// 所有帧超出限制
+	SetGIFDecodeLimit(0, 40*20*2)
+	_, err = GIFDecode(newTestGIF(0))
+	assert.Nil(err)
+	_, err = GIFDecodeAnimation(newTestGIF(0))
+	assert.Equal(ErrImageIsTooLarge, err)
+
+	// 帧较小但画布较大，解码前根据帧数检查
+	SetGIFDecodeLimit(0, 0)
+	g := &gif.GIF{
+		Config: image.Config{
+			Width:      4000,
+			Height:     4000,
+			ColorModel: color.Palette{color.Black, color.White},
+		},
+	}
+	for i := 0; i < 20; i++ {
+		g.Image = append(g.Image, image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.Black, color.White}))
+		g.Delay = append(g.Delay, 0)
+	}
+	buf := &bytes.Buffer{}
+	err = gif.EncodeAll(buf, g)
+	assert.Nil(err)
+	_, err = GIFDecodeAnimation(buf.Bytes())
+	assert.Equal(ErrImageIsTooLarge, err)
+}
+
+func TestCountGIFFrames(t *testing.T) {
+	assert := assert.New(t)
+	data := newTestGIF(0)
+	assert.Equal(3, countGIFFrames(data))
+	// 包含循环次数的扩展
+	assert.Equal(3, countGIFFrames(newTestGIF(2)))
+	// 数据不完整
+	assert.Equal(3, countGIFFrames(data[:len(data)-1]))
+	assert.Equal(0, countGIFFrames(data[:10]))
+	assert.Equal(0, countGIFFrames(nil))
+}
+
+func TestImageOptimAnimation(t *testing.T) {
+	assert := assert.New(t)
+	data := newTestGIF(2)
+	assert.Equal(EncodeTypeGIF, detectImageType(data))
+
+	for _, quality := range []int{0, 80} {
+		img, err := ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+			Output:  EncodeTypeWEBP,
+			Quality: quality,
+			Width:   20,
+		})
+		assert.Nil(err)
+		assert.Equal(20, img.Width)
+		assert.Equal(10, img.Height)
+
+		chunks, err := parseWebpChunks(img.Data)
+		assert.Nil(err)
+		assert.Equal("VP8X", chunks[0].name)
+		assert.NotEqual(0, chunks[0].data[0]&webpFlagAnimation)
+		assert.Equal("ANIM", chunks[1].name)
+		assert.Equal(uint16(3), binary.LittleEndian.Uint16(chunks[1].data[4:]))
+		durations := make([]int, 0)
+		for _, c := range chunks[2:] {
+			assert.Equal("ANMF", c.name)
+			// 每帧均调整尺寸
+			assert.Equal(19, int(c.data[6])|int(c.data[7])<<8)
+			assert.Equal(9, int(c.data[9])|int(c.data[10])<<8)
+			durations = append(durations, int(c.data[12])|int(c.data[13])<<8)
+		}
+		assert.Equal([]int{100, 200, 300}, durations)
+	}
+
+	// 不支持动图的输出则使用第一帧
+	img, err := ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+		Source: EncodeTypeGIF,
+		Output: EncodeTypePNG,
+	})
+	assert.Nil(err)
+	assert.Equal(40, img.Width)
+	decoded, err := imageDecode(img.Data, EncodeTypePNG)
+	assert.Nil(err)
+	r, _, _, _ := decoded.At(10, 10).RGBA()
+	assert.Equal(uint32(0xffff), r)
+
+	// 已取消则不再处理各帧
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ImageOptimWithOptions(ctx, data, &ImageOptimOptions{
+		Output: EncodeTypeWEBP,
+	})
+	assert.True(errors.Is(err, context.Canceled))
+}
+
+func TestImageQuantize(t *testing.T) {
+	assert := assert.New(t)
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 4))
+	for x := 0; x < 64; x++ {
+		for y := 0; y < 4; y++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x * 4), uint8(y * 60), 128, 255})
+		}
+	}
+	img.SetNRGBA(0, 0, color.NRGBA{255, 255, 255, 10})
+
+	result := ImageQuantize(img, 16, false)
+	assert.Equal(16, len(result.Palette))
+	// 透明像素使用透明色
+	_, _, _, a := result.At(0, 0).RGBA()
+	assert.Equal(uint32(0), a)
+	_, _, _, a = result.At(1, 0).RGBA()
+	assert.Equal(uint32(0xffff), a)
+
+	dithered := ImageQuantize(img, 16, true)
+	assert.Equal(result.Palette, dithered.Palette)
+	assert.NotEqual(result.Pix, dithered.Pix)
+
+	// 颜色数量不超过调色板则不损失
+	result = ImageQuantize(img, 0, false)
+	assert.Equal(img.NRGBAAt(10, 2), color.NRGBAModel.Convert(result.At(10, 2)))
+
+	assert.Equal(maxGIFColors, getGIFColors(0))
+	assert.Equal(128, getGIFColors(50))
+	assert.Equal(2, getGIFColors(1))
+}
+
+func TestImageOptimGIF(t *testing.T) {
+	assert := assert.New(t)
+
+	img, err := ImageOptimWithOptions(context.Background(), newTestGIF(2), &ImageOptimOptions{
+		Output: EncodeTypeGIF,
+		Width:  20,
+	})
+	assert.Nil(err)
+	assert.Equal(EncodeTypeGIF, img.Type)
+	assert.Equal(EncoderGoGIF, img.Encoder)
+	g, err := gif.DecodeAll(bytes.NewReader(img.Data))
+	assert.Nil(err)
+	assert.Equal(3, len(g.Image))
+	assert.Equal(2, g.LoopCount)
+	assert.Equal([]int{10, 20, 30}, g.Delay)
+	assert.Equal(image.Rect(0, 0, 20, 10), g.Image[0].Bounds())
+
+	img, err = ImageOptimWithOptions(context.Background(), newTestJPEGWithOrientation(1), &ImageOptimOptions{
+		Source: EncodeTypeJPEG,
+		Output: EncodeTypeGIF,
+		Dither: true,
+	})
+	assert.Nil(err)
+	assert.Equal(EncodeTypeGIF, detectImageType(img.Data))
+	assert.Equal(EncodeTypeGIF, ConvertToEncodeType("gif"))
+}
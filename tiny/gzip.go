@@ -17,15 +17,26 @@ package tiny
 import (
 	"bytes"
 	"compress/gzip"
+	"io"
 )
 
-// GzipEncode gzip compress
-func GzipEncode(buf []byte, quality int) ([]byte, error) {
+// NewGzipWriter create a gzip writer
+func NewGzipWriter(w io.Writer, quality int) (io.WriteCloser, error) {
 	if quality <= 0 || quality > gzip.BestCompression {
 		quality = defaultGzipQuality
 	}
+	return gzip.NewWriterLevel(w, quality)
+}
+
+// NewGzipReader create a gzip reader
+func NewGzipReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// GzipEncode gzip compress
+func GzipEncode(buf []byte, quality int) ([]byte, error) {
 	var b bytes.Buffer
-	w, err := gzip.NewWriterLevel(&b, quality)
+	w, err := NewGzipWriter(&b, quality)
 	if err != nil {
 		return nil, err
 	}
@@ -41,3 +52,13 @@ func GzipEncode(buf []byte, quality int) ([]byte, error) {
 	}
 	return b.Bytes(), nil
 }
+
+// GzipDecode gzip decompress
+func GzipDecode(buf []byte) ([]byte, error) {
+	r, err := NewGzipReader(bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return readAllWithLimit(r)
+}
@@ -27,3 +27,13 @@ func TestGzipEncode(t *testing.T) {
 	assert.Nil(err)
 	assert.NotNil(buf)
 }
+
+func TestGzipDecode(t *testing.T) {
+	assert := assert.New(t)
+	originalBuf := []byte("abcdabcdabcdabcdabcdabcdabcdabcd")
+	buf, err := GzipEncode(originalBuf, 0)
+	assert.Nil(err)
+	data, err := GzipDecode(buf)
+	assert.Nil(err)
+	assert.Equal(originalBuf, data)
+}
@@ -0,0 +1,316 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	iccHeaderSize = 128
+	// 线性值转换为sRGB的查询表大小
+	srgbLUTSize = 1 << 14
+)
+
+// ErrICCNotSupported the icc profile is not supported,
+// only the matrix/trc rgb profile is supported
+var ErrICCNotSupported = errors.New("icc profile is not supported")
+
+// srgbColorants the colorants of sRGB(D50)
+var srgbColorants = [3][3]float64{
+	{0.4360747, 0.3850649, 0.1430804},
+	{0.2225045, 0.7168786, 0.0606169},
+	{0.0139322, 0.0971045, 0.7141733},
+}
+
+// iccProfile the matrix/trc rgb profile
+type iccProfile struct {
+	// matrix convert the linear rgb to xyz(D50)
+	matrix [3][3]float64
+	curves [3]func(float64) float64
+}
+
+// srgbEncode convert the linear value to sRGB
+func srgbEncode(v float64) float64 {
+	if v <= 0.0031308 {
+		return 12.92 * v
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// srgbDecode convert the sRGB value to linear
+func srgbDecode(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// clampUnit clamp the value to [0, 1], NaN is converted to 0
+func clampUnit(v float64) float64 {
+	if math.IsNaN(v) || v <= 0 {
+		return 0
+	}
+	if v >= 1 {
+		return 1
+	}
+	return v
+}
+
+func readS15Fixed16(data []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(data))) / 65536
+}
+
+// parseICCCurve parse the curv or para tag to function
+func parseICCCurve(data []byte) (func(float64) float64, error) {
+	if len(data) < 12 {
+		return nil, ErrICCNotSupported
+	}
+	switch string(data[0:4]) {
+	case "curv":
+		count := int(binary.BigEndian.Uint32(data[8:]))
+		if len(data) < 12+count*2 {
+			return nil, ErrICCNotSupported
+		}
+		switch count {
+		case 0:
+			return func(v float64) float64 {
+				return v
+			}, nil
+		case 1:
+			gamma := float64(binary.BigEndian.Uint16(data[12:])) / 256
+			return func(v float64) float64 {
+				return math.Pow(v, gamma)
+			}, nil
+		}
+		table := make([]float64, count)
+		for i := range table {
+			table[i] = float64(binary.BigEndian.Uint16(data[12+i*2:])) / 65535
+		}
+		// 表中数据为等间隔采样，其它值线性插值
+		return func(v float64) float64 {
+			pos := v * float64(count-1)
+			index := int(pos)
+			if index >= count-1 {
+				return table[count-1]
+			}
+			if index < 0 {
+				return table[0]
+			}
+			return table[index] + (table[index+1]-table[index])*(pos-float64(index))
+		}, nil
+	case "para":
+		funcType := int(binary.BigEndian.Uint16(data[8:]))
+		paramCounts := []int{1, 3, 4, 5, 7}
+		if funcType >= len(paramCounts) || len(data) < 12+paramCounts[funcType]*4 {
+			return nil, ErrICCNotSupported
+		}
+		// g, a, b, c, d, e, f
+		params := make([]float64, 7)
+		for i := 0; i < paramCounts[funcType]; i++ {
+			params[i] = readS15Fixed16(data[12+i*4:])
+			if math.IsNaN(params[i]) || math.IsInf(params[i], 0) {
+				return nil, ErrICCNotSupported
+			}
+		}
+		g, a, b, c, d, e, f := params[0], params[1], params[2], params[3], params[4], params[5], params[6]
+		// 类型1与2需要计算-b/a
+		if (funcType == 1 || funcType == 2) && a == 0 {
+			return nil, ErrICCNotSupported
+		}
+		pow := func(v float64) float64 {
+			if v <= 0 {
+				return 0
+			}
+			return math.Pow(v, g)
+		}
+		switch funcType {
+		case 0:
+			return pow, nil
+		case 1:
+			return func(v float64) float64 {
+				if v >= -b/a {
+					return pow(a*v + b)
+				}
+				return 0
+			}, nil
+		case 2:
+			return func(v float64) float64 {
+				if v >= -b/a {
+					return pow(a*v+b) + c
+				}
+				return c
+			}, nil
+		case 3:
+			return func(v float64) float64 {
+				if v >= d {
+					return pow(a*v + b)
+				}
+				return c * v
+			}, nil
+		default:
+			return func(v float64) float64 {
+				if v >= d {
+					return pow(a*v+b) + e
+				}
+				return c*v + f
+			}, nil
+		}
+	default:
+		return nil, ErrICCNotSupported
+	}
+}
+
+// parseICCProfile parse the matrix/trc rgb profile
+func parseICCProfile(data []byte) (*iccProfile, error) {
+	if len(data) < iccHeaderSize+4 || string(data[16:20]) != "RGB " {
+		return nil, ErrICCNotSupported
+	}
+	tags := make(map[string][]byte)
+	count := int(binary.BigEndian.Uint32(data[iccHeaderSize:]))
+	for i := 0; i < count; i++ {
+		offset := iccHeaderSize + 4 + i*12
+		if offset+12 > len(data) {
+			return nil, ErrICCNotSupported
+		}
+		start := int(binary.BigEndian.Uint32(data[offset+4:]))
+		size := int(binary.BigEndian.Uint32(data[offset+8:]))
+		if start < 0 || size < 0 || start+size > len(data) {
+			return nil, ErrICCNotSupported
+		}
+		tags[string(data[offset:offset+4])] = data[start : start+size]
+	}
+	profile := &iccProfile{}
+	for i, name := range []string{"r", "g", "b"} {
+		xyz := tags[name+"XYZ"]
+		if len(xyz) < 20 || string(xyz[0:4]) != "XYZ " {
+			return nil, ErrICCNotSupported
+		}
+		// 每个颜色的xyz为矩阵的一列
+		for j := 0; j < 3; j++ {
+			profile.matrix[j][i] = readS15Fixed16(xyz[8+j*4:])
+		}
+		curve, err := parseICCCurve(tags[name+"TRC"])
+		if err != nil {
+			return nil, err
+		}
+		// 异常的参数（如极大的gamma）可能导致Inf，因此限制结果为[0, 1]
+		profile.curves[i] = func(v float64) float64 {
+			return clampUnit(curve(v))
+		}
+	}
+	return profile, nil
+}
+
+// isSRGB check the profile is same as sRGB
+func (p *iccProfile) isSRGB() bool {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if math.Abs(p.matrix[i][j]-srgbColorants[i][j]) > 0.002 {
+				return false
+			}
+		}
+	}
+	for _, curve := range p.curves {
+		for _, v := range []float64{0.05, 0.2, 0.5, 0.8} {
+			if math.Abs(curve(v)-srgbDecode(v)) > 0.002 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// invertMatrix invert the 3x3 matrix
+func invertMatrix(m [3][3]float64) ([3][3]float64, bool) {
+	var result [3][3]float64
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	if math.Abs(det) < 1e-10 {
+		return result, false
+	}
+	result[0][0] = (m[1][1]*m[2][2] - m[1][2]*m[2][1]) / det
+	result[0][1] = (m[0][2]*m[2][1] - m[0][1]*m[2][2]) / det
+	result[0][2] = (m[0][1]*m[1][2] - m[0][2]*m[1][1]) / det
+	result[1][0] = (m[1][2]*m[2][0] - m[1][0]*m[2][2]) / det
+	result[1][1] = (m[0][0]*m[2][2] - m[0][2]*m[2][0]) / det
+	result[1][2] = (m[0][2]*m[1][0] - m[0][0]*m[1][2]) / det
+	result[2][0] = (m[1][0]*m[2][1] - m[1][1]*m[2][0]) / det
+	result[2][1] = (m[0][1]*m[2][0] - m[0][0]*m[2][1]) / det
+	result[2][2] = (m[0][0]*m[1][1] - m[0][1]*m[1][0]) / det
+	return result, true
+}
+
+func multiplyMatrix(a, b [3][3]float64) [3][3]float64 {
+	var result [3][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				result[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return result
+}
+
+// ImageToSRGB convert the pixels of image from the icc profile to sRGB,
+// the image is returned directly if the profile is sRGB
+func ImageToSRGB(img image.Image, icc []byte) (image.Image, error) {
+	profile, err := parseICCProfile(icc)
+	if err != nil {
+		return nil, err
+	}
+	if profile.isSRGB() {
+		return img, nil
+	}
+	toSRGB, ok := invertMatrix(srgbColorants)
+	if !ok {
+		return nil, ErrICCNotSupported
+	}
+	// profile rgb -> xyz -> linear sRGB
+	matrix := multiplyMatrix(toSRGB, profile.matrix)
+
+	var linear [3][256]float64
+	for i, curve := range profile.curves {
+		for j := 0; j < 256; j++ {
+			linear[i][j] = curve(float64(j) / 255)
+		}
+	}
+	encode := make([]uint8, srgbLUTSize)
+	for i := range encode {
+		encode[i] = uint8(math.Round(srgbEncode(float64(i)/(srgbLUTSize-1)) * 255))
+	}
+	toUint8 := func(v float64) uint8 {
+		return encode[int(clampUnit(v)*(srgbLUTSize-1)+0.5)]
+	}
+
+	// 转换为非预乘alpha的8位图片后处理
+	dst := imaging.Clone(img)
+	for i := 0; i+3 < len(dst.Pix); i += 4 {
+		r := linear[0][dst.Pix[i]]
+		g := linear[1][dst.Pix[i+1]]
+		b := linear[2][dst.Pix[i+2]]
+		dst.Pix[i] = toUint8(matrix[0][0]*r + matrix[0][1]*g + matrix[0][2]*b)
+		dst.Pix[i+1] = toUint8(matrix[1][0]*r + matrix[1][1]*g + matrix[1][2]*b)
+		dst.Pix[i+2] = toUint8(matrix[2][0]*r + matrix[2][1]*g + matrix[2][2]*b)
+	}
+	return dst, nil
+}
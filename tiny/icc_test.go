@@ -0,0 +1,197 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the colorants of display p3(D50)
+var displayP3Colorants = [3][3]float64{
+	{0.5151, 0.2920, 0.1571},
+	{0.2412, 0.6922, 0.0666},
+	{-0.0011, 0.0419, 0.7841},
+}
+
+func putS15Fixed16(buf []byte, v float64) {
+	binary.BigEndian.PutUint32(buf, uint32(int32(math.Round(v*65536))))
+}
+
+// newTestICC create the matrix/trc rgb profile
+func newTestICC(colorants [3][3]float64, curve []byte) []byte {
+	tags := make([][]byte, 0)
+	names := make([]string, 0)
+	for i, name := range []string{"r", "g", "b"} {
+		xyz := make([]byte, 20)
+		copy(xyz, "XYZ ")
+		for j := 0; j < 3; j++ {
+			putS15Fixed16(xyz[8+j*4:], colorants[j][i])
+		}
+		tags = append(tags, xyz, curve)
+		names = append(names, name+"XYZ", name+"TRC")
+	}
+	header := make([]byte, iccHeaderSize+4+len(tags)*12)
+	copy(header[16:], "RGB ")
+	binary.BigEndian.PutUint32(header[iccHeaderSize:], uint32(len(tags)))
+	data := &bytes.Buffer{}
+	offset := len(header)
+	for i, tag := range tags {
+		entry := header[iccHeaderSize+4+i*12:]
+		copy(entry, names[i])
+		binary.BigEndian.PutUint32(entry[4:], uint32(offset+data.Len()))
+		binary.BigEndian.PutUint32(entry[8:], uint32(len(tag)))
+		data.Write(tag)
+	}
+	return append(header, data.Bytes()...)
+}
+
+// newSRGBCurve create the para curve of sRGB
+func newSRGBCurve() []byte {
+	curve := make([]byte, 12+5*4)
+	copy(curve, "para")
+	binary.BigEndian.PutUint16(curve[8:], 3)
+	for i, v := range []float64{2.4, 1 / 1.055, 0.055 / 1.055, 1 / 12.92, 0.04045} {
+		putS15Fixed16(curve[12+i*4:], v)
+	}
+	return curve
+}
+
+// newGammaCurve create the curv of gamma
+func newGammaCurve(gamma float64) []byte {
+	curve := make([]byte, 14)
+	copy(curve, "curv")
+	binary.BigEndian.PutUint32(curve[8:], 1)
+	binary.BigEndian.PutUint16(curve[12:], uint16(gamma*256))
+	return curve
+}
+
+func TestParseICCProfile(t *testing.T) {
+	assert := assert.New(t)
+
+	profile, err := parseICCProfile(newTestICC(srgbColorants, newSRGBCurve()))
+	assert.Nil(err)
+	assert.True(profile.isSRGB())
+
+	profile, err = parseICCProfile(newTestICC(displayP3Colorants, newSRGBCurve()))
+	assert.Nil(err)
+	assert.False(profile.isSRGB())
+
+	profile, err = parseICCProfile(newTestICC(srgbColorants, newGammaCurve(2.2)))
+	assert.Nil(err)
+	assert.False(profile.isSRGB())
+	assert.InDelta(math.Pow(0.5, 2.2), profile.curves[0](0.5), 0.001)
+
+	_, err = parseICCProfile([]byte("abcd"))
+	assert.Equal(ErrICCNotSupported, err)
+}
+
+func TestImageToSRGB(t *testing.T) {
+	assert := assert.New(t)
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{128, 128, 128, 255})
+	img.SetNRGBA(1, 0, color.NRGBA{200, 100, 50, 255})
+	img.SetNRGBA(2, 0, color.NRGBA{0, 255, 0, 128})
+
+	result, err := ImageToSRGB(img, newTestICC(displayP3Colorants, newSRGBCurve()))
+	assert.Nil(err)
+	// 白点相同，灰色不变
+	gray := color.NRGBAModel.Convert(result.At(0, 0)).(color.NRGBA)
+	assert.InDelta(128, int(gray.R), 1)
+	assert.InDelta(128, int(gray.G), 1)
+	assert.InDelta(128, int(gray.B), 1)
+	// display p3的色域更广，转换为sRGB后更饱和
+	c := color.NRGBAModel.Convert(result.At(1, 0)).(color.NRGBA)
+	assert.Greater(c.R, uint8(200))
+	assert.Less(c.B, uint8(50))
+	// 超出sRGB色域的值被截断，alpha不变
+	c = color.NRGBAModel.Convert(result.At(2, 0)).(color.NRGBA)
+	assert.Equal(color.NRGBA{0, 255, 0, 128}, color.NRGBA{c.R, c.G, 0, c.A})
+
+	// sRGB则不处理
+	result, err = ImageToSRGB(img, newTestICC(srgbColorants, newSRGBCurve()))
+	assert.Nil(err)
+	assert.Equal(img, result)
+
+	_, err = ImageToSRGB(img, []byte("abcd"))
+	assert.Equal(ErrICCNotSupported, err)
+
+	// 极大的gamma不会导致异常
+	curve := make([]byte, 12+3*4)
+	copy(curve, "para")
+	binary.BigEndian.PutUint16(curve[8:], 1)
+	putS15Fixed16(curve[12:], 30000)
+	putS15Fixed16(curve[16:], 4)
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	result, err = ImageToSRGB(img, newTestICC(displayP3Colorants, curve))
+	assert.Nil(err)
+	assert.NotNil(result)
+
+	// 类型1的a为0
+	curve = make([]byte, 12+3*4)
+	copy(curve, "para")
+	binary.BigEndian.PutUint16(curve[8:], 1)
+	putS15Fixed16(curve[12:], 2.2)
+	_, err = ImageToSRGB(img, newTestICC(displayP3Colorants, curve))
+	assert.Equal(ErrICCNotSupported, err)
+}
+
+func TestClampUnit(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(0.0, clampUnit(math.NaN()))
+	assert.Equal(0.0, clampUnit(-1))
+	assert.Equal(1.0, clampUnit(math.Inf(1)))
+	assert.Equal(0.5, clampUnit(0.5))
+}
+
+func TestImageOptimConvertToSRGB(t *testing.T) {
+	assert := assert.New(t)
+	icc := newTestICC(displayP3Colorants, newSRGBCurve())
+	buf := &bytes.Buffer{}
+	err := png.Encode(buf, getTestImage())
+	assert.Nil(err)
+	data, err := writeMetadata(buf.Bytes(), EncodeTypePNG, &metadata{
+		ICC: icc,
+	})
+	assert.Nil(err)
+
+	img, err := ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+		Source:   EncodeTypePNG,
+		Output:   EncodeTypeWEBP,
+		Metadata: MetadataKeepICC,
+	})
+	assert.Nil(err)
+	assert.Equal(icc, readMetadata(img.Data, EncodeTypeWEBP).ICC)
+
+	// 转换后不再保留icc
+	img, err = ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+		Source:        EncodeTypePNG,
+		Output:        EncodeTypeWEBP,
+		Metadata:      MetadataKeepICC,
+		ConvertToSRGB: true,
+	})
+	assert.Nil(err)
+	assert.Nil(readMetadata(img.Data, EncodeTypeWEBP).ICC)
+}
@@ -0,0 +1,783 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var errAVIFIsInvalid = errors.New("avif data is invalid")
+
+// isoBox the box of isobmff(avif)
+type isoBox struct {
+	typ string
+	// data the payload of box
+	data []byte
+	// raw the whole box including header
+	raw []byte
+}
+
+// parseISOBoxes parse the boxes of isobmff
+func parseISOBoxes(data []byte) ([]*isoBox, error) {
+	boxes := make([]*isoBox, 0)
+	offset := 0
+	for offset < len(data) {
+		if offset+8 > len(data) {
+			return nil, errAVIFIsInvalid
+		}
+		size := uint64(binary.BigEndian.Uint32(data[offset:]))
+		header := uint64(8)
+		switch size {
+		case 0:
+			// 0表示至文件结尾
+			size = uint64(len(data) - offset)
+		case 1:
+			if offset+16 > len(data) {
+				return nil, errAVIFIsInvalid
+			}
+			size = binary.BigEndian.Uint64(data[offset+8:])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)-offset) {
+			return nil, errAVIFIsInvalid
+		}
+		end := offset + int(size)
+		boxes = append(boxes, &isoBox{
+			typ:  string(data[offset+4 : offset+8]),
+			data: data[offset+int(header) : end],
+			raw:  data[offset:end],
+		})
+		offset = end
+	}
+	return boxes, nil
+}
+
+// newISOBox create the box of isobmff
+func newISOBox(typ string, data ...[]byte) []byte {
+	size := 8
+	for _, item := range data {
+		size += len(item)
+	}
+	buf := make([]byte, 8, size)
+	binary.BigEndian.PutUint32(buf, uint32(size))
+	copy(buf[4:], typ)
+	for _, item := range data {
+		buf = append(buf, item...)
+	}
+	return buf
+}
+
+// isoReader the reader of isobmff fields
+type isoReader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func (r *isoReader) read(size int) uint64 {
+	if r.err != nil {
+		return 0
+	}
+	if r.offset+size > len(r.data) {
+		r.err = errAVIFIsInvalid
+		return 0
+	}
+	var v uint64
+	for _, b := range r.data[r.offset : r.offset+size] {
+		v = v<<8 | uint64(b)
+	}
+	r.offset += size
+	return v
+}
+
+// isoWriter the writer of isobmff fields
+type isoWriter struct {
+	bytes.Buffer
+}
+
+func (w *isoWriter) write(size int, v uint64) {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, v)
+	w.Write(buf[8-size:])
+}
+
+// ilocExtent the extent of item location
+type ilocExtent struct {
+	index  uint64
+	offset uint64
+	length uint64
+}
+
+// ilocItem the item location
+type ilocItem struct {
+	id uint32
+	// method the construction method, 0 means the offset of file
+	method     uint16
+	refIndex   uint16
+	baseOffset uint64
+	extents    []*ilocExtent
+}
+
+// ilocBox the item location box
+type ilocBox struct {
+	version        byte
+	flags          []byte
+	offsetSize     int
+	lengthSize     int
+	baseOffsetSize int
+	indexSize      int
+	items          []*ilocItem
+}
+
+func parseIlocBox(data []byte) (*ilocBox, error) {
+	r := &isoReader{
+		data: data,
+	}
+	box := &ilocBox{}
+	box.version = byte(r.read(1))
+	if r.err == nil {
+		box.flags = data[1:4]
+	}
+	r.read(3)
+	v := r.read(1)
+	box.offsetSize = int(v >> 4)
+	box.lengthSize = int(v & 0x0f)
+	v = r.read(1)
+	box.baseOffsetSize = int(v >> 4)
+	if box.version == 1 || box.version == 2 {
+		box.indexSize = int(v & 0x0f)
+	}
+	idSize := 2
+	if box.version == 2 {
+		idSize = 4
+	}
+	count := r.read(idSize)
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		item := &ilocItem{
+			id: uint32(r.read(idSize)),
+		}
+		if box.version == 1 || box.version == 2 {
+			item.method = uint16(r.read(2) & 0x0f)
+		}
+		item.refIndex = uint16(r.read(2))
+		item.baseOffset = r.read(box.baseOffsetSize)
+		extentCount := r.read(2)
+		for j := uint64(0); j < extentCount && r.err == nil; j++ {
+			extent := &ilocExtent{}
+			if box.indexSize != 0 {
+				extent.index = r.read(box.indexSize)
+			}
+			extent.offset = r.read(box.offsetSize)
+			extent.length = r.read(box.lengthSize)
+			item.extents = append(item.extents, extent)
+		}
+		box.items = append(box.items, item)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return box, nil
+}
+
+func (box *ilocBox) bytes() []byte {
+	w := &isoWriter{}
+	w.WriteByte(box.version)
+	w.Write(box.flags)
+	w.write(1, uint64(box.offsetSize<<4|box.lengthSize))
+	w.write(1, uint64(box.baseOffsetSize<<4|box.indexSize))
+	idSize := 2
+	if box.version == 2 {
+		idSize = 4
+	}
+	w.write(idSize, uint64(len(box.items)))
+	for _, item := range box.items {
+		w.write(idSize, uint64(item.id))
+		if box.version == 1 || box.version == 2 {
+			w.write(2, uint64(item.method))
+		}
+		w.write(2, uint64(item.refIndex))
+		w.write(box.baseOffsetSize, item.baseOffset)
+		w.write(2, uint64(len(item.extents)))
+		for _, extent := range item.extents {
+			w.write(box.indexSize, extent.index)
+			w.write(box.offsetSize, extent.offset)
+			w.write(box.lengthSize, extent.length)
+		}
+	}
+	return newISOBox("iloc", w.Bytes())
+}
+
+// maxValue get the max value of the field size
+func maxValue(size int) uint64 {
+	if size >= 8 {
+		return math.MaxUint64
+	}
+	return 1<<(uint(size)*8) - 1
+}
+
+// shift shift the offset of items which are located in the file after the position
+func (box *ilocBox) shift(position uint64, delta int64) error {
+	for _, item := range box.items {
+		if item.method != 0 || item.refIndex != 0 || len(item.extents) == 0 {
+			continue
+		}
+		if item.baseOffset+item.extents[0].offset < position {
+			continue
+		}
+		if box.baseOffsetSize != 0 {
+			item.baseOffset = uint64(int64(item.baseOffset) + delta)
+			if item.baseOffset > maxValue(box.baseOffsetSize) {
+				return errAVIFIsInvalid
+			}
+			continue
+		}
+		for _, extent := range item.extents {
+			extent.offset = uint64(int64(extent.offset) + delta)
+			if extent.offset > maxValue(box.offsetSize) {
+				return errAVIFIsInvalid
+			}
+		}
+	}
+	return nil
+}
+
+// setOffset set the offset of item which has only one extent
+func (box *ilocBox) setOffset(item *ilocItem, offset uint64) error {
+	if box.offsetSize != 0 {
+		item.extents[0].offset = offset
+		if offset > maxValue(box.offsetSize) {
+			return errAVIFIsInvalid
+		}
+		return nil
+	}
+	if box.baseOffsetSize == 0 || offset > maxValue(box.baseOffsetSize) {
+		return errAVIFIsInvalid
+	}
+	item.baseOffset = offset
+	return nil
+}
+
+// find find the item location by id
+func (box *ilocBox) find(id uint32) *ilocItem {
+	for _, item := range box.items {
+		if item.id == id {
+			return item
+		}
+	}
+	return nil
+}
+
+// ipmaEntry the property association of item
+type ipmaEntry struct {
+	id uint32
+	// associations the property indexes(1-based), the highest bit is essential flag
+	associations []uint16
+}
+
+// ipmaBox the item property association box
+type ipmaBox struct {
+	version byte
+	flags   []byte
+	entries []*ipmaEntry
+}
+
+func parseIpmaBox(data []byte) (*ipmaBox, error) {
+	r := &isoReader{
+		data: data,
+	}
+	box := &ipmaBox{}
+	box.version = byte(r.read(1))
+	if r.err == nil {
+		box.flags = append([]byte{}, data[1:4]...)
+	}
+	r.read(3)
+	idSize := 2
+	if box.version >= 1 {
+		idSize = 4
+	}
+	large := r.err == nil && box.flags[2]&1 != 0
+	count := r.read(4)
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		entry := &ipmaEntry{
+			id: uint32(r.read(idSize)),
+		}
+		associationCount := r.read(1)
+		for j := uint64(0); j < associationCount && r.err == nil; j++ {
+			if large {
+				entry.associations = append(entry.associations, uint16(r.read(2)))
+				continue
+			}
+			// 8位时最高位为essential，转换为16位的形式
+			v := uint16(r.read(1))
+			entry.associations = append(entry.associations, (v&0x80)<<8|v&0x7f)
+		}
+		box.entries = append(box.entries, entry)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return box, nil
+}
+
+func (box *ipmaBox) bytes() []byte {
+	large := false
+	for _, entry := range box.entries {
+		for _, v := range entry.associations {
+			if v&0x7fff > 0x7f {
+				large = true
+			}
+		}
+	}
+	if large {
+		box.flags[2] |= 1
+	}
+	large = box.flags[2]&1 != 0
+	w := &isoWriter{}
+	w.WriteByte(box.version)
+	w.Write(box.flags)
+	idSize := 2
+	if box.version >= 1 {
+		idSize = 4
+	}
+	w.write(4, uint64(len(box.entries)))
+	for _, entry := range box.entries {
+		w.write(idSize, uint64(entry.id))
+		w.write(1, uint64(len(entry.associations)))
+		for _, v := range entry.associations {
+			if large {
+				w.write(2, uint64(v))
+				continue
+			}
+			w.write(1, uint64(v>>8&0x80|v&0x7f))
+		}
+	}
+	return newISOBox("ipma", w.Bytes())
+}
+
+// add add the property association of item
+func (box *ipmaBox) add(id uint32, index uint16) {
+	for _, entry := range box.entries {
+		if entry.id == id {
+			entry.associations = append(entry.associations, index)
+			return
+		}
+	}
+	box.entries = append(box.entries, &ipmaEntry{
+		id:           id,
+		associations: []uint16{index},
+	})
+}
+
+// avifMeta the meta box of avif
+type avifMeta struct {
+	header    []byte
+	boxes     []*isoBox
+	primaryID uint32
+	iloc      *ilocBox
+	// items the type of items
+	items map[uint32]string
+	ipco  []*isoBox
+	ipma  *ipmaBox
+}
+
+func findISOBox(boxes []*isoBox, typ string) *isoBox {
+	for _, box := range boxes {
+		if box.typ == typ {
+			return box
+		}
+	}
+	return nil
+}
+
+// parseAVIFMeta parse the meta box of avif
+func parseAVIFMeta(data []byte) (*avifMeta, error) {
+	if len(data) < 4 {
+		return nil, errAVIFIsInvalid
+	}
+	boxes, err := parseISOBoxes(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	meta := &avifMeta{
+		header: data[0:4],
+		boxes:  boxes,
+		items:  make(map[uint32]string),
+	}
+	pitm := findISOBox(boxes, "pitm")
+	iloc := findISOBox(boxes, "iloc")
+	iinf := findISOBox(boxes, "iinf")
+	iprp := findISOBox(boxes, "iprp")
+	if pitm == nil || iloc == nil || iinf == nil || iprp == nil {
+		return nil, errAVIFIsInvalid
+	}
+
+	r := &isoReader{
+		data: pitm.data,
+	}
+	if r.read(1) == 0 {
+		r.read(3)
+		meta.primaryID = uint32(r.read(2))
+	} else {
+		r.read(3)
+		meta.primaryID = uint32(r.read(4))
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	meta.iloc, err = parseIlocBox(iloc.data)
+	if err != nil {
+		return nil, err
+	}
+
+	r = &isoReader{
+		data: iinf.data,
+	}
+	countSize := 2
+	if r.read(1) != 0 {
+		countSize = 4
+	}
+	r.read(3)
+	r.read(countSize)
+	if r.err != nil {
+		return nil, r.err
+	}
+	infes, err := parseISOBoxes(iinf.data[r.offset:])
+	if err != nil {
+		return nil, err
+	}
+	for _, infe := range infes {
+		r = &isoReader{
+			data: infe.data,
+		}
+		version := byte(r.read(1))
+		r.read(3)
+		// 仅version 2与3包括item type
+		if version < 2 {
+			continue
+		}
+		idSize := 2
+		if version == 3 {
+			idSize = 4
+		}
+		id := uint32(r.read(idSize))
+		r.read(2)
+		if r.err != nil || r.offset+4 > len(infe.data) {
+			return nil, errAVIFIsInvalid
+		}
+		meta.items[id] = string(infe.data[r.offset : r.offset+4])
+	}
+
+	iprpBoxes, err := parseISOBoxes(iprp.data)
+	if err != nil {
+		return nil, err
+	}
+	ipco := findISOBox(iprpBoxes, "ipco")
+	ipma := findISOBox(iprpBoxes, "ipma")
+	if ipco == nil || ipma == nil {
+		return nil, errAVIFIsInvalid
+	}
+	meta.ipco, err = parseISOBoxes(ipco.data)
+	if err != nil {
+		return nil, err
+	}
+	meta.ipma, err = parseIpmaBox(ipma.data)
+	if err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// maxItemID get the max id of items
+func (meta *avifMeta) maxItemID() uint32 {
+	id := meta.primaryID
+	for itemID := range meta.items {
+		if itemID > id {
+			id = itemID
+		}
+	}
+	for _, item := range meta.iloc.items {
+		if item.id > id {
+			id = item.id
+		}
+	}
+	return id
+}
+
+// iccIndex get the index(1-based) of icc profile which is associated with primary item
+func (meta *avifMeta) iccIndex() int {
+	for _, entry := range meta.ipma.entries {
+		if entry.id != meta.primaryID {
+			continue
+		}
+		for _, v := range entry.associations {
+			index := int(v & 0x7fff)
+			if index == 0 || index > len(meta.ipco) {
+				continue
+			}
+			box := meta.ipco[index-1]
+			if box.typ == "colr" && len(box.data) >= 4 {
+				switch string(box.data[0:4]) {
+				case "prof", "rICC":
+					return index
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func readAVIFMetadata(data []byte) *metadata {
+	m := &metadata{}
+	boxes, err := parseISOBoxes(data)
+	if err != nil {
+		return m
+	}
+	metaBox := findISOBox(boxes, "meta")
+	if metaBox == nil {
+		return m
+	}
+	meta, err := parseAVIFMeta(metaBox.data)
+	if err != nil {
+		return m
+	}
+	if index := meta.iccIndex(); index != 0 {
+		m.ICC = meta.ipco[index-1].data[4:]
+	}
+	for id, typ := range meta.items {
+		if typ != "Exif" {
+			continue
+		}
+		item := meta.iloc.find(id)
+		// 仅支持单个extent且位于文件中的数据
+		if item == nil || item.method != 0 || len(item.extents) != 1 {
+			continue
+		}
+		start := item.baseOffset + item.extents[0].offset
+		end := start + item.extents[0].length
+		if end > uint64(len(data)) || end < start+4 {
+			continue
+		}
+		// 前4字节为tiff头的偏移
+		exif := data[start+4 : end]
+		offset := uint64(binary.BigEndian.Uint32(data[start:]))
+		if offset > uint64(len(exif)) {
+			continue
+		}
+		m.Exif = exif[offset:]
+	}
+	return m
+}
+
+// bytes create the meta box
+func (meta *avifMeta) bytes(iinf, iref []byte) []byte {
+	iprp := newISOBox("iprp", newISOBox("ipco", isoBoxesBytes(meta.ipco)...), meta.ipma.bytes())
+	data := [][]byte{
+		meta.header,
+	}
+	for _, box := range meta.boxes {
+		switch box.typ {
+		case "iloc":
+			data = append(data, meta.iloc.bytes())
+		case "iinf":
+			data = append(data, iinf)
+		case "iprp":
+			data = append(data, iprp)
+		case "iref":
+			data = append(data, iref)
+		default:
+			data = append(data, box.raw)
+		}
+	}
+	if findISOBox(meta.boxes, "iref") == nil && iref != nil {
+		data = append(data, iref)
+	}
+	return newISOBox("meta", data...)
+}
+
+func isoBoxesBytes(boxes []*isoBox) [][]byte {
+	result := make([][]byte, len(boxes))
+	for i, box := range boxes {
+		result[i] = box.raw
+	}
+	return result
+}
+
+// addExif add the exif item which describes the primary item, it returns the iinf and iref box
+func (meta *avifMeta) addExif(id uint32, length int) ([]byte, []byte, error) {
+	iinf := findISOBox(meta.boxes, "iinf")
+	// 更新iinf
+	r := &isoReader{
+		data: iinf.data,
+	}
+	version := byte(r.read(1))
+	countSize := 2
+	if version != 0 {
+		countSize = 4
+	}
+	r.read(3)
+	count := r.read(countSize)
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	w := &isoWriter{}
+	w.Write(iinf.data[0:4])
+	w.write(countSize, count+1)
+	w.Write(iinf.data[r.offset:])
+	infe := &isoWriter{}
+	if id > math.MaxUint16 {
+		infe.write(4, 3<<24)
+		infe.write(4, uint64(id))
+	} else {
+		infe.write(4, 2<<24)
+		infe.write(2, uint64(id))
+	}
+	// protection index, item type与name
+	infe.write(2, 0)
+	infe.WriteString("Exif\x00")
+	w.Write(newISOBox("infe", infe.Bytes()))
+	iinfBytes := newISOBox("iinf", w.Bytes())
+
+	// 添加iloc
+	if meta.iloc.lengthSize == 0 || uint64(length) > maxValue(meta.iloc.lengthSize) {
+		return nil, nil, errAVIFIsInvalid
+	}
+	meta.iloc.items = append(meta.iloc.items, &ilocItem{
+		id: id,
+		extents: []*ilocExtent{
+			{
+				length: uint64(length),
+			},
+		},
+	})
+
+	// 添加cdsc的引用
+	iref := findISOBox(meta.boxes, "iref")
+	w = &isoWriter{}
+	idSize := 2
+	if iref != nil {
+		w.Write(iref.data)
+		if iref.data[0] != 0 {
+			idSize = 4
+		}
+	} else {
+		if id > math.MaxUint16 {
+			idSize = 4
+			w.write(4, 1<<24)
+		} else {
+			w.write(4, 0)
+		}
+	}
+	if idSize == 2 && id > math.MaxUint16 {
+		return nil, nil, errAVIFIsInvalid
+	}
+	cdsc := &isoWriter{}
+	cdsc.write(idSize, uint64(id))
+	cdsc.write(2, 1)
+	cdsc.write(idSize, uint64(meta.primaryID))
+	w.Write(newISOBox("cdsc", cdsc.Bytes()))
+	return iinfBytes, newISOBox("iref", w.Bytes()), nil
+}
+
+func writeAVIFMetadata(data []byte, m *metadata) ([]byte, error) {
+	boxes, err := parseISOBoxes(data)
+	if err != nil {
+		return nil, err
+	}
+	metaIndex := -1
+	metaEnd := 0
+	for index, box := range boxes {
+		metaEnd += len(box.raw)
+		if box.typ == "meta" {
+			metaIndex = index
+			break
+		}
+	}
+	if metaIndex < 0 {
+		return nil, errAVIFIsInvalid
+	}
+	meta, err := parseAVIFMeta(boxes[metaIndex].data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(m.ICC) != 0 {
+		colr := newISOBox("colr", []byte("prof"), m.ICC)
+		colrBox := &isoBox{
+			typ:  "colr",
+			data: colr[8:],
+			raw:  colr,
+		}
+		// 已有icc则替换，否则添加并关联至主图
+		if index := meta.iccIndex(); index != 0 {
+			meta.ipco[index-1] = colrBox
+		} else {
+			meta.ipco = append(meta.ipco, colrBox)
+			meta.ipma.add(meta.primaryID, uint16(len(meta.ipco)))
+		}
+	}
+	var iinf, iref []byte
+	var exifItem *ilocItem
+	var exifPayload []byte
+	if len(m.Exif) != 0 {
+		// 最后的box长度为0（至文件结尾）时无法在其后添加数据
+		if binary.BigEndian.Uint32(boxes[len(boxes)-1].raw) == 0 {
+			return nil, errAVIFIsInvalid
+		}
+		// 前4字节为tiff头的偏移
+		exifPayload = append(make([]byte, 4), m.Exif...)
+		id := meta.maxItemID() + 1
+		iinf, iref, err = meta.addExif(id, len(exifPayload))
+		if err != nil {
+			return nil, err
+		}
+		exifItem = meta.iloc.items[len(meta.iloc.items)-1]
+	} else {
+		iinf = findISOBox(meta.boxes, "iinf").raw
+		if box := findISOBox(meta.boxes, "iref"); box != nil {
+			iref = box.raw
+		}
+	}
+
+	// meta的长度变化后需要调整位于其后的数据的偏移
+	delta := len(meta.bytes(iinf, iref)) - len(boxes[metaIndex].raw)
+	err = meta.iloc.shift(uint64(metaEnd), int64(delta))
+	if err != nil {
+		return nil, err
+	}
+	if exifItem != nil {
+		// exif的数据添加至文件结尾的mdat
+		err = meta.iloc.setOffset(exifItem, uint64(len(data)+delta+8))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	w := bytes.NewBuffer(make([]byte, 0, len(data)+delta+len(exifPayload)+8))
+	for index, box := range boxes {
+		if index == metaIndex {
+			w.Write(meta.bytes(iinf, iref))
+			continue
+		}
+		w.Write(box.raw)
+	}
+	if exifItem != nil {
+		w.Write(newISOBox("mdat", exifPayload))
+	}
+	return w.Bytes(), nil
+}
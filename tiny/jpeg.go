@@ -18,12 +18,22 @@ import (
 	"bytes"
 	"context"
 	"image"
+	"image/color"
 	"image/jpeg"
 	"strconv"
 )
 
-// JPEGEncode jpeg encode
+// JPEGEncode jpeg encode, the image/jpeg is used if cjpeg is not found and fallback is enabled
 func JPEGEncode(ctx context.Context, img image.Image, quality int) (data []byte, err error) {
+	info, err := jpegEncode(ctx, img, quality)
+	if err != nil {
+		return
+	}
+	data = info.Data
+	return
+}
+
+func jpegEncode(ctx context.Context, img image.Image, quality int) (info *Image, err error) {
 	if quality <= minJPEGQuality || quality > maxJPEGQuality {
 		quality = defaultJEPGQuality
 	}
@@ -34,21 +44,105 @@ func JPEGEncode(ctx context.Context, img image.Image, quality int) (data []byte,
 	if err != nil {
 		return
 	}
-	fn := func(originalFile, targetFile string) []string {
-		return []string{
-			"cjpeg",
-			"-quality",
-			strconv.Itoa(quality),
-			"-outfile",
-			targetFile,
-			originalFile,
-		}
+	// cjpeg未指定文件时从stdin读取并输出至stdout
+	args := []string{
+		"cjpeg",
+		"-quality",
+		strconv.Itoa(quality),
 	}
 	fileBuffer := new(bytes.Buffer)
-	err = doCommandConvert(ctx, w.Bytes(), fn, fileBuffer)
+	err = doCommandPipe(ctx, w.Bytes(), args, fileBuffer)
+	if err != nil {
+		if !shouldFallback(err) {
+			return
+		}
+		// cjpeg不可用时使用image/jpeg编码的数据
+		info = &Image{
+			Data:    w.Bytes(),
+			Type:    EncodeTypeJPEG,
+			Encoder: EncoderGoJPEG,
+		}
+		err = nil
+		return
+	}
+	info = &Image{
+		Data:    fileBuffer.Bytes(),
+		Type:    EncodeTypeJPEG,
+		Encoder: EncoderCjpeg,
+	}
+	return
+}
+
+// adobe的APP14，transform为0（CMYK）
+var jpegAdobeSegment = []byte{0xff, 0xee, 0x00, 0x0e, 'A', 'd', 'o', 'b', 'e', 0x00, 0x64, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+// isJPEGSOF check the marker is SOF(start of frame)
+func isJPEGSOF(marker byte) bool {
+	switch marker {
+	// DHT, JPG与DAC
+	case 0xc4, 0xc8, 0xcc:
+		return false
+	}
+	return marker >= 0xc0 && marker <= 0xcf
+}
+
+// getJPEGColorInfo get the count of components and whether it has adobe segment
+func getJPEGColorInfo(data []byte) (components int, adobe bool) {
+	segments, _, err := parseJPEGSegments(data)
 	if err != nil {
 		return
 	}
-	data = fileBuffer.Bytes()
+	for _, s := range segments {
+		switch {
+		case s.marker == 0xee && bytes.HasPrefix(s.data, []byte("Adobe")):
+			adobe = true
+		case isJPEGSOF(s.marker) && len(s.data) > 5:
+			components = int(s.data[5])
+		}
+	}
 	return
 }
+
+// cmykToRGB convert the cmyk image to rgb, the inverted cmyk
+// should be inverted again before convert
+func cmykToRGB(img *image.CMYK, inverted bool) *image.NRGBA {
+	bounds := img.Bounds()
+	dst := image.NewNRGBA(bounds)
+	for y := 0; y < bounds.Dy(); y++ {
+		src := img.Pix[y*img.Stride : y*img.Stride+bounds.Dx()*4]
+		pix := dst.Pix[y*dst.Stride : y*dst.Stride+bounds.Dx()*4]
+		for i := 0; i < len(src); i += 4 {
+			c, m, yellow, k := src[i], src[i+1], src[i+2], src[i+3]
+			if inverted {
+				c, m, yellow, k = 255-c, 255-m, 255-yellow, 255-k
+			}
+			pix[i], pix[i+1], pix[i+2] = color.CMYKToRGB(c, m, yellow, k)
+			pix[i+3] = 255
+		}
+	}
+	return dst
+}
+
+// jpegDecode decode jpeg, the CMYK and YCCK jpeg will be converted to RGB
+func jpegDecode(data []byte) (image.Image, error) {
+	components, adobe := getJPEGColorInfo(data)
+	// image/jpeg不支持无adobe segment的4通道图片，添加transform为0的segment
+	// 以CMYK解码，由于此类图片未反转，解码后的数据需要再次反转
+	inverted := components == 4 && !adobe
+	if inverted {
+		buf := make([]byte, 0, len(data)+len(jpegAdobeSegment))
+		buf = append(buf, data[0:2]...)
+		buf = append(buf, jpegAdobeSegment...)
+		data = append(buf, data[2:]...)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	// adobe的CMYK（反转）与YCCK已由image/jpeg转换为CMYK
+	cmyk, ok := img.(*image.CMYK)
+	if !ok {
+		return img, nil
+	}
+	return cmykToRGB(cmyk, inverted), nil
+}
@@ -15,7 +15,9 @@
 package tiny
 
 import (
+	"bytes"
 	"context"
+	"image/color"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,3 +31,142 @@ func TestJPEGEncode(t *testing.T) {
 	assert.Nil(err)
 	assert.NotEqual(0, len(data))
 }
+
+// newTestFlatJPEG create the 8x8 baseline jpeg with 4 components,
+// each component is filled with the value, adobe transform < 0 means no adobe segment
+func newTestFlatJPEG(values [4]int, adobeTransform int) []byte {
+	buf := &bytes.Buffer{}
+	buf.Write([]byte{0xff, 0xd8})
+	if adobeTransform >= 0 {
+		buf.Write([]byte{0xff, 0xee, 0x00, 0x0e, 'A', 'd', 'o', 'b', 'e', 0x00, 0x64, 0x00, 0x00, 0x00, 0x00, byte(adobeTransform)})
+	}
+	// 量化表全为1
+	buf.Write([]byte{0xff, 0xdb, 0x00, 0x43, 0x00})
+	buf.Write(bytes.Repeat([]byte{1}, 64))
+	// 8x8，4个通道且无采样
+	buf.Write([]byte{0xff, 0xc0, 0x00, 0x14, 0x08, 0x00, 0x08, 0x00, 0x08, 0x04})
+	for i := 1; i <= 4; i++ {
+		buf.Write([]byte{byte(i), 0x11, 0x00})
+	}
+	// dc的12个类别均为4位，ac仅有EOB（1位）
+	buf.Write([]byte{0xff, 0xc4, 0x00, 0x1f, 0x00, 0, 0, 0, 12, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	for i := 0; i < 12; i++ {
+		buf.WriteByte(byte(i))
+	}
+	buf.Write([]byte{0xff, 0xc4, 0x00, 0x14, 0x10, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x00})
+	buf.Write([]byte{0xff, 0xda, 0x00, 0x0e, 0x04})
+	for i := 1; i <= 4; i++ {
+		buf.Write([]byte{byte(i), 0x00})
+	}
+	buf.Write([]byte{0x00, 0x3f, 0x00})
+
+	var acc, n uint
+	writeBits := func(code, size uint) {
+		for i := int(size) - 1; i >= 0; i-- {
+			acc = acc<<1 | (code>>uint(i))&1
+			n++
+			if n == 8 {
+				buf.WriteByte(byte(acc))
+				if byte(acc) == 0xff {
+					buf.WriteByte(0)
+				}
+				acc, n = 0, 0
+			}
+		}
+	}
+	for _, v := range values {
+		// 纯色block的DC为8*(v-128)
+		dc := 8 * (v - 128)
+		abs := dc
+		if abs < 0 {
+			abs = -abs
+		}
+		category := uint(0)
+		for ; abs != 0; abs >>= 1 {
+			category++
+		}
+		// 负数使用反码
+		if dc < 0 {
+			dc += 1<<category - 1
+		}
+		writeBits(category, 4)
+		writeBits(uint(dc), category)
+		// EOB
+		writeBits(0, 1)
+	}
+	for n != 0 {
+		writeBits(1, 1)
+	}
+	buf.Write([]byte{0xff, 0xd9})
+	return buf.Bytes()
+}
+
+func TestJPEGDecode(t *testing.T) {
+	tests := []struct {
+		name           string
+		values         [4]int
+		adobeTransform int
+		result         color.NRGBA
+	}{
+		{
+			name: "adobe cmyk(inverted)",
+			// cyan
+			values:         [4]int{0, 255, 255, 255},
+			adobeTransform: 0,
+			result:         color.NRGBA{0, 255, 255, 255},
+		},
+		{
+			name: "cmyk without adobe",
+			// magenta
+			values:         [4]int{0, 255, 0, 0},
+			adobeTransform: -1,
+			result:         color.NRGBA{255, 0, 255, 255},
+		},
+		{
+			name: "ycck",
+			// gray and no black
+			values:         [4]int{128, 128, 128, 255},
+			adobeTransform: 2,
+			result:         color.NRGBA{128, 128, 128, 255},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			data := newTestFlatJPEG(tt.values, tt.adobeTransform)
+			components, adobe := getJPEGColorInfo(data)
+			assert.Equal(4, components)
+			assert.Equal(tt.adobeTransform >= 0, adobe)
+			for _, sourceType := range []EncodeType{EncodeTypeJPEG, EncodeTypeUnknown} {
+				img, err := imageDecode(data, sourceType)
+				assert.Nil(err)
+				c := color.NRGBAModel.Convert(img.At(4, 4)).(color.NRGBA)
+				assert.InDelta(int(tt.result.R), int(c.R), 2)
+				assert.InDelta(int(tt.result.G), int(c.G), 2)
+				assert.InDelta(int(tt.result.B), int(c.B), 2)
+				assert.Equal(uint8(255), c.A)
+			}
+		})
+	}
+}
+
+func TestJPEGDecodeKeepMetadata(t *testing.T) {
+	assert := assert.New(t)
+	// cmyk的icc在转换为RGB后不再适用
+	cmykICC := make([]byte, 132)
+	copy(cmykICC[16:], "CMYK")
+	data, err := writeMetadata(newTestFlatJPEG([4]int{0, 255, 255, 255}, 0), EncodeTypeJPEG, &metadata{
+		ICC: cmykICC,
+	})
+	assert.Nil(err)
+	assert.Empty(readMetadata(data, EncodeTypeJPEG).ICC)
+
+	for _, policy := range []MetadataPolicy{MetadataKeep, MetadataKeepICC} {
+		img, err := ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+			Output:   EncodeTypeJPEG,
+			Metadata: policy,
+		})
+		assert.Nil(err)
+		assert.Empty(readMetadata(img.Data, EncodeTypeJPEG).ICC)
+	}
+}
@@ -15,16 +15,170 @@
 package tiny
 
 import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
 	"github.com/pierrec/lz4"
 )
 
-// Lz4Encode lz4 encode
+// the min length of match, the match length of token is added to it
+const lz4MinMatch = 4
+
+// lz4CompressionLevel convert the quality(same as lz4 cli, 1-12) to compression level,
+// 1-2 use the fast compression, and others use the high compression with search depth
+func lz4CompressionLevel(quality int) int {
+	if quality < minLz4Quality || quality > maxLz4Quality {
+		quality = defaultLz4Quality
+	}
+	if quality <= 2 {
+		return 0
+	}
+	// 3: 128, 12: 65536
+	return 1 << (quality + 4)
+}
+
+// NewLz4Writer create a lz4 writer of frame format with checksums
+func NewLz4Writer(w io.Writer, quality int) (io.WriteCloser, error) {
+	zw := lz4.NewWriter(w)
+	zw.Header = lz4.Header{
+		BlockChecksum:    true,
+		CompressionLevel: lz4CompressionLevel(quality),
+	}
+	return zw, nil
+}
+
+// NewLz4Reader create a lz4 reader of frame format
+func NewLz4Reader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(lz4.NewReader(r)), nil
+}
+
+// Lz4Encode lz4 encode, the data is encoded to frame format,
+// which can be decoded by the standard lz4 tools
 func Lz4Encode(data []byte, quality int) ([]byte, error) {
-	buf := make([]byte, len(data))
-	n, err := lz4.CompressBlock(data, buf, nil)
+	buffer := new(bytes.Buffer)
+	w, err := NewLz4Writer(buffer, quality)
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Lz4Decode lz4 decode of frame format
+func Lz4Decode(data []byte) ([]byte, error) {
+	r, err := NewLz4Reader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return readAllWithLimit(r)
+}
+
+// Lz4BlockEncode lz4 encode to raw block, the block has no length header
+func Lz4BlockEncode(data []byte, quality int) ([]byte, error) {
+	// 缓存大小为CompressBlockBound，不可压缩的数据也能正常生成
+	buf := make([]byte, lz4.CompressBlockBound(len(data)))
+	var n int
+	var err error
+	if level := lz4CompressionLevel(quality); level != 0 {
+		n, err = lz4.CompressBlockHC(data, buf, level)
+	} else {
+		n, err = lz4.CompressBlock(data, buf, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// readLz4Length read the extended length of literals or match
+func readLz4Length(data []byte, offset, length int) (int, int, error) {
+	if length != 0x0F {
+		return offset, length, nil
+	}
+	for {
+		if offset >= len(data) {
+			return 0, 0, lz4.ErrInvalidSourceShortBuffer
+		}
+		v := data[offset]
+		offset++
+		length += int(v)
+		if length > maxDecodeSize {
+			return 0, 0, ErrDecodeSizeExceeded
+		}
+		if v != 0xFF {
+			return offset, length, nil
+		}
+	}
+}
+
+// lz4BlockSize get the decoded size of raw block by walking its sequences,
+// the corrupt block is rejected without decoding
+func lz4BlockSize(data []byte) (int, error) {
+	size := 0
+	offset := 0
+	for offset < len(data) {
+		token := data[offset]
+		// 字面量
+		var literals, match int
+		var err error
+		offset, literals, err = readLz4Length(data, offset+1, int(token>>4))
+		if err != nil {
+			return 0, err
+		}
+		offset += literals
+		size += literals
+		if offset > len(data) {
+			return 0, lz4.ErrInvalidSourceShortBuffer
+		}
+		// 最后的sequence只有字面量
+		if offset == len(data) {
+			break
+		}
+		if offset+2 > len(data) {
+			return 0, lz4.ErrInvalidSourceShortBuffer
+		}
+		// 匹配的偏移不能为0且不能超出已解压的数据
+		matchOffset := int(binary.LittleEndian.Uint16(data[offset:]))
+		if matchOffset == 0 || matchOffset > size {
+			return 0, lz4.ErrInvalidSourceShortBuffer
+		}
+		offset, match, err = readLz4Length(data, offset+2, int(token&0x0F))
+		if err != nil {
+			return 0, err
+		}
+		size += match + lz4MinMatch
+		if size > maxDecodeSize {
+			return 0, ErrDecodeSizeExceeded
+		}
+	}
+	if size > maxDecodeSize {
+		return 0, ErrDecodeSizeExceeded
+	}
+	return size, nil
+}
+
+// Lz4BlockDecode lz4 decode of raw block
+func Lz4BlockDecode(data []byte) ([]byte, error) {
+	// lz4的block数据并未记录原始数据长度，先根据sequence计算长度再解压
+	size, err := lz4BlockSize(data)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return []byte{}, nil
+	}
+	buf := make([]byte, size)
+	n, err := lz4.UncompressBlock(data, buf)
 	if err != nil {
 		return nil, err
 	}
-	buf = buf[:n]
-	return buf, nil
+	return buf[:n], nil
 }
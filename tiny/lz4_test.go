@@ -15,8 +15,10 @@
 package tiny
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/pierrec/lz4"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,3 +29,71 @@ func TestLz4Encode(t *testing.T) {
 	assert.Nil(err)
 	assert.NotNil(buf)
 }
+
+func TestLz4Decode(t *testing.T) {
+	assert := assert.New(t)
+	originalBuf := bytes.Repeat([]byte("abcd"), 1024)
+	for _, quality := range []int{0, 1, 3, 12} {
+		buf, err := Lz4Encode(originalBuf, quality)
+		assert.Nil(err)
+		assert.True(len(buf) < len(originalBuf))
+		data, err := Lz4Decode(buf)
+		assert.Nil(err)
+		assert.Equal(originalBuf, data)
+	}
+
+	// 不可压缩的数据
+	originalBuf = []byte("abcd")
+	buf, err := Lz4Encode(originalBuf, 0)
+	assert.Nil(err)
+	data, err := Lz4Decode(buf)
+	assert.Nil(err)
+	assert.Equal(originalBuf, data)
+}
+
+func TestLz4Block(t *testing.T) {
+	assert := assert.New(t)
+	for _, originalBuf := range [][]byte{
+		[]byte("abcd"),
+		bytes.Repeat([]byte("abcd"), 1024),
+	} {
+		for _, quality := range []int{0, 12} {
+			buf, err := Lz4BlockEncode(originalBuf, quality)
+			assert.Nil(err)
+			assert.NotEqual(0, len(buf))
+			data, err := Lz4BlockDecode(buf)
+			assert.Nil(err)
+			assert.Equal(originalBuf, data)
+		}
+	}
+}
+
+func TestLz4BlockSize(t *testing.T) {
+	assert := assert.New(t)
+	originalBuf := bytes.Repeat([]byte("abcd"), 1024)
+	buf, err := Lz4BlockEncode(originalBuf, 0)
+	assert.Nil(err)
+	size, err := lz4BlockSize(buf)
+	assert.Nil(err)
+	assert.Equal(len(originalBuf), size)
+
+	// 数据不完整
+	_, err = Lz4BlockDecode(buf[:len(buf)-1])
+	assert.Equal(lz4.ErrInvalidSourceShortBuffer, err)
+
+	buf, err = Lz4BlockEncode(nil, 0)
+	assert.Nil(err)
+	data, err := Lz4BlockDecode(buf)
+	assert.Nil(err)
+	assert.Empty(data)
+	// 字面量长度超出数据
+	_, err = Lz4BlockDecode([]byte{0xF0, 0xFF, 0xFF, 0x10})
+	assert.Equal(lz4.ErrInvalidSourceShortBuffer, err)
+	// 匹配的偏移超出已解压的数据
+	_, err = Lz4BlockDecode([]byte{0x10, 'a', 0x02, 0x00, 0x00})
+	assert.Equal(lz4.ErrInvalidSourceShortBuffer, err)
+	// 声明的匹配长度超出限制，无需解压即可判断
+	corrupt := append([]byte{0x1F, 'a', 0x01, 0x00}, bytes.Repeat([]byte{0xFF}, maxDecodeSize/255+1)...)
+	_, err = Lz4BlockDecode(corrupt)
+	assert.Equal(ErrDecodeSizeExceeded, err)
+}
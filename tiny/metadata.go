@@ -0,0 +1,661 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"sort"
+)
+
+// MetadataPolicy the policy of image metadata(exif and icc profile)
+type MetadataPolicy int
+
+const (
+	// MetadataStrip remove all metadata
+	MetadataStrip MetadataPolicy = iota
+	// MetadataKeep keep the exif and icc profile
+	MetadataKeep
+	// MetadataKeepICC keep the icc profile only
+	MetadataKeepICC
+	// MetadataKeepCopyright keep the copyright and artist of exif, and the icc profile
+	MetadataKeepCopyright
+)
+
+const (
+	// jpeg的segment最大长度（不包括长度字段）
+	maxJPEGSegmentSize = 65533
+	// ICC_PROFILE头与序号占用14字节
+	maxJPEGICCChunkSize = maxJPEGSegmentSize - 14
+	// icc的最大长度，png的icc为压缩数据，避免解压过大的数据
+	maxICCSize = 4 * 1024 * 1024
+	// tiff中icc的tag
+	tiffICCTag = 0x8773
+)
+
+var (
+	jpegICCHeader = []byte("ICC_PROFILE\x00")
+	jpegXMPHeader = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+
+	errJPEGIsInvalid = errors.New("jpeg data is invalid")
+	errPNGIsInvalid  = errors.New("png data is invalid")
+	errWebpIsInvalid = errors.New("webp data is invalid")
+)
+
+func (p MetadataPolicy) String() string {
+	switch p {
+	case MetadataKeep:
+		return "keep"
+	case MetadataKeepICC:
+		return "keep-icc"
+	case MetadataKeepCopyright:
+		return "keep-copyright"
+	default:
+		return "strip"
+	}
+}
+
+// ConvertToMetadataPolicy convert to metadata policy, strip is returned for unknown value
+func ConvertToMetadataPolicy(v string) MetadataPolicy {
+	switch v {
+	case "keep":
+		return MetadataKeep
+	case "keep-icc":
+		return MetadataKeepICC
+	case "keep-copyright":
+		return MetadataKeepCopyright
+	default:
+		return MetadataStrip
+	}
+}
+
+// metadata the metadata of image
+type metadata struct {
+	// Exif the exif(tiff) data
+	Exif []byte
+	// ICC the icc profile
+	ICC []byte
+}
+
+// isEmpty check the metadata is empty
+func (m *metadata) isEmpty() bool {
+	return m == nil || (len(m.Exif) == 0 && len(m.ICC) == 0)
+}
+
+// filter filter the metadata by policy, the orientation of exif will be
+// reset if the image has been oriented
+func (m *metadata) filter(policy MetadataPolicy, oriented bool) *metadata {
+	if m == nil {
+		return nil
+	}
+	switch policy {
+	case MetadataKeep:
+		exif := m.Exif
+		if oriented {
+			exif = setExifOrientation(exif, OrientationNormal)
+		}
+		return &metadata{
+			Exif: exif,
+			ICC:  m.ICC,
+		}
+	case MetadataKeepICC:
+		return &metadata{
+			ICC: m.ICC,
+		}
+	case MetadataKeepCopyright:
+		return &metadata{
+			Exif: newCopyrightExif(m.Exif),
+			ICC:  m.ICC,
+		}
+	default:
+		return nil
+	}
+}
+
+// detectImageType detect the type of image by the magic bytes
+func detectImageType(data []byte) EncodeType {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xff\xd8")):
+		return EncodeTypeJPEG
+	case bytes.HasPrefix(data, pngSignature):
+		return EncodeTypePNG
+	case bytes.HasPrefix(data, []byte("GIF87a")) || bytes.HasPrefix(data, []byte("GIF89a")):
+		return EncodeTypeGIF
+	case bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*")):
+		return EncodeTypeTIFF
+	case bytes.HasPrefix(data, []byte("BM")):
+		return EncodeTypeBMP
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return EncodeTypeWEBP
+	case len(data) >= 12 && string(data[4:8]) == "ftyp" && (string(data[8:12]) == "avif" || string(data[8:12]) == "avis"):
+		return EncodeTypeAVIF
+	default:
+		return EncodeTypeUnknown
+	}
+}
+
+// readMetadata read the metadata of image, the invalid metadata will be ignored
+func readMetadata(data []byte, t EncodeType) *metadata {
+	if t == EncodeTypeUnknown {
+		t = detectImageType(data)
+	}
+	switch t {
+	case EncodeTypeJPEG:
+		return readJPEGMetadata(data)
+	case EncodeTypePNG:
+		return readPNGMetadata(data)
+	case EncodeTypeWEBP:
+		return readWebpMetadata(data)
+	case EncodeTypeAVIF:
+		return readAVIFMetadata(data)
+	case EncodeTypeTIFF:
+		return readTIFFMetadata(data)
+	default:
+		return &metadata{}
+	}
+}
+
+// readTIFFMetadata read the metadata of tiff, the tags of ifd0 are the same as
+// exif, so the orientation, artist and copyright are copied to a new exif
+func readTIFFMetadata(data []byte) *metadata {
+	m := &metadata{}
+	entries := make([]*exifEntry, 0, 3)
+	// ifd中的tag需要按升序排列
+	if orientation := readExifOrientation(data); orientation != 0 {
+		entries = append(entries, &exifEntry{
+			tag:   exifOrientationTag,
+			typ:   exifTypeShort,
+			count: 1,
+			// newExif使用big endian
+			value: []byte{0, byte(orientation)},
+		})
+	}
+	for _, tag := range []uint16{exifArtistTag, exifCopyrightTag} {
+		value := readExifASCII(data, tag)
+		if len(value) == 0 {
+			continue
+		}
+		entries = append(entries, &exifEntry{
+			tag:   tag,
+			typ:   exifTypeASCII,
+			count: uint32(len(value)),
+			value: value,
+		})
+	}
+	if len(entries) != 0 {
+		m.Exif = newExif(entries)
+	}
+	if icc := readExifBytes(data, tiffICCTag, exifTypeUndefined); len(icc) <= maxICCSize {
+		m.ICC = icc
+	}
+	return m
+}
+
+// writeMetadata remove the metadata of image and write the new metadata,
+// the data of other types will be returned directly
+func writeMetadata(data []byte, t EncodeType, m *metadata) ([]byte, error) {
+	switch t {
+	case EncodeTypeJPEG:
+		return writeJPEGMetadata(data, m)
+	case EncodeTypePNG:
+		return writePNGMetadata(data, m)
+	case EncodeTypeWEBP:
+		return writeWebpMetadata(data, m)
+	case EncodeTypeAVIF:
+		// avif编码器不会写入元数据，因此仅需添加
+		if m.isEmpty() {
+			return data, nil
+		}
+		return writeAVIFMetadata(data, m)
+	default:
+		return data, nil
+	}
+}
+
+// jpegSegment the marker segment of jpeg
+type jpegSegment struct {
+	marker byte
+	data   []byte
+}
+
+// parseJPEGSegments parse the segments before SOS,
+// it returns the segments and the offset of SOS
+func parseJPEGSegments(data []byte) ([]*jpegSegment, int, error) {
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil, 0, errJPEGIsInvalid
+	}
+	segments := make([]*jpegSegment, 0)
+	offset := 2
+	for {
+		// marker之前可以有多个0xff填充
+		for offset+1 < len(data) && data[offset] == 0xff && data[offset+1] == 0xff {
+			offset++
+		}
+		if offset+4 > len(data) || data[offset] != 0xff {
+			return nil, 0, errJPEGIsInvalid
+		}
+		marker := data[offset+1]
+		// SOS之后为图像数据
+		if marker == 0xda || marker == 0xd9 {
+			return segments, offset, nil
+		}
+		size := int(binary.BigEndian.Uint16(data[offset+2:]))
+		end := offset + 2 + size
+		if size < 2 || end > len(data) {
+			return nil, 0, errJPEGIsInvalid
+		}
+		segments = append(segments, &jpegSegment{
+			marker: marker,
+			data:   data[offset+4 : end],
+		})
+		offset = end
+	}
+}
+
+// isMetadata check the segment is metadata(exif, xmp, icc or iptc)
+func (s *jpegSegment) isMetadata() bool {
+	switch s.marker {
+	case 0xe1:
+		return bytes.HasPrefix(s.data, exifHeader) || bytes.HasPrefix(s.data, jpegXMPHeader)
+	case 0xe2:
+		return bytes.HasPrefix(s.data, jpegICCHeader)
+	case 0xed:
+		// photoshop iptc
+		return true
+	default:
+		return false
+	}
+}
+
+func readJPEGMetadata(data []byte) *metadata {
+	m := &metadata{}
+	segments, _, err := parseJPEGSegments(data)
+	if err != nil {
+		return m
+	}
+	iccChunks := make(map[byte][]byte)
+	for _, s := range segments {
+		switch {
+		case s.marker == 0xe1 && bytes.HasPrefix(s.data, exifHeader):
+			if m.Exif == nil {
+				m.Exif = s.data[len(exifHeader):]
+			}
+		case s.marker == 0xe2 && bytes.HasPrefix(s.data, jpegICCHeader):
+			// 序号（从1开始）与总数各占1字节
+			if len(s.data) > len(jpegICCHeader)+2 {
+				iccChunks[s.data[len(jpegICCHeader)]] = s.data[len(jpegICCHeader)+2:]
+			}
+		}
+	}
+	// CMYK(YCCK)的图片解码时已转换为RGB，其icc不可再使用
+	if components, _ := getJPEGColorInfo(data); components == 4 {
+		iccChunks = nil
+	}
+	if len(iccChunks) != 0 {
+		seqs := make([]int, 0, len(iccChunks))
+		for seq := range iccChunks {
+			seqs = append(seqs, int(seq))
+		}
+		sort.Ints(seqs)
+		icc := make([]byte, 0)
+		for _, seq := range seqs {
+			icc = append(icc, iccChunks[byte(seq)]...)
+		}
+		m.ICC = icc
+	}
+	return m
+}
+
+func writeJPEGSegment(w *bytes.Buffer, marker byte, data ...[]byte) {
+	size := 2
+	for _, item := range data {
+		size += len(item)
+	}
+	w.Write([]byte{0xff, marker, byte(size >> 8), byte(size)})
+	for _, item := range data {
+		w.Write(item)
+	}
+}
+
+func writeJPEGMetadata(data []byte, m *metadata) ([]byte, error) {
+	segments, sos, err := parseJPEGSegments(data)
+	if err != nil {
+		return nil, err
+	}
+	w := bytes.NewBuffer(make([]byte, 0, len(data)))
+	w.Write(data[0:2])
+	// JFIF需要为第一个segment
+	if len(segments) != 0 && segments[0].marker == 0xe0 {
+		writeJPEGSegment(w, segments[0].marker, segments[0].data)
+		segments = segments[1:]
+	}
+	if m != nil {
+		// 超过segment长度的exif无法写入
+		if len(m.Exif) != 0 && len(m.Exif)+len(exifHeader) <= maxJPEGSegmentSize {
+			writeJPEGSegment(w, 0xe1, exifHeader, m.Exif)
+		}
+		count := (len(m.ICC) + maxJPEGICCChunkSize - 1) / maxJPEGICCChunkSize
+		// 序号仅占1字节
+		if count < 256 {
+			for i := 0; i < count; i++ {
+				end := (i + 1) * maxJPEGICCChunkSize
+				if end > len(m.ICC) {
+					end = len(m.ICC)
+				}
+				writeJPEGSegment(w, 0xe2, jpegICCHeader, []byte{byte(i + 1), byte(count)}, m.ICC[i*maxJPEGICCChunkSize:end])
+			}
+		}
+	}
+	for _, s := range segments {
+		if s.isMetadata() {
+			continue
+		}
+		writeJPEGSegment(w, s.marker, s.data)
+	}
+	w.Write(data[sos:])
+	return w.Bytes(), nil
+}
+
+// pngChunk the chunk of png
+type pngChunk struct {
+	name string
+	data []byte
+}
+
+// parsePNGChunks parse the chunks of png
+func parsePNGChunks(data []byte) ([]*pngChunk, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errPNGIsInvalid
+	}
+	chunks := make([]*pngChunk, 0)
+	offset := len(pngSignature)
+	for offset+12 <= len(data) {
+		size := int(binary.BigEndian.Uint32(data[offset:]))
+		end := offset + 8 + size
+		// 包括4字节的crc
+		if size < 0 || end+4 > len(data) {
+			return nil, errPNGIsInvalid
+		}
+		chunk := &pngChunk{
+			name: string(data[offset+4 : offset+8]),
+			data: data[offset+8 : end],
+		}
+		chunks = append(chunks, chunk)
+		offset = end + 4
+		if chunk.name == "IEND" {
+			return chunks, nil
+		}
+	}
+	return nil, errPNGIsInvalid
+}
+
+// isMetadata check the chunk is metadata(exif, icc or text)
+func (c *pngChunk) isMetadata() bool {
+	switch c.name {
+	case "eXIf", "iCCP", "tEXt", "zTXt", "iTXt", "tIME":
+		return true
+	default:
+		return false
+	}
+}
+
+func writePNGChunk(w *bytes.Buffer, name string, data []byte) {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	w.Write(buf)
+	crc := crc32.NewIEEE()
+	_, _ = crc.Write([]byte(name))
+	_, _ = crc.Write(data)
+	w.WriteString(name)
+	w.Write(data)
+	binary.BigEndian.PutUint32(buf, crc.Sum32())
+	w.Write(buf)
+}
+
+func readPNGMetadata(data []byte) *metadata {
+	m := &metadata{}
+	chunks, err := parsePNGChunks(data)
+	if err != nil {
+		return m
+	}
+	for _, c := range chunks {
+		switch c.name {
+		case "eXIf":
+			m.Exif = c.data
+		case "iCCP":
+			// 名称以0结尾，其后为压缩方式（仅支持zlib）与压缩数据
+			index := bytes.IndexByte(c.data, 0)
+			if index < 0 || index+2 > len(c.data) {
+				continue
+			}
+			r, err := zlib.NewReader(bytes.NewReader(c.data[index+2:]))
+			if err != nil {
+				continue
+			}
+			// 限制解压后的大小，超出的icc忽略
+			icc, err := io.ReadAll(io.LimitReader(r, maxICCSize+1))
+			if err != nil || len(icc) > maxICCSize {
+				continue
+			}
+			m.ICC = icc
+		}
+	}
+	return m
+}
+
+func writePNGMetadata(data []byte, m *metadata) ([]byte, error) {
+	chunks, err := parsePNGChunks(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 || chunks[0].name != "IHDR" {
+		return nil, errPNGIsInvalid
+	}
+	w := bytes.NewBuffer(make([]byte, 0, len(data)))
+	w.Write(pngSignature)
+	writePNGChunk(w, chunks[0].name, chunks[0].data)
+	hasICC := m != nil && len(m.ICC) != 0
+	if hasICC {
+		iccp := bytes.NewBufferString("icc\x00\x00")
+		zw := zlib.NewWriter(iccp)
+		_, err = zw.Write(m.ICC)
+		if err == nil {
+			err = zw.Close()
+		}
+		if err != nil {
+			return nil, err
+		}
+		writePNGChunk(w, "iCCP", iccp.Bytes())
+	}
+	if m != nil && len(m.Exif) != 0 {
+		writePNGChunk(w, "eXIf", m.Exif)
+	}
+	for _, c := range chunks[1:] {
+		// iCCP与sRGB不能同时存在
+		if c.isMetadata() || (hasICC && c.name == "sRGB") {
+			continue
+		}
+		writePNGChunk(w, c.name, c.data)
+	}
+	return w.Bytes(), nil
+}
+
+const (
+	webpFlagICC       = 0x20
+	webpFlagAlpha     = 0x10
+	webpFlagEXIF      = 0x08
+	webpFlagXMP       = 0x04
+	webpFlagAnimation = 0x02
+)
+
+// riffChunk the chunk of riff(webp)
+type riffChunk struct {
+	name string
+	data []byte
+}
+
+// parseWebpChunks parse the chunks of webp
+func parseWebpChunks(data []byte) ([]*riffChunk, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errWebpIsInvalid
+	}
+	chunks := make([]*riffChunk, 0)
+	offset := 12
+	for offset+8 <= len(data) {
+		size := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		end := offset + 8 + size
+		if size < 0 || end > len(data) {
+			return nil, errWebpIsInvalid
+		}
+		chunks = append(chunks, &riffChunk{
+			name: string(data[offset : offset+4]),
+			data: data[offset+8 : end],
+		})
+		// chunk的长度为偶数
+		offset = end + size%2
+	}
+	return chunks, nil
+}
+
+// getWebpCanvasSize get the canvas size of simple format(VP8/VP8L) webp
+func getWebpCanvasSize(c *riffChunk) (width, height int, alpha, ok bool) {
+	switch c.name {
+	case "VP8 ":
+		// 3字节frame tag与3字节start code之后为14位的宽高
+		if len(c.data) < 10 {
+			return
+		}
+		width = int(binary.LittleEndian.Uint16(c.data[6:]) & 0x3fff)
+		height = int(binary.LittleEndian.Uint16(c.data[8:]) & 0x3fff)
+		ok = true
+	case "VP8L":
+		// 1字节signature之后为14位的宽高（减1）以及1位的alpha
+		if len(c.data) < 5 || c.data[0] != 0x2f {
+			return
+		}
+		bits := binary.LittleEndian.Uint32(c.data[1:])
+		width = int(bits&0x3fff) + 1
+		height = int((bits>>14)&0x3fff) + 1
+		alpha = bits&(1<<28) != 0
+		ok = true
+	}
+	return
+}
+
+func readWebpMetadata(data []byte) *metadata {
+	m := &metadata{}
+	chunks, err := parseWebpChunks(data)
+	if err != nil {
+		return m
+	}
+	for _, c := range chunks {
+		switch c.name {
+		case "EXIF":
+			// 部分实现包括Exif头
+			m.Exif = bytes.TrimPrefix(c.data, exifHeader)
+		case "ICCP":
+			m.ICC = c.data
+		}
+	}
+	return m
+}
+
+func writeRIFFChunk(w *bytes.Buffer, name string, data []byte) {
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, uint32(len(data)))
+	w.WriteString(name)
+	w.Write(buf)
+	w.Write(data)
+	if len(data)%2 != 0 {
+		w.WriteByte(0)
+	}
+}
+
+func writeWebpMetadata(data []byte, m *metadata) ([]byte, error) {
+	chunks, err := parseWebpChunks(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, errWebpIsInvalid
+	}
+	hasICC := m != nil && len(m.ICC) != 0
+	hasExif := m != nil && len(m.Exif) != 0
+
+	var vp8x []byte
+	if chunks[0].name == "VP8X" {
+		if len(chunks[0].data) < 10 {
+			return nil, errWebpIsInvalid
+		}
+		vp8x = append([]byte{}, chunks[0].data...)
+		chunks = chunks[1:]
+	} else if hasICC || hasExif {
+		// 简单格式需要转换为扩展格式才可写入元数据
+		width, height, alpha, ok := getWebpCanvasSize(chunks[0])
+		if !ok {
+			return nil, errWebpIsInvalid
+		}
+		vp8x = make([]byte, 10)
+		if alpha {
+			vp8x[0] |= webpFlagAlpha
+		}
+		putUint24LE(vp8x[4:], uint32(width-1))
+		putUint24LE(vp8x[7:], uint32(height-1))
+	}
+
+	w := bytes.NewBuffer(make([]byte, 0, len(data)))
+	w.WriteString("RIFF")
+	// 文件长度最后再更新
+	w.Write(make([]byte, 4))
+	w.WriteString("WEBP")
+	if vp8x != nil {
+		vp8x[0] &^= webpFlagICC | webpFlagEXIF | webpFlagXMP
+		if hasICC {
+			vp8x[0] |= webpFlagICC
+		}
+		if hasExif {
+			vp8x[0] |= webpFlagEXIF
+		}
+		writeRIFFChunk(w, "VP8X", vp8x)
+	}
+	// ICCP需要在图像数据之前，EXIF则在之后
+	if hasICC {
+		writeRIFFChunk(w, "ICCP", m.ICC)
+	}
+	for _, c := range chunks {
+		switch c.name {
+		case "ICCP", "EXIF", "XMP ":
+			continue
+		}
+		writeRIFFChunk(w, c.name, c.data)
+	}
+	if hasExif {
+		writeRIFFChunk(w, "EXIF", m.Exif)
+	}
+	result := w.Bytes()
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+	return result, nil
+}
+
+func putUint24LE(b []byte, v uint32) {
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+}
@@ -0,0 +1,316 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testICC = bytes.Repeat([]byte("icc profile "), 10)
+
+// newTestFullExif create the exif data with orientation, artist and copyright
+func newTestFullExif() []byte {
+	orientation := make([]byte, 2)
+	binary.BigEndian.PutUint16(orientation, 6)
+	return newExif([]*exifEntry{
+		{
+			tag:   exifOrientationTag,
+			typ:   exifTypeShort,
+			count: 1,
+			value: orientation,
+		},
+		{
+			tag:   exifArtistTag,
+			typ:   exifTypeASCII,
+			count: 5,
+			value: []byte("tree\x00"),
+		},
+		{
+			tag:   exifCopyrightTag,
+			typ:   exifTypeASCII,
+			count: 8,
+			value: []byte("tiny.io\x00"),
+		},
+	})
+}
+
+func TestConvertToMetadataPolicy(t *testing.T) {
+	assert := assert.New(t)
+	for _, policy := range []MetadataPolicy{
+		MetadataStrip,
+		MetadataKeep,
+		MetadataKeepICC,
+		MetadataKeepCopyright,
+	} {
+		assert.Equal(policy, ConvertToMetadataPolicy(policy.String()))
+	}
+	assert.Equal(MetadataStrip, ConvertToMetadataPolicy("unknown"))
+}
+
+func TestMetadataFilter(t *testing.T) {
+	assert := assert.New(t)
+	m := &metadata{
+		Exif: newTestFullExif(),
+		ICC:  testICC,
+	}
+
+	result := m.filter(MetadataKeep, true)
+	assert.Equal(testICC, result.ICC)
+	assert.Equal(OrientationNormal, readExifOrientation(result.Exif))
+	// 未旋转则保留原有方向
+	assert.Equal(6, readExifOrientation(m.filter(MetadataKeep, false).Exif))
+
+	result = m.filter(MetadataKeepICC, true)
+	assert.Equal(testICC, result.ICC)
+	assert.Nil(result.Exif)
+
+	result = m.filter(MetadataKeepCopyright, true)
+	assert.Equal(testICC, result.ICC)
+	assert.Equal(0, readExifOrientation(result.Exif))
+	assert.Equal("tree\x00", string(readExifASCII(result.Exif, exifArtistTag)))
+	assert.Equal("tiny.io\x00", string(readExifASCII(result.Exif, exifCopyrightTag)))
+
+	assert.True(m.filter(MetadataStrip, true).isEmpty())
+}
+
+func TestWriteMetadata(t *testing.T) {
+	ctx := context.Background()
+	img := getTestImage()
+	m := &metadata{
+		Exif: newTestFullExif(),
+		ICC:  testICC,
+	}
+	jpegData, err := JPEGEncode(ctx, img, 80)
+	if err != nil {
+		panic(err)
+	}
+	pngData := &bytes.Buffer{}
+	err = png.Encode(pngData, img)
+	if err != nil {
+		panic(err)
+	}
+	lossyWebp, err := WEBPEncode(img, 80)
+	if err != nil {
+		panic(err)
+	}
+	losslessWebp, err := WEBPEncode(img, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	tests := []struct {
+		name string
+		t    EncodeType
+		data []byte
+	}{
+		{
+			name: "jpeg",
+			t:    EncodeTypeJPEG,
+			data: jpegData,
+		},
+		{
+			name: "png",
+			t:    EncodeTypePNG,
+			data: pngData.Bytes(),
+		},
+		{
+			name: "lossy webp",
+			t:    EncodeTypeWEBP,
+			data: lossyWebp,
+		},
+		{
+			name: "lossless webp",
+			t:    EncodeTypeWEBP,
+			data: losslessWebp,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			data, err := writeMetadata(tt.data, tt.t, m)
+			assert.Nil(err)
+			result := readMetadata(data, tt.t)
+			assert.Equal(m.Exif, result.Exif)
+			assert.Equal(m.ICC, result.ICC)
+			decoded, err := imageDecode(data, tt.t)
+			assert.Nil(err)
+			assert.Equal(img.Bounds(), decoded.Bounds())
+
+			// strip后不包括元数据
+			data, err = writeMetadata(data, tt.t, nil)
+			assert.Nil(err)
+			assert.True(readMetadata(data, tt.t).isEmpty())
+			_, err = imageDecode(data, tt.t)
+			assert.Nil(err)
+		})
+	}
+
+	t.Run("large icc of jpeg", func(t *testing.T) {
+		assert := assert.New(t)
+		icc := bytes.Repeat([]byte("0123456789"), 10000)
+		data, err := writeMetadata(jpegData, EncodeTypeJPEG, &metadata{
+			ICC: icc,
+		})
+		assert.Nil(err)
+		assert.Equal(icc, readMetadata(data, EncodeTypeJPEG).ICC)
+	})
+}
+
+// newTestAVIF create the avif(only the structure of isobmff) which has a primary item
+func newTestAVIF(payload []byte) []byte {
+	ftyp := newISOBox("ftyp", []byte("avif\x00\x00\x00\x00avifmif1"))
+	hdlr := newISOBox("hdlr", make([]byte, 8), []byte("pict"), make([]byte, 13))
+	pitm := newISOBox("pitm", []byte{0, 0, 0, 0, 0, 1})
+	infe := newISOBox("infe", []byte{2, 0, 0, 0, 0, 1, 0, 0}, []byte("av01\x00"))
+	iinf := newISOBox("iinf", []byte{0, 0, 0, 0, 0, 1}, infe)
+	ispe := newISOBox("ispe", make([]byte, 4), []byte{0, 0, 0, 80, 0, 0, 0, 40})
+	ipma := newISOBox("ipma", []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 1, 1, 0x01})
+	iprp := newISOBox("iprp", newISOBox("ipco", ispe), ipma)
+	build := func(offset int) []byte {
+		iloc := &isoWriter{}
+		iloc.write(4, 0)
+		iloc.write(1, 0x44)
+		iloc.write(1, 0)
+		iloc.write(2, 1)
+		iloc.write(2, 1)
+		iloc.write(2, 0)
+		iloc.write(2, 1)
+		iloc.write(4, uint64(offset))
+		iloc.write(4, uint64(len(payload)))
+		meta := newISOBox("meta", make([]byte, 4), hdlr, pitm, newISOBox("iloc", iloc.Bytes()), iinf, iprp)
+		return append(append(ftyp, meta...), newISOBox("mdat", payload)...)
+	}
+	data := build(0)
+	return build(len(data) - len(payload))
+}
+
+// getAVIFItemData get the data of item
+func getAVIFItemData(data []byte, id uint32) []byte {
+	boxes, _ := parseISOBoxes(data)
+	meta, err := parseAVIFMeta(findISOBox(boxes, "meta").data)
+	if err != nil {
+		panic(err)
+	}
+	item := meta.iloc.find(id)
+	start := item.baseOffset + item.extents[0].offset
+	return data[start : start+item.extents[0].length]
+}
+
+func TestAVIFMetadata(t *testing.T) {
+	assert := assert.New(t)
+	payload := []byte("av01 data")
+	data := newTestAVIF(payload)
+	assert.Equal(EncodeTypeAVIF, detectImageType(data))
+	assert.Equal(payload, getAVIFItemData(data, 1))
+	assert.True(readMetadata(data, EncodeTypeAVIF).isEmpty())
+
+	m := &metadata{
+		Exif: newTestFullExif(),
+		ICC:  testICC,
+	}
+	result, err := writeMetadata(data, EncodeTypeAVIF, m)
+	assert.Nil(err)
+	// 主图的数据偏移需要调整
+	assert.Equal(payload, getAVIFItemData(result, 1))
+	meta := readMetadata(result, EncodeTypeAVIF)
+	assert.Equal(m.Exif, meta.Exif)
+	assert.Equal(m.ICC, meta.ICC)
+
+	// 再次写入则替换icc
+	icc := []byte("new icc profile")
+	result, err = writeMetadata(result, EncodeTypeAVIF, &metadata{
+		ICC: icc,
+	})
+	assert.Nil(err)
+	assert.Equal(payload, getAVIFItemData(result, 1))
+	assert.Equal(icc, readMetadata(result, EncodeTypeAVIF).ICC)
+
+	// strip不修改数据
+	result, err = writeMetadata(data, EncodeTypeAVIF, nil)
+	assert.Nil(err)
+	assert.Equal(data, result)
+
+	_, err = writeMetadata([]byte("abcd"), EncodeTypeAVIF, m)
+	assert.Equal(errAVIFIsInvalid, err)
+}
+
+func TestImageOptimMetadata(t *testing.T) {
+	data, err := writeMetadata(newTestJPEGWithOrientation(6), EncodeTypeJPEG, &metadata{
+		Exif: newTestFullExif(),
+		ICC:  testICC,
+	})
+	if err != nil {
+		panic(err)
+	}
+	for _, output := range []EncodeType{EncodeTypeJPEG, EncodeTypePNG, EncodeTypeWEBP} {
+		t.Run(output.String(), func(t *testing.T) {
+			assert := assert.New(t)
+			img, err := ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+				Source: EncodeTypeJPEG,
+				Output: output,
+			})
+			assert.Nil(err)
+			assert.True(readMetadata(img.Data, output).isEmpty())
+
+			img, err = ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+				Source:   EncodeTypeJPEG,
+				Output:   output,
+				Metadata: MetadataKeep,
+			})
+			assert.Nil(err)
+			m := readMetadata(img.Data, output)
+			assert.Equal(testICC, m.ICC)
+			// 已旋转则重置方向
+			assert.Equal(OrientationNormal, readExifOrientation(m.Exif))
+			assert.Equal("tiny.io\x00", string(readExifASCII(m.Exif, exifCopyrightTag)))
+
+			img, err = ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+				Source:   EncodeTypeJPEG,
+				Output:   output,
+				Metadata: MetadataKeepICC,
+			})
+			assert.Nil(err)
+			m = readMetadata(img.Data, output)
+			assert.Equal(testICC, m.ICC)
+			assert.Nil(m.Exif)
+		})
+	}
+}
+
+func TestPNGICCLimit(t *testing.T) {
+	assert := assert.New(t)
+	pngData := &bytes.Buffer{}
+	err := png.Encode(pngData, getTestImage())
+	assert.Nil(err)
+	data, err := writeMetadata(pngData.Bytes(), EncodeTypePNG, &metadata{
+		ICC: make([]byte, maxICCSize+1),
+	})
+	assert.Nil(err)
+	// 压缩后的数据较小，但解压后超出限制
+	assert.Less(len(data), maxICCSize)
+	assert.Nil(readMetadata(data, EncodeTypePNG).ICC)
+
+	data, err = writeMetadata(pngData.Bytes(), EncodeTypePNG, &metadata{
+		ICC: testICC,
+	})
+	assert.Nil(err)
+	assert.Equal(testICC, readMetadata(data, EncodeTypePNG).ICC)
+}
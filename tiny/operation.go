@@ -0,0 +1,250 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	// OpRotate rotate clockwise by the angle
+	OpRotate = "rotate"
+	// OpFlipH flip horizontally
+	OpFlipH = "flipH"
+	// OpFlipV flip vertically
+	OpFlipV = "flipV"
+	// OpBlur gaussian blur by the sigma
+	OpBlur = "blur"
+	// OpSharpen sharpen by the sigma
+	OpSharpen = "sharpen"
+	// OpGamma gamma correction by the value, 1.0 means no change
+	OpGamma = "gamma"
+	// OpBrightness adjust brightness by the value(-100, 100)
+	OpBrightness = "brightness"
+	// OpContrast adjust contrast by the value(-100, 100)
+	OpContrast = "contrast"
+	// OpSaturation adjust saturation by the value(-100, 500)
+	OpSaturation = "saturation"
+	// OpGrayscale convert to grayscale
+	OpGrayscale = "grayscale"
+)
+
+// maxOperationSigma the max sigma of blur and sharpen, the kernel size
+// and the cost increase with the sigma
+const maxOperationSigma = 50
+
+// maxOperations the max count of operations, each operation
+// processes all pixels of image
+const maxOperations = 16
+
+// ErrOperationIsInvalid the operation is not supported or its parameter is invalid
+var ErrOperationIsInvalid = errors.New("operation is invalid")
+
+// Operation the image operation
+type Operation struct {
+	Op string `json:"op"`
+	// Angle the clockwise angle of rotate
+	Angle float64 `json:"angle,omitempty"`
+	// Sigma the sigma of blur and sharpen
+	Sigma float64 `json:"sigma,omitempty"`
+	// Value the value of gamma, brightness, contrast and saturation
+	Value float64 `json:"value,omitempty"`
+}
+
+func newOperationError(op *Operation) error {
+	return fmt.Errorf("%w: %s", ErrOperationIsInvalid, op.Op)
+}
+
+// validate check the operation and its parameter
+func (op *Operation) validate() error {
+	// NaN与Inf会导致imaging创建无效尺寸的图片
+	for _, v := range []float64{op.Angle, op.Sigma, op.Value} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return newOperationError(op)
+		}
+	}
+	switch op.Op {
+	case OpRotate, OpFlipH, OpFlipV, OpGrayscale:
+		return nil
+	case OpBlur, OpSharpen:
+		if op.Sigma <= 0 || op.Sigma > maxOperationSigma {
+			return newOperationError(op)
+		}
+	case OpGamma:
+		if op.Value <= 0 {
+			return newOperationError(op)
+		}
+	case OpBrightness, OpContrast:
+		if op.Value < -100 || op.Value > 100 {
+			return newOperationError(op)
+		}
+	case OpSaturation:
+		if op.Value < -100 || op.Value > 500 {
+			return newOperationError(op)
+		}
+	default:
+		return newOperationError(op)
+	}
+	return nil
+}
+
+// rotatedSize get the size of image after rotating by the angle,
+// it is not less than the size of imaging
+func rotatedSize(width, height int, angle float64) (int, int) {
+	switch math.Mod(math.Abs(angle), 360) {
+	case 0, 180:
+		return width, height
+	case 90, 270:
+		return height, width
+	}
+	sin, cos := math.Sincos(math.Pi * angle / 180)
+	w := float64(width)*math.Abs(cos) + float64(height)*math.Abs(sin)
+	h := float64(width)*math.Abs(sin) + float64(height)*math.Abs(cos)
+	return int(math.Ceil(w)) + 1, int(math.Ceil(h)) + 1
+}
+
+// checkSize check the pixels of image after the operation,
+// the image may be enlarged by rotating
+func (op *Operation) checkSize(img image.Image) error {
+	if op.Op != OpRotate {
+		return nil
+	}
+	size := img.Bounds().Size()
+	width, height := rotatedSize(size.X, size.Y, op.Angle)
+	return checkGIFPixels(width, height, 1)
+}
+
+// apply apply the operation to image
+func (op *Operation) apply(img image.Image) image.Image {
+	switch op.Op {
+	case OpRotate:
+		// imaging的旋转为逆时针
+		angle := math.Mod(op.Angle, 360)
+		if angle < 0 {
+			angle += 360
+		}
+		switch angle {
+		case 0:
+			return img
+		case 90:
+			return imaging.Rotate270(img)
+		case 180:
+			return imaging.Rotate180(img)
+		case 270:
+			return imaging.Rotate90(img)
+		default:
+			return imaging.Rotate(img, -angle, color.Transparent)
+		}
+	case OpFlipH:
+		return imaging.FlipH(img)
+	case OpFlipV:
+		return imaging.FlipV(img)
+	case OpBlur:
+		return imaging.Blur(img, op.Sigma)
+	case OpSharpen:
+		return imaging.Sharpen(img, op.Sigma)
+	case OpGamma:
+		return imaging.AdjustGamma(img, op.Value)
+	case OpBrightness:
+		return imaging.AdjustBrightness(img, op.Value)
+	case OpContrast:
+		return imaging.AdjustContrast(img, op.Value)
+	case OpSaturation:
+		return imaging.AdjustSaturation(img, op.Value)
+	case OpGrayscale:
+		return imaging.Grayscale(img)
+	default:
+		return img
+	}
+}
+
+// validateOperations check all operations
+func validateOperations(ops []*Operation) error {
+	if len(ops) > maxOperations {
+		return fmt.Errorf("%w: the count of operations exceeds %d", ErrOperationIsInvalid, maxOperations)
+	}
+	for _, op := range ops {
+		err := op.validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImageOperate apply the operations to image in order, it returns
+// ErrImageIsTooLarge if the pixels of image exceed the limit of SetGIFDecodeLimit
+func ImageOperate(img image.Image, ops []*Operation) (image.Image, error) {
+	err := validateOperations(ops)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		// 在创建新图片前检查尺寸
+		err = op.checkSize(img)
+		if err != nil {
+			return nil, err
+		}
+		img = op.apply(img)
+	}
+	return img, nil
+}
+
+// ParseOperations parse the operations of query string, e.g. rotate:90,sharpen:0.5,grayscale,
+// the value is the angle of rotate, the sigma of blur and sharpen, otherwise the value of adjustment
+func ParseOperations(v string) ([]*Operation, error) {
+	if v == "" {
+		return nil, nil
+	}
+	items := strings.Split(v, ",")
+	if len(items) > maxOperations {
+		return nil, fmt.Errorf("%w: the count of operations exceeds %d", ErrOperationIsInvalid, maxOperations)
+	}
+	ops := make([]*Operation, 0, len(items))
+	for _, item := range items {
+		name, value, hasValue := strings.Cut(strings.TrimSpace(item), ":")
+		op := &Operation{
+			Op: name,
+		}
+		if hasValue {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, newOperationError(op)
+			}
+			switch name {
+			case OpRotate:
+				op.Angle = f
+			case OpBlur, OpSharpen:
+				op.Sigma = f
+			default:
+				op.Value = f
+			}
+		}
+		err := op.validate()
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
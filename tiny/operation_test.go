@@ -0,0 +1,188 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOperations(t *testing.T) {
+	assert := assert.New(t)
+
+	ops, err := ParseOperations("rotate:90, sharpen:0.5,grayscale,brightness:-10")
+	assert.Nil(err)
+	assert.Equal([]*Operation{
+		{Op: OpRotate, Angle: 90},
+		{Op: OpSharpen, Sigma: 0.5},
+		{Op: OpGrayscale},
+		{Op: OpBrightness, Value: -10},
+	}, ops)
+
+	ops, err = ParseOperations("")
+	assert.Nil(err)
+	assert.Nil(ops)
+
+	for _, v := range []string{
+		"unknown",
+		"rotate:abc",
+		"blur",
+		"gamma:0",
+		"contrast:200",
+		"saturation:600",
+		"blur:NaN",
+		"sharpen:Inf",
+		"blur:1e12",
+		"sharpen:51",
+		"rotate:NaN",
+		"rotate:-Inf",
+		"gamma:NaN",
+		"brightness:NaN",
+	} {
+		_, err = ParseOperations(v)
+		assert.True(errors.Is(err, ErrOperationIsInvalid), v)
+	}
+
+	_, err = ParseOperations(strings.Repeat("flipH,", maxOperations) + "flipV")
+	assert.True(errors.Is(err, ErrOperationIsInvalid))
+}
+
+func TestImageOperate(t *testing.T) {
+	assert := assert.New(t)
+	// 20x10的图片，左上角为红色
+	img := image.NewNRGBA(image.Rect(0, 0, 20, 10))
+	img.SetNRGBA(0, 0, color.NRGBA{255, 0, 0, 255})
+	red := color.NRGBA{255, 0, 0, 255}
+
+	// 顺时针旋转后左上角移至右上角
+	result, err := ImageOperate(img, []*Operation{
+		{Op: OpRotate, Angle: 90},
+	})
+	assert.Nil(err)
+	assert.Equal(image.Rect(0, 0, 10, 20), result.Bounds())
+	assert.Equal(red, color.NRGBAModel.Convert(result.At(9, 0)))
+
+	result, err = ImageOperate(img, []*Operation{
+		{Op: OpRotate, Angle: -90},
+		{Op: OpFlipV},
+	})
+	assert.Nil(err)
+	assert.Equal(red, color.NRGBAModel.Convert(result.At(0, 0)))
+
+	result, err = ImageOperate(img, []*Operation{
+		{Op: OpFlipH},
+		{Op: OpGrayscale},
+	})
+	assert.Nil(err)
+	c := color.NRGBAModel.Convert(result.At(19, 0)).(color.NRGBA)
+	assert.Equal(c.R, c.G)
+	assert.Equal(c.G, c.B)
+
+	result, err = ImageOperate(img, []*Operation{
+		{Op: OpRotate, Angle: 45},
+	})
+	assert.Nil(err)
+	assert.Greater(result.Bounds().Dx(), 20)
+
+	for _, op := range []*Operation{
+		{Op: OpSharpen},
+		{Op: OpBlur, Sigma: math.NaN()},
+		{Op: OpRotate, Angle: math.Inf(1)},
+		{Op: OpSharpen, Sigma: 1e12},
+		{Op: OpContrast, Value: math.NaN()},
+	} {
+		_, err = ImageOperate(img, []*Operation{op})
+		assert.True(errors.Is(err, ErrOperationIsInvalid), op)
+	}
+
+	// 操作数量超出限制
+	ops := make([]*Operation, maxOperations+1)
+	for i := range ops {
+		ops[i] = &Operation{Op: OpFlipH}
+	}
+	_, err = ImageOperate(img, ops)
+	assert.True(errors.Is(err, ErrOperationIsInvalid))
+
+	// 每次旋转45度图片都会变大，超出像素限制则出错
+	SetGIFDecodeLimit(1000, 0)
+	defer SetGIFDecodeLimit(0, 0)
+	_, err = ImageOperate(img, []*Operation{
+		{Op: OpRotate, Angle: 90},
+		{Op: OpRotate, Angle: 45},
+	})
+	assert.Nil(err)
+	_, err = ImageOperate(img, []*Operation{
+		{Op: OpRotate, Angle: 45},
+		{Op: OpRotate, Angle: 45},
+		{Op: OpRotate, Angle: 45},
+	})
+	assert.Equal(ErrImageIsTooLarge, err)
+}
+
+func TestRotatedSize(t *testing.T) {
+	assert := assert.New(t)
+	for _, angle := range []float64{0, 180, -180, 360} {
+		w, h := rotatedSize(20, 10, angle)
+		assert.Equal([]int{20, 10}, []int{w, h})
+	}
+	for _, angle := range []float64{90, -90, 270} {
+		w, h := rotatedSize(20, 10, angle)
+		assert.Equal([]int{10, 20}, []int{w, h})
+	}
+	// 不小于imaging旋转后的尺寸
+	img := image.NewNRGBA(image.Rect(0, 0, 20, 10))
+	for _, angle := range []float64{1, 30, 45, 100, 333} {
+		w, h := rotatedSize(20, 10, angle)
+		size := imaging.Rotate(img, -angle, color.Transparent).Bounds().Size()
+		assert.GreaterOrEqual(w, size.X)
+		assert.GreaterOrEqual(h, size.Y)
+	}
+}
+
+func TestImageOptimOperations(t *testing.T) {
+	assert := assert.New(t)
+	data := newTestJPEGWithOrientation(1)
+	img, err := imageDecode(data, EncodeTypeJPEG)
+	assert.Nil(err)
+
+	result, err := ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+		Source: EncodeTypeJPEG,
+		Output: EncodeTypePNG,
+		Operations: []*Operation{
+			{Op: OpRotate, Angle: 270},
+			{Op: OpBlur, Sigma: 1},
+		},
+	})
+	assert.Nil(err)
+	assert.Equal(img.Bounds().Dy(), result.Width)
+	assert.Equal(img.Bounds().Dx(), result.Height)
+
+	_, err = ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+		Source: EncodeTypeJPEG,
+		Output: EncodeTypePNG,
+		Operations: []*Operation{
+			{Op: "unknown"},
+		},
+	})
+	assert.True(errors.Is(err, ErrOperationIsInvalid))
+}
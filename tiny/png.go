@@ -22,8 +22,18 @@ import (
 	"strconv"
 )
 
-// PNGEncode png encode
+// PNGEncode png encode, the image/png with best compression is used
+// if pngquant is not found and fallback is enabled
 func PNGEncode(ctx context.Context, img image.Image, quality int) (data []byte, err error) {
+	info, err := pngEncode(ctx, img, quality)
+	if err != nil {
+		return
+	}
+	data = info.Data
+	return
+}
+
+func pngEncode(ctx context.Context, img image.Image, quality int) (info *Image, err error) {
 	if quality <= minPNGQuality || quality > maxPNGQuality {
 		quality = defaultPNGQuality
 	}
@@ -33,21 +43,43 @@ func PNGEncode(ctx context.Context, img image.Image, quality int) (data []byte,
 		return
 	}
 
-	fn := func(originalFile, targetFile string) []string {
-		return []string{
-			"pngquant",
-			"--quality",
-			strconv.Itoa(quality),
-			originalFile,
-			"--output",
-			targetFile,
-		}
+	// pngquant的文件为-时从stdin读取并输出至stdout
+	args := []string{
+		"pngquant",
+		"--quality",
+		strconv.Itoa(quality),
+		"-",
 	}
 	fileBuffer := new(bytes.Buffer)
-	err = doCommandConvert(ctx, w.Bytes(), fn, fileBuffer)
+	err = doCommandPipe(ctx, w.Bytes(), args, fileBuffer)
 	if err != nil {
-		return
+		if !shouldFallback(err) {
+			return
+		}
+		// pngquant不可用时使用image/png的最高压缩级别
+		return pngLosslessEncode(img)
+	}
+	info = &Image{
+		Data:    fileBuffer.Bytes(),
+		Type:    EncodeTypePNG,
+		Encoder: EncoderPngquant,
 	}
-	data = fileBuffer.Bytes()
 	return
 }
+
+// pngLosslessEncode encode the image with the best compression of image/png
+func pngLosslessEncode(img image.Image) (*Image, error) {
+	w := new(bytes.Buffer)
+	encoder := &png.Encoder{
+		CompressionLevel: png.BestCompression,
+	}
+	err := encoder.Encode(w, img)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{
+		Data:    w.Bytes(),
+		Type:    EncodeTypePNG,
+		Encoder: EncoderGoPNG,
+	}, nil
+}
@@ -0,0 +1,343 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// FitMode the mode of resizing image to the width and height
+type FitMode int
+
+const (
+	// FitNone resize to the width and height directly, or crop without
+	// scaling if the crop type is set
+	FitNone FitMode = iota
+	// FitContain keep the aspect ratio within the box, and pad the
+	// remaining area with transparent pixels
+	FitContain
+	// FitCover keep the aspect ratio to cover the box, and crop the
+	// overflow by the crop type(center by default)
+	FitCover
+	// FitFill ignore the aspect ratio and stretch to the box
+	FitFill
+	// FitInside keep the aspect ratio within the box
+	FitInside
+	// FitOutside keep the aspect ratio to cover the box without crop
+	FitOutside
+)
+
+func (m FitMode) String() string {
+	switch m {
+	case FitContain:
+		return "contain"
+	case FitCover:
+		return "cover"
+	case FitFill:
+		return "fill"
+	case FitInside:
+		return "inside"
+	case FitOutside:
+		return "outside"
+	default:
+		return "none"
+	}
+}
+
+// ConvertToFitMode convert to fit mode, none is returned for unknown value
+func ConvertToFitMode(v string) FitMode {
+	switch v {
+	case "contain":
+		return FitContain
+	case "cover":
+		return FitCover
+	case "fill":
+		return FitFill
+	case "inside":
+		return FitInside
+	case "outside":
+		return FitOutside
+	default:
+		return FitNone
+	}
+}
+
+// ResampleFilter the resampling filter of resizing
+type ResampleFilter int
+
+const (
+	// FilterAuto linear for downscaling by a large factor, otherwise lanczos
+	FilterAuto ResampleFilter = iota
+	// FilterNearest nearest neighbor, it's suitable for pixel art
+	FilterNearest
+	// FilterBox box(area average)
+	FilterBox
+	// FilterLinear bilinear
+	FilterLinear
+	// FilterHermite hermite
+	FilterHermite
+	// FilterMitchell mitchell-netravali
+	FilterMitchell
+	// FilterCatmullRom catmull-rom, a sharp cubic filter
+	FilterCatmullRom
+	// FilterBSpline b-spline, a smooth cubic filter
+	FilterBSpline
+	// FilterGaussian gaussian
+	FilterGaussian
+	// FilterLanczos lanczos, the best quality but slow
+	FilterLanczos
+)
+
+// 缩小超过该倍数时，自动选择的滤波器使用linear
+const autoFilterDownscaleFactor = 4
+
+var resampleFilterNames = map[ResampleFilter]string{
+	FilterAuto:       "auto",
+	FilterNearest:    "nearest",
+	FilterBox:        "box",
+	FilterLinear:     "linear",
+	FilterHermite:    "hermite",
+	FilterMitchell:   "mitchell",
+	FilterCatmullRom: "catmull-rom",
+	FilterBSpline:    "bspline",
+	FilterGaussian:   "gaussian",
+	FilterLanczos:    "lanczos",
+}
+
+func (f ResampleFilter) String() string {
+	name, ok := resampleFilterNames[f]
+	if !ok {
+		return resampleFilterNames[FilterAuto]
+	}
+	return name
+}
+
+// ConvertToResampleFilter convert to resample filter, auto is returned for unknown value
+func ConvertToResampleFilter(v string) ResampleFilter {
+	for f, name := range resampleFilterNames {
+		if name == v {
+			return f
+		}
+	}
+	return FilterAuto
+}
+
+// imagingFilter get the filter of imaging, the auto filter is selected by
+// the scale factor of resizing
+func (f ResampleFilter) imagingFilter(bounds image.Rectangle, width, height int) imaging.ResampleFilter {
+	switch f {
+	case FilterNearest:
+		return imaging.NearestNeighbor
+	case FilterBox:
+		return imaging.Box
+	case FilterLinear:
+		return imaging.Linear
+	case FilterHermite:
+		return imaging.Hermite
+	case FilterMitchell:
+		return imaging.MitchellNetravali
+	case FilterCatmullRom:
+		return imaging.CatmullRom
+	case FilterBSpline:
+		return imaging.BSpline
+	case FilterGaussian:
+		return imaging.Gaussian
+	case FilterLanczos:
+		return imaging.Lanczos
+	}
+	// 未指定的宽或高按比例计算
+	factor := 0.0
+	if width > 0 {
+		factor = float64(bounds.Dx()) / float64(width)
+	}
+	if height > 0 {
+		factor = math.Max(factor, float64(bounds.Dy())/float64(height))
+	}
+	// 大倍数缩小时lanczos的耗时较多，且linear的效果已足够
+	if factor >= autoFilterDownscaleFactor {
+		return imaging.Linear
+	}
+	return imaging.Lanczos
+}
+
+// ImageResizeWithFilter resize image with the resampling filter
+func ImageResizeWithFilter(img image.Image, width, height int, filter ResampleFilter) image.Image {
+	return imaging.Resize(img, width, height, filter.imagingFilter(img.Bounds(), width, height))
+}
+
+// scaleSize scale the size, the result is at least 1
+func scaleSize(size int, scale float64) int {
+	v := int(math.Round(float64(size) * scale))
+	if v < 1 {
+		return 1
+	}
+	return v
+}
+
+// ImageFit resize the image to the box by fit mode, the crop type is used as
+// the position of cover and contain. If without enlargement, the image
+// will not be scaled up.
+func ImageFit(img image.Image, fit FitMode, cropType CropType, width, height int, withoutEnlargement bool, filter ResampleFilter) image.Image {
+	currentWidth := img.Bounds().Dx()
+	currentHeight := img.Bounds().Dy()
+	if (width == 0 && height == 0) || currentWidth == 0 || currentHeight == 0 {
+		return img
+	}
+	scaleX := float64(width) / float64(currentWidth)
+	scaleY := float64(height) / float64(currentHeight)
+
+	// 仅指定宽或高时按比例缩放
+	if width == 0 || height == 0 {
+		scale := math.Max(scaleX, scaleY)
+		if withoutEnlargement && scale >= 1 {
+			return img
+		}
+		return ImageResizeWithFilter(img, width, height, filter)
+	}
+	if cropType == CropNone {
+		cropType = CropCenterCenter
+	}
+
+	var scale float64
+	switch fit {
+	case FitFill, FitNone:
+		if withoutEnlargement {
+			width = int(math.Min(float64(width), float64(currentWidth)))
+			height = int(math.Min(float64(height), float64(currentHeight)))
+		}
+		if width == currentWidth && height == currentHeight {
+			return img
+		}
+		return ImageResizeWithFilter(img, width, height, filter)
+	case FitCover, FitOutside:
+		scale = math.Max(scaleX, scaleY)
+	default:
+		scale = math.Min(scaleX, scaleY)
+	}
+	if withoutEnlargement && scale > 1 {
+		scale = 1
+	}
+	result := img
+	resizeWidth := scaleSize(currentWidth, scale)
+	resizeHeight := scaleSize(currentHeight, scale)
+	if resizeWidth != currentWidth || resizeHeight != currentHeight {
+		result = ImageResizeWithFilter(img, resizeWidth, resizeHeight, filter)
+	}
+
+	switch fit {
+	case FitCover:
+		// 超出的部分裁剪，不放大时尺寸可能小于指定的尺寸
+		return ImageCrop(result, cropType, width, height)
+	case FitContain:
+		// 其余部分填充透明像素
+		if resizeWidth == width && resizeHeight == height {
+			return result
+		}
+		bg := imaging.New(width, height, color.Transparent)
+		// 智能裁剪对填充无意义，居中处理
+		if cropType == CropSmart {
+			cropType = CropCenterCenter
+		}
+		x, y := getCropOffset(cropType, width-resizeWidth, height-resizeHeight)
+		return imaging.Paste(bg, result, image.Pt(x, y))
+	default:
+		return result
+	}
+}
+
+// FocalPoint the normalized focal point, the value of x and y is 0 to 1
+type FocalPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// CropRect the pixel rectangle of source image
+type CropRect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// ErrCropRectIsInvalid the crop rectangle is empty or out of the image bounds
+var ErrCropRectIsInvalid = errors.New("crop rectangle is invalid")
+
+// ImageCropRect crop the rectangle of image, the rectangle should be within the image bounds
+func ImageCropRect(img image.Image, rect *CropRect) (image.Image, error) {
+	bounds := img.Bounds()
+	if rect.X < 0 || rect.Y < 0 || rect.Width <= 0 || rect.Height <= 0 ||
+		rect.X+rect.Width > bounds.Dx() || rect.Y+rect.Height > bounds.Dy() {
+		return nil, ErrCropRectIsInvalid
+	}
+	start := bounds.Min.Add(image.Pt(rect.X, rect.Y))
+	return imaging.Crop(img, image.Rectangle{
+		Min: start,
+		Max: start.Add(image.Pt(rect.Width, rect.Height)),
+	}), nil
+}
+
+// ImageFocalCrop scale the image to cover the width and height, then crop
+// around the focal point. The focal point is kept at the center of result
+// if possible.
+func ImageFocalCrop(img image.Image, focal *FocalPoint, width, height int, withoutEnlargement bool, filter ResampleFilter) image.Image {
+	// 仅指定宽或高时无需裁剪
+	if width == 0 || height == 0 {
+		return ImageFit(img, FitCover, CropNone, width, height, withoutEnlargement, filter)
+	}
+	currentWidth := img.Bounds().Dx()
+	currentHeight := img.Bounds().Dy()
+	if currentWidth == 0 || currentHeight == 0 {
+		return img
+	}
+	scale := math.Max(float64(width)/float64(currentWidth), float64(height)/float64(currentHeight))
+	if withoutEnlargement && scale > 1 {
+		scale = 1
+	}
+	result := img
+	resizeWidth := scaleSize(currentWidth, scale)
+	resizeHeight := scaleSize(currentHeight, scale)
+	if resizeWidth != currentWidth || resizeHeight != currentHeight {
+		result = ImageResizeWithFilter(img, resizeWidth, resizeHeight, filter)
+	}
+	if width > resizeWidth {
+		width = resizeWidth
+	}
+	if height > resizeHeight {
+		height = resizeHeight
+	}
+	clamp := func(v, limit int) int {
+		if v < 0 {
+			return 0
+		}
+		if v > limit {
+			return limit
+		}
+		return v
+	}
+	fx := math.Min(math.Max(focal.X, 0), 1)
+	fy := math.Min(math.Max(focal.Y, 0), 1)
+	x := clamp(int(math.Round(fx*float64(resizeWidth)-float64(width)/2)), resizeWidth-width)
+	y := clamp(int(math.Round(fy*float64(resizeHeight)-float64(height)/2)), resizeHeight-height)
+	start := result.Bounds().Min.Add(image.Pt(x, y))
+	return imaging.Crop(result, image.Rectangle{
+		Min: start,
+		Max: start.Add(image.Pt(width, height)),
+	})
+}
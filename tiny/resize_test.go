@@ -0,0 +1,315 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertToFitMode(t *testing.T) {
+	assert := assert.New(t)
+	for _, fit := range []FitMode{
+		FitNone,
+		FitContain,
+		FitCover,
+		FitFill,
+		FitInside,
+		FitOutside,
+	} {
+		assert.Equal(fit, ConvertToFitMode(fit.String()))
+	}
+	assert.Equal(FitNone, ConvertToFitMode("unknown"))
+}
+
+func TestImageFit(t *testing.T) {
+	// 200x100的图片，左半部分为红色，右半部分为蓝色
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 100))
+	for x := 0; x < 200; x++ {
+		for y := 0; y < 100; y++ {
+			c := color.NRGBA{255, 0, 0, 255}
+			if x >= 100 {
+				c = color.NRGBA{0, 0, 255, 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	tests := []struct {
+		name               string
+		fit                FitMode
+		crop               CropType
+		width              int
+		height             int
+		withoutEnlargement bool
+		result             image.Rectangle
+	}{
+		{
+			name:   "contain",
+			fit:    FitContain,
+			width:  100,
+			height: 100,
+			result: image.Rect(0, 0, 100, 100),
+		},
+		{
+			name:   "cover",
+			fit:    FitCover,
+			width:  100,
+			height: 100,
+			result: image.Rect(0, 0, 100, 100),
+		},
+		{
+			name:   "fill",
+			fit:    FitFill,
+			width:  100,
+			height: 100,
+			result: image.Rect(0, 0, 100, 100),
+		},
+		{
+			name:   "inside",
+			fit:    FitInside,
+			width:  100,
+			height: 100,
+			result: image.Rect(0, 0, 100, 50),
+		},
+		{
+			name:   "outside",
+			fit:    FitOutside,
+			width:  100,
+			height: 100,
+			result: image.Rect(0, 0, 200, 100),
+		},
+		{
+			name:   "only width",
+			fit:    FitCover,
+			width:  100,
+			result: image.Rect(0, 0, 100, 50),
+		},
+		{
+			name:               "inside without enlargement",
+			fit:                FitInside,
+			width:              400,
+			height:             400,
+			withoutEnlargement: true,
+			result:             image.Rect(0, 0, 200, 100),
+		},
+		{
+			name:               "cover without enlargement",
+			fit:                FitCover,
+			width:              400,
+			height:             50,
+			withoutEnlargement: true,
+			result:             image.Rect(0, 0, 200, 50),
+		},
+		{
+			name:               "contain without enlargement",
+			fit:                FitContain,
+			width:              400,
+			height:             400,
+			withoutEnlargement: true,
+			result:             image.Rect(0, 0, 400, 400),
+		},
+		{
+			name:               "fill without enlargement",
+			fit:                FitFill,
+			width:              400,
+			height:             50,
+			withoutEnlargement: true,
+			result:             image.Rect(0, 0, 200, 50),
+		},
+		{
+			name:   "enlarge",
+			fit:    FitInside,
+			width:  400,
+			height: 400,
+			result: image.Rect(0, 0, 400, 200),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			result := ImageFit(img, tt.fit, tt.crop, tt.width, tt.height, tt.withoutEnlargement, FilterLanczos)
+			assert.Equal(tt.result, result.Bounds())
+		})
+	}
+
+	assert := assert.New(t)
+	// cover默认保留中间部分，也可指定位置
+	result := ImageFit(img, FitCover, CropNone, 100, 100, false, FilterLanczos)
+	assert.Equal(color.NRGBA{255, 0, 0, 255}, color.NRGBAModel.Convert(result.At(10, 50)))
+	assert.Equal(color.NRGBA{0, 0, 255, 255}, color.NRGBAModel.Convert(result.At(90, 50)))
+	result = ImageFit(img, FitCover, CropRightCenter, 100, 100, false, FilterLanczos)
+	assert.Equal(color.NRGBA{0, 0, 255, 255}, color.NRGBAModel.Convert(result.At(10, 50)))
+
+	// contain的空白部分为透明
+	result = ImageFit(img, FitContain, CropNone, 100, 100, false, FilterLanczos)
+	_, _, _, a := result.At(50, 10).RGBA()
+	assert.Equal(uint32(0), a)
+	_, _, _, a = result.At(50, 50).RGBA()
+	assert.Equal(uint32(0xffff), a)
+}
+
+func TestImageOptimFit(t *testing.T) {
+	assert := assert.New(t)
+	data := newTestJPEGWithOrientation(1)
+	img, err := imageDecode(data, EncodeTypeJPEG)
+	assert.Nil(err)
+	width := img.Bounds().Dx()
+	height := img.Bounds().Dy()
+
+	result, err := ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+		Source: EncodeTypeJPEG,
+		Output: EncodeTypePNG,
+		Width:  width / 2,
+		Height: width / 2,
+		Fit:    FitInside,
+	})
+	assert.Nil(err)
+	assert.Equal(width/2, result.Width)
+	assert.Equal(scaleSize(height, 0.5), result.Height)
+
+	result, err = ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+		Source:             EncodeTypeJPEG,
+		Output:             EncodeTypePNG,
+		Width:              width * 2,
+		WithoutEnlargement: true,
+	})
+	assert.Nil(err)
+	assert.Equal(width, result.Width)
+	assert.Equal(height, result.Height)
+}
+
+func TestImageCropRect(t *testing.T) {
+	assert := assert.New(t)
+	img := image.NewNRGBA(image.Rect(0, 0, 100, 50))
+	img.SetNRGBA(30, 20, color.NRGBA{255, 0, 0, 255})
+
+	result, err := ImageCropRect(img, &CropRect{
+		X:      30,
+		Y:      20,
+		Width:  40,
+		Height: 30,
+	})
+	assert.Nil(err)
+	assert.Equal(image.Rect(0, 0, 40, 30), result.Bounds())
+	assert.Equal(color.NRGBA{255, 0, 0, 255}, color.NRGBAModel.Convert(result.At(0, 0)))
+
+	for _, rect := range []*CropRect{
+		{X: -1, Y: 0, Width: 10, Height: 10},
+		{X: 0, Y: 0, Width: 0, Height: 10},
+		{X: 60, Y: 0, Width: 41, Height: 10},
+		{X: 0, Y: 30, Width: 10, Height: 21},
+	} {
+		_, err = ImageCropRect(img, rect)
+		assert.Equal(ErrCropRectIsInvalid, err)
+	}
+}
+
+func TestImageFocalCrop(t *testing.T) {
+	assert := assert.New(t)
+	// 200x100的图片，x为10-20的区域为红色
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 100))
+	for x := 10; x < 20; x++ {
+		for y := 0; y < 100; y++ {
+			img.SetNRGBA(x, y, color.NRGBA{255, 0, 0, 255})
+		}
+	}
+	// 缩放后为100x50，焦点在左侧则不超出边界
+	result := ImageFocalCrop(img, &FocalPoint{X: 0.075, Y: 0.5}, 50, 50, false, FilterLanczos)
+	assert.Equal(image.Rect(0, 0, 50, 50), result.Bounds())
+	r, _, _, _ := result.At(7, 25).RGBA()
+	assert.Greater(r, uint32(0xf000))
+
+	// 焦点在右侧
+	result = ImageFocalCrop(img, &FocalPoint{X: 0.7, Y: 0.5}, 50, 50, false, FilterLanczos)
+	assert.Equal(image.Rect(0, 0, 50, 50), result.Bounds())
+	r, _, _, _ = result.At(7, 25).RGBA()
+	assert.Less(r, uint32(0x1000))
+
+	result = ImageFocalCrop(img, &FocalPoint{X: 0.5, Y: 0.5}, 400, 400, true, FilterLanczos)
+	assert.Equal(image.Rect(0, 0, 200, 100), result.Bounds())
+
+	result = ImageFocalCrop(img, &FocalPoint{X: 0.5, Y: 0.5}, 100, 0, false, FilterLanczos)
+	assert.Equal(image.Rect(0, 0, 100, 50), result.Bounds())
+}
+
+func TestImageOptimRect(t *testing.T) {
+	assert := assert.New(t)
+	data := newTestJPEGWithOrientation(1)
+	result, err := ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+		Source: EncodeTypeJPEG,
+		Output: EncodeTypePNG,
+		Rect: &CropRect{
+			Width:  4,
+			Height: 2,
+		},
+		Width: 8,
+	})
+	assert.Nil(err)
+	assert.Equal(8, result.Width)
+	assert.Equal(4, result.Height)
+
+	_, err = ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+		Source: EncodeTypeJPEG,
+		Output: EncodeTypePNG,
+		Rect: &CropRect{
+			Width:  10000,
+			Height: 2,
+		},
+	})
+	assert.Equal(ErrCropRectIsInvalid, err)
+}
+
+func TestResampleFilter(t *testing.T) {
+	assert := assert.New(t)
+	for f := range resampleFilterNames {
+		assert.Equal(f, ConvertToResampleFilter(f.String()))
+	}
+	assert.Equal(FilterAuto, ConvertToResampleFilter("unknown"))
+
+	bounds := image.Rect(0, 0, 1000, 500)
+	assert.Equal(imaging.Lanczos.Support, FilterAuto.imagingFilter(bounds, 500, 0).Support)
+	assert.Equal(imaging.Linear.Support, FilterAuto.imagingFilter(bounds, 250, 0).Support)
+	assert.Equal(imaging.Linear.Support, FilterAuto.imagingFilter(bounds, 0, 100).Support)
+	assert.Equal(imaging.Lanczos.Support, FilterAuto.imagingFilter(bounds, 2000, 1000).Support)
+	assert.Equal(imaging.CatmullRom.Support, FilterCatmullRom.imagingFilter(bounds, 250, 0).Support)
+
+	// nearest不产生新的颜色
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.SetNRGBA(0, 0, color.NRGBA{255, 255, 255, 255})
+	img.SetNRGBA(1, 1, color.NRGBA{255, 255, 255, 255})
+	buf := &bytes.Buffer{}
+	err := png.Encode(buf, img)
+	assert.Nil(err)
+	result, err := ImageOptimWithOptions(context.Background(), buf.Bytes(), &ImageOptimOptions{
+		Source: EncodeTypePNG,
+		Output: EncodeTypePNG,
+		Width:  8,
+		Filter: FilterNearest,
+	})
+	assert.Nil(err)
+	decoded, err := imageDecode(result.Data, EncodeTypePNG)
+	assert.Nil(err)
+	for _, pt := range []image.Point{{1, 1}, {3, 3}, {4, 3}, {5, 5}} {
+		r, _, _, _ := decoded.At(pt.X, pt.Y).RGBA()
+		assert.True(r == 0 || r == 0xffff, pt)
+	}
+}
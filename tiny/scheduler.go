@@ -0,0 +1,126 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// 等待队列的长度为并发数的倍数
+const defaultEncodeQueueFactor = 10
+
+// ErrQueueIsFull the wait queue of encoder is full
+var ErrQueueIsFull = errors.New("the queue of encoder is full")
+
+// encodeLimiter limit the concurrency of encoder with a bounded wait queue
+type encodeLimiter struct {
+	tokens     chan struct{}
+	waiting    int32
+	maxWaiting int32
+}
+
+var encodeLimiters = struct {
+	sync.RWMutex
+	m map[EncodeType]*encodeLimiter
+}{
+	m: make(map[EncodeType]*encodeLimiter),
+}
+
+func init() {
+	cpus := runtime.NumCPU()
+	// avif的编码耗时与内存占用较多，限制更低的并发
+	avifConcurrency := cpus / 4
+	if avifConcurrency < 1 {
+		avifConcurrency = 1
+	}
+	SetEncodeLimit(EncodeTypeAVIF, avifConcurrency, avifConcurrency*defaultEncodeQueueFactor)
+	for _, t := range []EncodeType{EncodeTypeJPEG, EncodeTypePNG} {
+		SetEncodeLimit(t, cpus, cpus*defaultEncodeQueueFactor)
+	}
+	SetEncodeLimit(EncodeTypeWEBP, 2*cpus, 2*cpus*defaultEncodeQueueFactor)
+	// gif的量化与动图的每帧编码均较耗时
+	SetEncodeLimit(EncodeTypeGIF, cpus, cpus*defaultEncodeQueueFactor)
+}
+
+// SetEncodeLimit set the max concurrency and wait queue size of encode type,
+// the limit will be removed if concurrency <= 0
+func SetEncodeLimit(t EncodeType, concurrency, queueSize int) {
+	encodeLimiters.Lock()
+	defer encodeLimiters.Unlock()
+	if concurrency <= 0 {
+		delete(encodeLimiters.m, t)
+		return
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	encodeLimiters.m[t] = &encodeLimiter{
+		tokens:     make(chan struct{}, concurrency),
+		maxWaiting: int32(queueSize),
+	}
+}
+
+// GetEncodeLimit get the max concurrency and wait queue size of encode type,
+// the concurrency is 0 if there is no limit
+func GetEncodeLimit(t EncodeType) (concurrency, queueSize int) {
+	encodeLimiters.RLock()
+	defer encodeLimiters.RUnlock()
+	l, ok := encodeLimiters.m[t]
+	if !ok {
+		return 0, 0
+	}
+	return cap(l.tokens), int(l.maxWaiting)
+}
+
+// acquire acquire the token of encoder, it returns ErrQueueIsFull
+// if the wait queue is full, or the error of context if it's done
+func (l *encodeLimiter) acquire(ctx context.Context) (func(), error) {
+	release := func() {
+		<-l.tokens
+	}
+	// 有空闲则直接执行
+	select {
+	case l.tokens <- struct{}{}:
+		return release, nil
+	default:
+	}
+	if atomic.AddInt32(&l.waiting, 1) > l.maxWaiting {
+		atomic.AddInt32(&l.waiting, -1)
+		return nil, ErrQueueIsFull
+	}
+	defer atomic.AddInt32(&l.waiting, -1)
+	select {
+	case l.tokens <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// acquireEncode acquire the token of encode type, the release function
+// should be called when encode is done
+func acquireEncode(ctx context.Context, t EncodeType) (func(), error) {
+	encodeLimiters.RLock()
+	l, ok := encodeLimiters.m[t]
+	encodeLimiters.RUnlock()
+	if !ok {
+		return func() {}, nil
+	}
+	return l.acquire(ctx)
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeLimit(t *testing.T) {
+	assert := assert.New(t)
+	t.Cleanup(func() {
+		SetEncodeLimit(EncodeTypeGzip, 0, 0)
+	})
+	SetEncodeLimit(EncodeTypeGzip, 1, 1)
+	ctx := context.Background()
+	concurrency, queueSize := GetEncodeLimit(EncodeTypeGzip)
+	assert.Equal(1, concurrency)
+	assert.Equal(1, queueSize)
+	// gif默认也有限制
+	concurrency, _ = GetEncodeLimit(EncodeTypeGIF)
+	assert.NotEqual(0, concurrency)
+
+	release, err := acquireEncode(ctx, EncodeTypeGzip)
+	assert.Nil(err)
+
+	// 队列中等待
+	done := make(chan error)
+	go func() {
+		r, err := acquireEncode(ctx, EncodeTypeGzip)
+		if err == nil {
+			r()
+		}
+		done <- err
+	}()
+	// 等待goroutine进入队列
+	for i := 0; i < 100; i++ {
+		encodeLimiters.RLock()
+		waiting := atomic.LoadInt32(&encodeLimiters.m[EncodeTypeGzip].waiting)
+		encodeLimiters.RUnlock()
+		if waiting != 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 队列已满
+	_, err = acquireEncode(ctx, EncodeTypeGzip)
+	assert.Equal(ErrQueueIsFull, err)
+
+	release()
+	assert.Nil(<-done)
+
+	// 超时
+	release, err = acquireEncode(ctx, EncodeTypeGzip)
+	assert.Nil(err)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = acquireEncode(timeoutCtx, EncodeTypeGzip)
+	assert.Equal(context.DeadlineExceeded, err)
+	release()
+
+	// 无限制
+	SetEncodeLimit(EncodeTypeGzip, 0, 0)
+	concurrency, _ = GetEncodeLimit(EncodeTypeGzip)
+	assert.Equal(0, concurrency)
+	release, err = acquireEncode(ctx, EncodeTypeGzip)
+	assert.Nil(err)
+	release()
+}
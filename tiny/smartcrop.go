@@ -0,0 +1,216 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	// 分析时缩小图片，长边的最大尺寸
+	smartCropAnalyzeSize = 256
+	// 计算信息熵的区块大小
+	smartCropEntropyBlock = 8
+	smartCropEntropyBins  = 16
+
+	smartCropEdgeWeight    = 1.0
+	smartCropSkinWeight    = 1.8
+	smartCropEntropyWeight = 0.5
+
+	smartCropSkinThreshold     = 0.8
+	smartCropSkinBrightnessMin = 0.2
+	smartCropSkinBrightnessMax = 1.0
+)
+
+// smartCropSkinColor the normalized skin color
+var smartCropSkinColor = normalizeRGB(0.78, 0.57, 0.44)
+
+func normalizeRGB(r, g, b float64) [3]float64 {
+	mag := math.Sqrt(r*r + g*g + b*b)
+	if mag == 0 {
+		return [3]float64{}
+	}
+	return [3]float64{r / mag, g / mag, b / mag}
+}
+
+// smartCropScores get the score of each pixel, the score is the weighted sum
+// of edge, skin and entropy
+func smartCropScores(img *image.NRGBA) []float64 {
+	width := img.Rect.Dx()
+	height := img.Rect.Dy()
+	lum := make([]float64, width*height)
+	scores := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			offset := y*img.Stride + x*4
+			r := float64(img.Pix[offset]) / 255
+			g := float64(img.Pix[offset+1]) / 255
+			b := float64(img.Pix[offset+2]) / 255
+			l := 0.299*r + 0.587*g + 0.114*b
+			lum[y*width+x] = l
+			// 与肤色的距离，亮度过低则忽略
+			c := normalizeRGB(r, g, b)
+			d := math.Sqrt((c[0]-smartCropSkinColor[0])*(c[0]-smartCropSkinColor[0]) +
+				(c[1]-smartCropSkinColor[1])*(c[1]-smartCropSkinColor[1]) +
+				(c[2]-smartCropSkinColor[2])*(c[2]-smartCropSkinColor[2]))
+			skin := 1 - d
+			if skin > smartCropSkinThreshold && l >= smartCropSkinBrightnessMin && l <= smartCropSkinBrightnessMax {
+				scores[y*width+x] = (skin - smartCropSkinThreshold) / (1 - smartCropSkinThreshold) * smartCropSkinWeight
+			}
+		}
+	}
+	at := func(x, y int) float64 {
+		if x < 0 {
+			x = 0
+		} else if x >= width {
+			x = width - 1
+		}
+		if y < 0 {
+			y = 0
+		} else if y >= height {
+			y = height - 1
+		}
+		return lum[y*width+x]
+	}
+	// 边缘使用拉普拉斯算子
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			edge := math.Abs(4*at(x, y) - at(x-1, y) - at(x+1, y) - at(x, y-1) - at(x, y+1))
+			scores[y*width+x] += math.Min(edge, 1) * smartCropEdgeWeight
+		}
+	}
+	// 按区块计算亮度的信息熵
+	maxEntropy := math.Log2(smartCropEntropyBins)
+	for by := 0; by < height; by += smartCropEntropyBlock {
+		for bx := 0; bx < width; bx += smartCropEntropyBlock {
+			var hist [smartCropEntropyBins]int
+			count := 0
+			for y := by; y < by+smartCropEntropyBlock && y < height; y++ {
+				for x := bx; x < bx+smartCropEntropyBlock && x < width; x++ {
+					bin := int(lum[y*width+x] * smartCropEntropyBins)
+					if bin >= smartCropEntropyBins {
+						bin = smartCropEntropyBins - 1
+					}
+					hist[bin]++
+					count++
+				}
+			}
+			entropy := 0.0
+			for _, v := range hist {
+				if v == 0 {
+					continue
+				}
+				p := float64(v) / float64(count)
+				entropy -= p * math.Log2(p)
+			}
+			value := entropy / maxEntropy * smartCropEntropyWeight
+			for y := by; y < by+smartCropEntropyBlock && y < height; y++ {
+				for x := bx; x < bx+smartCropEntropyBlock && x < width; x++ {
+					scores[y*width+x] += value
+				}
+			}
+		}
+	}
+	return scores
+}
+
+// findSmartCropOffset find the offset of the crop window which has
+// the most interesting content
+func findSmartCropOffset(img image.Image, width, height int) (x, y int) {
+	currentWidth := img.Bounds().Dx()
+	currentHeight := img.Bounds().Dy()
+	if width >= currentWidth && height >= currentHeight {
+		return
+	}
+	scale := math.Min(1, float64(smartCropAnalyzeSize)/math.Max(float64(currentWidth), float64(currentHeight)))
+	analyzeWidth := scaleSize(currentWidth, scale)
+	analyzeHeight := scaleSize(currentHeight, scale)
+	small := imaging.Resize(img, analyzeWidth, analyzeHeight, imaging.Box)
+	scores := smartCropScores(small)
+
+	// 积分图，用于快速计算窗口内的分数
+	stride := analyzeWidth + 1
+	integral := make([]float64, stride*(analyzeHeight+1))
+	for j := 0; j < analyzeHeight; j++ {
+		rowSum := 0.0
+		for i := 0; i < analyzeWidth; i++ {
+			rowSum += scores[j*analyzeWidth+i]
+			integral[(j+1)*stride+i+1] = integral[j*stride+i+1] + rowSum
+		}
+	}
+	cropWidth := int(math.Min(float64(scaleSize(width, scale)), float64(analyzeWidth)))
+	cropHeight := int(math.Min(float64(scaleSize(height, scale)), float64(analyzeHeight)))
+
+	bestX, bestY := 0, 0
+	best := math.Inf(-1)
+	bestDistance := math.Inf(1)
+	centerX := float64(analyzeWidth-cropWidth) / 2
+	centerY := float64(analyzeHeight-cropHeight) / 2
+	for j := 0; j+cropHeight <= analyzeHeight; j++ {
+		for i := 0; i+cropWidth <= analyzeWidth; i++ {
+			sum := integral[(j+cropHeight)*stride+i+cropWidth] - integral[j*stride+i+cropWidth] -
+				integral[(j+cropHeight)*stride+i] + integral[j*stride+i]
+			// 分数相同时优先选择靠近中间的位置
+			distance := math.Abs(float64(i)-centerX) + math.Abs(float64(j)-centerY)
+			if sum > best+1e-9 || (math.Abs(sum-best) <= 1e-9 && distance < bestDistance) {
+				best = sum
+				bestDistance = distance
+				bestX = i
+				bestY = j
+			}
+		}
+	}
+	x = int(math.Round(float64(bestX) / scale))
+	y = int(math.Round(float64(bestY) / scale))
+	if maxX := currentWidth - width; x > maxX {
+		x = maxX
+	}
+	if maxY := currentHeight - height; y > maxY {
+		y = maxY
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+	return
+}
+
+// findSmartCropFocal find the smart crop window of the image which is scaled
+// to cover the width and height, and return the center of window as focal point.
+// It is used to crop all frames of animation by the same window.
+func findSmartCropFocal(img image.Image, width, height int, withoutEnlargement bool) *FocalPoint {
+	currentWidth := img.Bounds().Dx()
+	currentHeight := img.Bounds().Dy()
+	if currentWidth == 0 || currentHeight == 0 {
+		return &FocalPoint{X: 0.5, Y: 0.5}
+	}
+	scale := math.Max(float64(width)/float64(currentWidth), float64(height)/float64(currentHeight))
+	if withoutEnlargement && scale > 1 {
+		scale = 1
+	}
+	// 裁剪窗口对应原图的尺寸
+	cropWidth := int(math.Min(math.Round(float64(width)/scale), float64(currentWidth)))
+	cropHeight := int(math.Min(math.Round(float64(height)/scale), float64(currentHeight)))
+	x, y := findSmartCropOffset(img, cropWidth, cropHeight)
+	return &FocalPoint{
+		X: (float64(x) + float64(cropWidth)/2) / float64(currentWidth),
+		Y: (float64(y) + float64(cropHeight)/2) / float64(currentHeight),
+	}
+}
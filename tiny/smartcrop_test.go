@@ -0,0 +1,134 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestSmartCropImage create the plain image, the rect is filled with the
+// checkerboard or the skin color
+func newTestSmartCropImage(width, height int, rect image.Rectangle, skin bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{40, 90, 160, 255}
+			if image.Pt(x, y).In(rect) {
+				switch {
+				case skin:
+					c = color.NRGBA{200, 145, 112, 255}
+				case (x/4+y/4)%2 == 0:
+					c = color.NRGBA{255, 255, 255, 255}
+				default:
+					c = color.NRGBA{0, 0, 0, 255}
+				}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestFindSmartCropOffset(t *testing.T) {
+	assert := assert.New(t)
+
+	// 细节在右侧
+	img := newTestSmartCropImage(600, 200, image.Rect(420, 40, 560, 160), false)
+	x, y := findSmartCropOffset(img, 200, 200)
+	assert.Equal(0, y)
+	assert.True(x >= 360 && x <= 420, x)
+
+	// 肤色在上方
+	img = newTestSmartCropImage(200, 600, image.Rect(40, 30, 160, 150), true)
+	x, y = findSmartCropOffset(img, 200, 200)
+	assert.Equal(0, x)
+	assert.True(y <= 30, y)
+
+	// 无内容则居中
+	img = newTestSmartCropImage(600, 200, image.Rectangle{}, false)
+	x, _ = findSmartCropOffset(img, 200, 200)
+	assert.InDelta(200, x, 3)
+
+	x, y = findSmartCropOffset(img, 600, 200)
+	assert.Equal(0, x)
+	assert.Equal(0, y)
+}
+
+func TestImageOptimSmartCrop(t *testing.T) {
+	assert := assert.New(t)
+	img := newTestSmartCropImage(600, 200, image.Rect(20, 40, 160, 160), false)
+	buf := &bytes.Buffer{}
+	err := png.Encode(buf, img)
+	assert.Nil(err)
+
+	// 先缩放至覆盖再裁剪，尺寸与指定的一致
+	result, err := ImageOptimWithOptions(context.Background(), buf.Bytes(), &ImageOptimOptions{
+		Source: EncodeTypePNG,
+		Output: EncodeTypePNG,
+		Crop:   CropSmart,
+		Width:  50,
+		Height: 50,
+	})
+	assert.Nil(err)
+	assert.Equal(50, result.Width)
+	assert.Equal(50, result.Height)
+	decoded, err := imageDecode(result.Data, EncodeTypePNG)
+	assert.Nil(err)
+	// 左侧的细节被保留
+	r, _, _, _ := decoded.At(25, 25).RGBA()
+	assert.NotEqual(uint32(40*0x101), r)
+}
+
+func TestAnimationSmartCrop(t *testing.T) {
+	assert := assert.New(t)
+	first := newTestSmartCropImage(600, 200, image.Rect(20, 40, 160, 160), false)
+	second := newTestSmartCropImage(600, 200, image.Rect(440, 40, 580, 160), false)
+
+	opts := &ImageOptimOptions{
+		Crop:   CropSmart,
+		Width:  50,
+		Height: 50,
+	}
+	result, err := opts.animationOptions(first)
+	assert.Nil(err)
+	assert.Nil(opts.Focal)
+	assert.NotNil(result.Focal)
+	assert.Less(result.Focal.X, 0.5)
+
+	// 所有帧均使用第一帧的裁剪窗口
+	img, err := result.resize(first)
+	assert.Nil(err)
+	assert.Equal(image.Rect(0, 0, 50, 50), img.Bounds())
+	r, _, _, _ := img.At(25, 25).RGBA()
+	assert.NotEqual(uint32(40*0x101), r)
+
+	img, err = result.resize(second)
+	assert.Nil(err)
+	r, _, _, _ = img.At(25, 25).RGBA()
+	assert.Equal(uint32(40*0x101), r)
+
+	// 非覆盖的模式无需计算
+	opts.Fit = FitContain
+	result, err = opts.animationOptions(first)
+	assert.Nil(err)
+	assert.Equal(opts, result)
+}
@@ -14,7 +14,22 @@
 
 package tiny
 
-import "github.com/golang/snappy"
+import (
+	"io"
+
+	"github.com/golang/snappy"
+)
+
+// NewSnappyWriter create a snappy writer, the stream uses the snappy framing format,
+// which is not the same as the block format of SnappyEncode
+func NewSnappyWriter(w io.Writer) (io.WriteCloser, error) {
+	return snappy.NewBufferedWriter(w), nil
+}
+
+// NewSnappyReader create a snappy reader of framing format
+func NewSnappyReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(snappy.NewReader(r)), nil
+}
 
 // SnappyEncode snappy encode
 func SnappyEncode(buf []byte) ([]byte, error) {
@@ -22,3 +37,17 @@ func SnappyEncode(buf []byte) ([]byte, error) {
 	data := snappy.Encode(dst, buf)
 	return data, nil
 }
+
+// SnappyDecode snappy decode
+func SnappyDecode(buf []byte) ([]byte, error) {
+	// 解压时按头部记录的长度分配内存
+	size, err := snappy.DecodedLen(buf)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxDecodeSize {
+		return nil, ErrDecodeSizeExceeded
+	}
+	var dst []byte
+	return snappy.Decode(dst, buf)
+}
@@ -27,3 +27,13 @@ func TestSnappyEncode(t *testing.T) {
 	assert.Nil(err)
 	assert.NotNil(buf)
 }
+
+func TestSnappyDecode(t *testing.T) {
+	assert := assert.New(t)
+	originalBuf := []byte("abcdabcdabcdabcdabcdabcdabcdabcd")
+	buf, err := SnappyEncode(originalBuf)
+	assert.Nil(err)
+	data, err := SnappyDecode(buf)
+	assert.Nil(err)
+	assert.Equal(originalBuf, data)
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"errors"
+	"io"
+)
+
+var errStreamNotSupported = errors.New("not support stream of the encode type")
+
+// SnappyFramed the content encoding of snappy stream, the stream uses the framing
+// format, which can't be decoded as the block format of sz
+const SnappyFramed = "x-snappy-framed"
+
+// StreamContentEncoding get the content encoding of the stream of encode type
+func StreamContentEncoding(t EncodeType) string {
+	if t == EncodeTypeSnappy {
+		return SnappyFramed
+	}
+	return t.String()
+}
+
+// textOptimReader the reader of encoded data,
+// close it will stop the goroutine of encoding
+type textOptimReader struct {
+	*io.PipeReader
+	source io.Reader
+}
+
+// Close close the pipe and the source reader, the goroutine of encoding
+// will exit as the write fails
+func (r *textOptimReader) Close() error {
+	err := r.PipeReader.Close()
+	if closer, ok := r.source.(io.Closer); ok {
+		closer.Close()
+	}
+	return err
+}
+
+// NewTextWriter create a writer which encodes the data to output type of options,
+// the writer should be closed to flush the data
+func NewTextWriter(w io.Writer, opts *TextOptimOptions) (io.WriteCloser, error) {
+	zstdOpts, err := opts.zstdOptions()
+	if err != nil {
+		return nil, err
+	}
+	c, ok := GetCodec(opts.Output)
+	if !ok {
+		return nil, errStreamNotSupported
+	}
+	encoder, ok := c.Encoder.(StreamEncoder)
+	if !ok {
+		return nil, errStreamNotSupported
+	}
+	return encoder.NewWriter(w, &EncodeOptions{
+		Quality:    opts.Quality,
+		Zstd:       zstdOpts,
+		Dictionary: opts.Dictionary,
+	})
+}
+
+// NewTextReader create a reader which decodes the data of source type,
+// the data of unknown type will be read directly
+func NewTextReader(r io.Reader, sourceType EncodeType) (io.ReadCloser, error) {
+	if sourceType == EncodeTypeUnknown {
+		return io.NopCloser(r), nil
+	}
+	c, ok := GetCodec(sourceType)
+	if !ok {
+		return nil, errStreamNotSupported
+	}
+	decoder, ok := c.Decoder.(StreamDecoder)
+	if !ok {
+		return nil, errStreamNotSupported
+	}
+	return decoder.NewReader(r)
+}
+
+// TextOptimStream read the data from reader, decode it of source type and
+// encode to output type, then write to writer.
+// It returns the size of the decoded data.
+func TextOptimStream(w io.Writer, r io.Reader, opts *TextOptimOptions) (int64, error) {
+	reader, err := NewTextReader(r, opts.Source)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+	writer, err := NewTextWriter(w, opts)
+	if err != nil {
+		return 0, err
+	}
+	written, err := io.Copy(writer, reader)
+	// close the writer to flush
+	e := writer.Close()
+	if err == nil {
+		err = e
+	}
+	return written, err
+}
+
+// NewTextOptimReader create a reader of the encoded data, the data of r is encoded
+// in a goroutine, so the memory use is flat for large payloads.
+// The r will be closed when done if it implements io.Closer,
+// the returned reader should be closed if it is not read to the end.
+func NewTextOptimReader(r io.Reader, opts *TextOptimOptions) (io.ReadCloser, error) {
+	reader, err := NewTextReader(r, opts.Source)
+	if err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	writer, err := NewTextWriter(pw, opts)
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+	go func() {
+		_, err := io.Copy(writer, reader)
+		e := writer.Close()
+		if err == nil {
+			err = e
+		}
+		reader.Close()
+		if closer, ok := r.(io.Closer); ok {
+			closer.Close()
+		}
+		// 如果出错，读取时返回该出错
+		pw.CloseWithError(err)
+	}()
+	return &textOptimReader{
+		PipeReader: pr,
+		source:     r,
+	}, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextOptimStream(t *testing.T) {
+	assert := assert.New(t)
+	originalData := bytes.Repeat([]byte("abcd"), 1024)
+	types := []EncodeType{
+		EncodeTypeGzip,
+		EncodeTypeBr,
+		EncodeTypeSnappy,
+		EncodeTypeLz4,
+		EncodeTypeZstd,
+	}
+	for _, encodeType := range types {
+		w := new(bytes.Buffer)
+		written, err := TextOptimStream(w, bytes.NewReader(originalData), &TextOptimOptions{
+			Output: encodeType,
+		})
+		assert.Nil(err)
+		assert.Equal(int64(len(originalData)), written)
+
+		r, err := NewTextReader(w, encodeType)
+		assert.Nil(err)
+		data, err := io.ReadAll(r)
+		assert.Nil(err)
+		assert.Equal(originalData, data, encodeType.String())
+	}
+
+	_, err := TextOptimStream(new(bytes.Buffer), bytes.NewReader(originalData), &TextOptimOptions{
+		Output: EncodeTypeJPEG,
+	})
+	assert.Equal(errStreamNotSupported, err)
+}
+
+func TestNewTextOptimReader(t *testing.T) {
+	assert := assert.New(t)
+	originalData := bytes.Repeat([]byte("abcd"), 1024)
+	gzipData, err := GzipEncode(originalData, 0)
+	assert.Nil(err)
+
+	r, err := NewTextOptimReader(bytes.NewReader(gzipData), &TextOptimOptions{
+		Source: EncodeTypeGzip,
+		Output: EncodeTypeBr,
+	})
+	assert.Nil(err)
+	buf, err := io.ReadAll(r)
+	assert.Nil(err)
+	data, err := BrotliDecode(buf)
+	assert.Nil(err)
+	assert.Equal(originalData, data)
+
+	_, err = NewTextOptimReader(bytes.NewReader(gzipData), &TextOptimOptions{
+		Source: EncodeTypeGzip,
+		Output: EncodeTypePNG,
+	})
+	assert.Equal(errStreamNotSupported, err)
+
+	// 数据非gzip
+	_, err = NewTextOptimReader(bytes.NewReader(originalData), &TextOptimOptions{
+		Source: EncodeTypeGzip,
+		Output: EncodeTypeBr,
+	})
+	assert.NotNil(err)
+
+	// 未读取完成时关闭，源数据也关闭
+	sr, sw := io.Pipe()
+	done := make(chan error)
+	go func() {
+		var err error
+		for err == nil {
+			_, err = sw.Write(originalData)
+		}
+		done <- err
+	}()
+	r, err = NewTextOptimReader(sr, &TextOptimOptions{
+		Output: EncodeTypeGzip,
+	})
+	assert.Nil(err)
+	_, err = io.ReadFull(r, make([]byte, 10))
+	assert.Nil(err)
+	assert.Nil(r.Close())
+	assert.Equal(io.ErrClosedPipe, <-done)
+}
+
+func TestStreamContentEncoding(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(SnappyFramed, StreamContentEncoding(EncodeTypeSnappy))
+	assert.Equal(Gzip, StreamContentEncoding(EncodeTypeGzip))
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"errors"
+	"image"
+
+	"golang.org/x/image/tiff"
+)
+
+var errTIFFIsInvalid = errors.New("tiff data is invalid")
+
+var (
+	// ErrPageNotFound the page is out of the pages of image
+	ErrPageNotFound = errors.New("page of image is not found")
+	// ErrPageNotSupported the source type doesn't support multiple pages
+	ErrPageNotSupported = errors.New("not support page of the source type")
+)
+
+// maxTIFFPages 避免ifd循环引用
+const maxTIFFPages = 1024
+
+// getTIFFPageOffsets get the ifd offsets of all pages
+func getTIFFPageOffsets(data []byte) ([]uint32, error) {
+	order := getExifByteOrder(data)
+	if order == nil || order.Uint16(data[2:]) != 42 {
+		return nil, errTIFFIsInvalid
+	}
+	offsets := make([]uint32, 0)
+	offset := order.Uint32(data[4:])
+	for offset != 0 && len(offsets) < maxTIFFPages {
+		start := int(offset)
+		if start < 8 || start+2 > len(data) {
+			return nil, errTIFFIsInvalid
+		}
+		offsets = append(offsets, offset)
+		// ifd之后为下一个ifd的偏移
+		next := start + 2 + int(order.Uint16(data[start:]))*12
+		if next+4 > len(data) {
+			return nil, errTIFFIsInvalid
+		}
+		offset = order.Uint32(data[next:])
+	}
+	return offsets, nil
+}
+
+// TIFFPageCount get the page count of tiff
+func TIFFPageCount(data []byte) (int, error) {
+	offsets, err := getTIFFPageOffsets(data)
+	if err != nil {
+		return 0, err
+	}
+	return len(offsets), nil
+}
+
+// TIFFDecodePage decode the page of tiff, page 0 is the first page
+func TIFFDecodePage(data []byte, page int) (image.Image, error) {
+	if page == 0 {
+		return tiff.Decode(bytes.NewReader(data))
+	}
+	offsets, err := getTIFFPageOffsets(data)
+	if err != nil {
+		return nil, err
+	}
+	if page < 0 || page >= len(offsets) {
+		return nil, ErrPageNotFound
+	}
+	// tiff解码只处理第一个ifd，因此将其修改为指定页的ifd
+	buf := append([]byte{}, data...)
+	getExifByteOrder(buf).PutUint32(buf[4:], offsets[page])
+	return tiff.Decode(bytes.NewReader(buf))
+}
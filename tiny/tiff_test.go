@@ -0,0 +1,171 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/bmp"
+)
+
+// newTestTIFF create the multi-page tiff, each page is an uncompressed gray image
+func newTestTIFF(width, height int, grays ...uint8) []byte {
+	return newTestTIFFWithOrientation(width, height, 0, grays...)
+}
+
+// newTestTIFFWithOrientation create the multi-page tiff with orientation tag,
+// 0 means no orientation
+func newTestTIFFWithOrientation(width, height, orientation int, grays ...uint8) []byte {
+	order := binary.BigEndian
+	buf := &bytes.Buffer{}
+	buf.WriteString("MM\x00*")
+	// 第一个ifd的偏移，写入ifd时再更新
+	buf.Write(make([]byte, 4))
+	next := 4
+	for _, gray := range grays {
+		stripOffset := buf.Len()
+		buf.Write(bytes.Repeat([]byte{gray}, width*height))
+		if buf.Len()%2 != 0 {
+			buf.WriteByte(0)
+		}
+		ifdOffset := buf.Len()
+		order.PutUint32(buf.Bytes()[next:], uint32(ifdOffset))
+		entries := [][3]uint32{
+			// tag, type, value
+			{256, exifTypeShort, uint32(width)},
+			{257, exifTypeShort, uint32(height)},
+			{258, exifTypeShort, 8},
+			{259, exifTypeShort, 1},
+			{262, exifTypeShort, 1},
+			{273, 4, uint32(stripOffset)},
+			{exifOrientationTag, exifTypeShort, uint32(orientation)},
+			{277, exifTypeShort, 1},
+			{278, exifTypeShort, uint32(height)},
+			{279, 4, uint32(width * height)},
+		}
+		if orientation == 0 {
+			entries = append(entries[:6], entries[7:]...)
+		}
+		ifd := make([]byte, 2+len(entries)*12+4)
+		order.PutUint16(ifd, uint16(len(entries)))
+		for i, e := range entries {
+			entry := ifd[2+i*12:]
+			order.PutUint16(entry, uint16(e[0]))
+			order.PutUint16(entry[2:], uint16(e[1]))
+			order.PutUint32(entry[4:], 1)
+			if e[1] == exifTypeShort {
+				order.PutUint16(entry[8:], uint16(e[2]))
+			} else {
+				order.PutUint32(entry[8:], e[2])
+			}
+		}
+		buf.Write(ifd)
+		next = buf.Len() - 4
+	}
+	return buf.Bytes()
+}
+
+func TestTIFFDecodePage(t *testing.T) {
+	assert := assert.New(t)
+	data := newTestTIFF(8, 4, 10, 100, 200)
+	assert.Equal(EncodeTypeTIFF, detectImageType(data))
+
+	count, err := TIFFPageCount(data)
+	assert.Nil(err)
+	assert.Equal(3, count)
+
+	for page, gray := range []uint8{10, 100, 200} {
+		img, err := TIFFDecodePage(data, page)
+		assert.Nil(err)
+		assert.Equal(image.Rect(0, 0, 8, 4), img.Bounds())
+		assert.Equal(color.Gray{gray}, color.GrayModel.Convert(img.At(1, 1)))
+	}
+
+	_, err = TIFFDecodePage(data, 3)
+	assert.Equal(ErrPageNotFound, err)
+
+	_, err = TIFFPageCount([]byte("abcdefgh"))
+	assert.Equal(errTIFFIsInvalid, err)
+}
+
+func TestImageOptimBMPAndTIFF(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	buf := &bytes.Buffer{}
+	err := bmp.Encode(buf, getTestImage())
+	assert.Nil(err)
+	assert.Equal(EncodeTypeBMP, detectImageType(buf.Bytes()))
+	assert.Equal(EncodeTypeBMP, ConvertToEncodeType("x-ms-bmp"))
+	img, err := ImageOptimWithOptions(ctx, buf.Bytes(), &ImageOptimOptions{
+		Source: EncodeTypeBMP,
+		Output: EncodeTypePNG,
+	})
+	assert.Nil(err)
+	assert.Equal(getTestImage().Bounds().Dx(), img.Width)
+
+	data := newTestTIFF(8, 4, 10, 100)
+	assert.Equal(EncodeTypeTIFF, ConvertToEncodeType("tif"))
+	for page, gray := range []uint8{10, 100} {
+		img, err = ImageOptimWithOptions(ctx, data, &ImageOptimOptions{
+			Source: EncodeTypeTIFF,
+			Output: EncodeTypePNG,
+			Page:   page,
+		})
+		assert.Nil(err)
+		decoded, err := imageDecode(img.Data, EncodeTypePNG)
+		assert.Nil(err)
+		assert.Equal(color.Gray{gray}, color.GrayModel.Convert(decoded.At(1, 1)))
+	}
+
+	_, err = ImageOptimWithOptions(ctx, data, &ImageOptimOptions{
+		Source: EncodeTypeTIFF,
+		Output: EncodeTypePNG,
+		Page:   2,
+	})
+	assert.Equal(ErrPageNotFound, err)
+
+	_, err = ImageOptimWithOptions(ctx, buf.Bytes(), &ImageOptimOptions{
+		Source: EncodeTypeBMP,
+		Output: EncodeTypePNG,
+		Page:   1,
+	})
+	assert.Equal(ErrPageNotSupported, err)
+}
+
+func TestTIFFMetadata(t *testing.T) {
+	assert := assert.New(t)
+	data := newTestTIFFWithOrientation(8, 4, 6, 10)
+	assert.Equal(6, GetOrientation(data, EncodeTypeTIFF))
+	assert.Equal(6, GetOrientation(data, EncodeTypeUnknown))
+	assert.Equal(0, GetOrientation(newTestTIFF(8, 4, 10), EncodeTypeTIFF))
+
+	// 根据方向旋转
+	img, err := ImageOptimWithOptions(context.Background(), data, &ImageOptimOptions{
+		Source:   EncodeTypeTIFF,
+		Output:   EncodeTypePNG,
+		Metadata: MetadataKeep,
+	})
+	assert.Nil(err)
+	assert.Equal(4, img.Width)
+	assert.Equal(8, img.Height)
+	assert.Equal(OrientationNormal, readExifOrientation(readMetadata(img.Data, EncodeTypePNG).Exif))
+}
@@ -19,8 +19,8 @@ import (
 	"context"
 	"errors"
 	"image"
-	"image/jpeg"
-	"image/png"
+	"io"
+	"time"
 
 	"github.com/disintegration/imaging"
 )
@@ -30,9 +30,14 @@ const (
 	defauttBrotliQuality = 6
 	maxBrotliQuality     = 11
 
-	defaultZstdQuality = 2
+	defaultLz4Quality = 1
+	minLz4Quality     = 1
+	maxLz4Quality     = 12
+
+	// zstd的压缩级别(1-22)
+	defaultZstdQuality = 3
 	minZstdQuality     = 1
-	maxZstdQuality     = 2
+	maxZstdQuality     = 22
 
 	defaultJEPGQuality = 80
 	minJPEGQuality     = 0
@@ -78,6 +83,14 @@ const (
 	EncodeTypeWEBP
 	// EncodeTypeAVIF avif
 	EncodeTypeAVIF
+	// EncodeTypeAuto auto, select the smallest result of text encode types
+	EncodeTypeAuto
+	// EncodeTypeGIF gif
+	EncodeTypeGIF
+	// EncodeTypeBMP bmp
+	EncodeTypeBMP
+	// EncodeTypeTIFF tiff
+	EncodeTypeTIFF
 )
 
 const (
@@ -101,6 +114,8 @@ const (
 	CropBottomCenter
 	// CropRightBottom crop right bottom
 	CropRightBottom
+	// CropSmart crop by the content of image, the image is scaled to cover the size first
+	CropSmart
 )
 
 const (
@@ -122,6 +137,14 @@ const (
 	WEBP = "webp"
 	// AVIF avif
 	AVIF = "avif"
+	// Auto auto
+	Auto = "auto"
+	// GIF gif
+	GIF = "gif"
+	// BMP bmp
+	BMP = "bmp"
+	// TIFF tiff
+	TIFF = "tiff"
 )
 
 type (
@@ -131,202 +154,526 @@ type (
 		Type   EncodeType `json:"type,omitempty"`
 		Width  int        `json:"width,omitempty"`
 		Height int        `json:"height,omitempty"`
+		// Encoder the encoder which is actually used, e.g. cjpeg or image/jpeg
+		Encoder string `json:"encoder,omitempty"`
 	}
 	// Text text information
 	Text struct {
 		Data []byte     `json:"data,omitempty"`
 		Type EncodeType `json:"type,omitempty"`
+		// Dictionary the id of dictionary used for encoding
+		Dictionary uint32 `json:"dictionary,omitempty"`
+	}
+	// ImageOptimOptions image optim options
+	ImageOptimOptions struct {
+		// Source the type of image, unknown means detect by data
+		Source EncodeType
+		// Output the output type
+		Output EncodeType
+		// Crop the crop type
+		Crop CropType
+		// Quality the quality of output type
+		Quality int
+		// Width the width of output, 0 means auto
+		Width int
+		// Height the height of output, 0 means auto
+		Height int
+		// DisableAutoOrient disable transform the image by exif orientation
+		DisableAutoOrient bool
+		// Metadata the policy of metadata, default is strip
+		Metadata MetadataPolicy
+		// ConvertToSRGB convert the pixels from the embedded icc profile to sRGB
+		ConvertToSRGB bool
+		// Dither use dithering when the output is palette image(gif)
+		Dither bool
+		// Page the page of multi-page image(tiff), 0 is the first page
+		Page int
+		// Fit the fit mode of resizing to the width and height
+		Fit FitMode
+		// WithoutEnlargement do not scale up the image which is smaller than the width and height
+		WithoutEnlargement bool
+		// Focal crop around the focal point after scaling to cover the width and height
+		Focal *FocalPoint
+		// Rect crop the rectangle of source image before resizing
+		Rect *CropRect
+		// Filter the resampling filter of resizing
+		Filter ResampleFilter
+		// Operations the operations which run in order after resizing
+		Operations []*Operation
+	}
+	// TextOptimOptions text optim options
+	TextOptimOptions struct {
+		// Source the encode type of data, unknown means the original text
+		Source EncodeType
+		// Output the output encode type
+		Output EncodeType
+		// Quality the quality of output encode type
+		Quality int
+		// Zstd the options of zstd encoder
+		Zstd *ZstdOptions
+		// Dictionary the id of dictionary for encoding, only zstd and brotli are supported
+		Dictionary uint32
+		// Candidates the encode types for auto output, empty means all text encode types
+		Candidates []EncodeType
+		// Timeout the soft time budget of auto output, it is checked before
+		// each candidate, so the running one is not interrupted and the first
+		// candidate is always tried, 0 means no limit
+		Timeout time.Duration
 	}
 )
 
 func (t EncodeType) String() string {
-	switch t {
-	default:
+	if t == EncodeTypeAuto {
+		return Auto
+	}
+	c, ok := GetCodec(t)
+	if !ok {
 		return "unknown"
-	case EncodeTypeGzip:
-		return Gzip
-	case EncodeTypeBr:
-		return Br
-	case EncodeTypeSnappy:
-		return Snappy
-	case EncodeTypeLz4:
-		return Lz4
-	case EncodeTypeZstd:
-		return Zstd
-	case EncodeTypeJPEG:
-		return JPEG
-	case EncodeTypePNG:
-		return PNG
-	case EncodeTypeWEBP:
-		return WEBP
-	case EncodeTypeAVIF:
-		return AVIF
 	}
+	return c.Name
 }
 
 // ConvertToEncodeType convert to encode type
 func ConvertToEncodeType(t string) EncodeType {
-	switch t {
-	default:
-		return EncodeTypeUnknown
-	case Gzip:
-		return EncodeTypeGzip
-	case Br:
-		return EncodeTypeBr
-	case Snappy:
-		return EncodeTypeSnappy
-	case Lz4:
-		return EncodeTypeLz4
-	case Zstd:
-		return EncodeTypeZstd
-	case JPEG:
-		return EncodeTypeJPEG
-	case PNG:
-		return EncodeTypePNG
-	case WEBP:
-		return EncodeTypeWEBP
-	case AVIF:
-		return EncodeTypeAVIF
+	if t == Auto {
+		return EncodeTypeAuto
 	}
+	return getCodecType(t)
 }
 
 func imageDecode(buf []byte, sourceType EncodeType) (img image.Image, err error) {
-	reader := bytes.NewReader(buf)
-	switch sourceType {
-	default:
-		img, _, err = image.Decode(reader)
-	case EncodeTypeWEBP:
-		img, err = WebpDecode(reader)
-	case EncodeTypePNG:
-		img, err = png.Decode(reader)
-	case EncodeTypeJPEG:
-		img, err = jpeg.Decode(reader)
+	return imageDecodeContext(context.Background(), buf, sourceType)
+}
+
+// imageDecodeContext decode the image, the context is used
+// if the decoder implements ContextDecoder
+func imageDecodeContext(ctx context.Context, buf []byte, sourceType EncodeType) (img image.Image, err error) {
+	// 未指定类型时根据数据判断，使用对应的解码器（如jpeg的CMYK转换）
+	if sourceType == EncodeTypeUnknown {
+		sourceType = detectImageType(buf)
 	}
-	return
+	c, ok := GetCodec(sourceType)
+	if !ok || c.ImageDecoder == nil {
+		img, _, err = image.Decode(bytes.NewReader(buf))
+		return
+	}
+	if decoder, ok := c.ImageDecoder.(ContextDecoder); ok {
+		return decoder.DecodeContext(ctx, buf)
+	}
+	return c.ImageDecoder.Decode(buf)
+}
+
+// imageDecodePage decode the page of multi-page image, page 0 is the first page
+func imageDecodePage(ctx context.Context, buf []byte, sourceType EncodeType, page int) (image.Image, error) {
+	if page == 0 {
+		return imageDecodeContext(ctx, buf, sourceType)
+	}
+	if sourceType == EncodeTypeUnknown {
+		sourceType = detectImageType(buf)
+	}
+	c, ok := GetCodec(sourceType)
+	if !ok {
+		return nil, ErrPageNotSupported
+	}
+	decoder, ok := c.ImageDecoder.(PageDecoder)
+	if !ok {
+		return nil, ErrPageNotSupported
+	}
+	return decoder.DecodePage(buf, page)
 }
 
 // ImageResize resize image
 func ImageResize(img image.Image, width, height int) image.Image {
-	return imaging.Resize(img, width, height, imaging.Lanczos)
+	return ImageResizeWithFilter(img, width, height, FilterLanczos)
 }
 
-// ImageCrop crop image
-func ImageCrop(img image.Image, cropType CropType, width, height int) image.Image {
-	currentWidth := img.Bounds().Dx()
-	currentHeight := img.Bounds().Dy()
-	if width == 0 || width > currentWidth {
-		width = currentWidth
-	}
-	if height == 0 || height > currentHeight {
-		height = currentHeight
-	}
-	var x0, y0, x1, y1 int
+// getCropOffset get the offset of the crop type, the free space is the
+// difference between the image and the crop area
+func getCropOffset(cropType CropType, freeWidth, freeHeight int) (x, y int) {
 	switch cropType {
-	case CropLeftTop:
-		x0 = 0
-		y0 = 0
 	case CropTopCenter:
-		x0 = (currentWidth - width) / 2
-		y0 = 0
+		x = freeWidth / 2
 	case CropRightTop:
-		x0 = (currentWidth - width)
-		y0 = 0
+		x = freeWidth
 	case CropLeftCenter:
-		x0 = 0
-		y0 = (currentHeight - height) / 2
+		y = freeHeight / 2
 	case CropCenterCenter:
-		x0 = (currentWidth - width) / 2
-		y0 = (currentHeight - height) / 2
+		x = freeWidth / 2
+		y = freeHeight / 2
 	case CropRightCenter:
-		x0 = currentWidth - width
-		y0 = (currentHeight - height) / 2
+		x = freeWidth
+		y = freeHeight / 2
 	case CropLeftBottom:
-		x0 = 0
-		y0 = currentHeight - height
+		y = freeHeight
 	case CropBottomCenter:
-		x0 = (currentWidth - width) / 2
-		y0 = currentHeight - height
+		x = freeWidth / 2
+		y = freeHeight
 	case CropRightBottom:
-		x0 = currentWidth - width
-		y0 = currentHeight - height
+		x = freeWidth
+		y = freeHeight
 	default:
-		// 其它的裁切类型不处理
+		// 其它的裁切类型（包括左上）不偏移
 	}
-	x1 = x0 + width
-	y1 = y0 + height
+	return
+}
 
-	rect := image.Rect(x0, y0, x1, y1)
+// ImageCrop crop image
+func ImageCrop(img image.Image, cropType CropType, width, height int) image.Image {
+	currentWidth := img.Bounds().Dx()
+	currentHeight := img.Bounds().Dy()
+	if width == 0 || width > currentWidth {
+		width = currentWidth
+	}
+	if height == 0 || height > currentHeight {
+		height = currentHeight
+	}
+	var x0, y0 int
+	if cropType == CropSmart {
+		x0, y0 = findSmartCropOffset(img, width, height)
+	} else {
+		x0, y0 = getCropOffset(cropType, currentWidth-width, currentHeight-height)
+	}
+	rect := image.Rect(x0, y0, x0+width, y0+height)
 	return imaging.Crop(img, rect)
 }
 
 // ImageOptim image optim
 func ImageOptim(ctx context.Context, buf []byte, sourceType, outputType EncodeType, cropType CropType, quality, width, height int) (imgInfo *Image, err error) {
-	img, err := imageDecode(buf, sourceType)
+	return ImageOptimWithOptions(ctx, buf, &ImageOptimOptions{
+		Source:  sourceType,
+		Output:  outputType,
+		Crop:    cropType,
+		Quality: quality,
+		Width:   width,
+		Height:  height,
+	})
+}
+
+// ImageOptimWithOptions image optim with options
+func ImageOptimWithOptions(ctx context.Context, buf []byte, opts *ImageOptimOptions) (imgInfo *Image, err error) {
+	sourceType := opts.Source
+	outputType := opts.Output
+	// 解码前校验操作，避免无效的解码
+	err = validateOperations(opts.Operations)
 	if err != nil {
 		return
 	}
-	if width != 0 || height != 0 {
-		// 如果不需要裁剪
-		if cropType == CropNone {
-			img = ImageResize(img, width, height)
-		} else {
-			img = ImageCrop(img, cropType, width, height)
-		}
+	// 解码后的图片也占用较多内存，因此在解码前限制
+	release, err := acquireEncode(ctx, outputType)
+	if err != nil {
+		return
 	}
-	var data []byte
-	switch outputType {
-	case EncodeTypeJPEG:
-		data, err = JPEGEncode(ctx, img, quality)
-	case EncodeTypePNG:
-		data, err = PNGEncode(ctx, img, quality)
-	case EncodeTypeAVIF:
-		data, err = AVIFEncode(ctx, img, quality)
-	case EncodeTypeWEBP:
-		data, err = WEBPEncode(img, quality)
-	default:
+	defer release()
+	c, ok := GetCodec(outputType)
+	if !ok || c.ImageEncoder == nil {
 		err = errors.New("not support the output type")
+		return
 	}
+	// 源图片与输出类型均支持动图时，动图的每帧均需要调整
+	anim, err := decodeAnimation(buf, sourceType, c)
 	if err != nil {
 		return
 	}
-	w := img.Bounds().Dx()
-	h := img.Bounds().Dy()
-	imgInfo = &Image{
-		Data:   data,
-		Width:  w,
-		Height: h,
-		Type:   outputType,
+	if anim != nil && len(anim.Frames) > 1 {
+		return animationOptim(ctx, anim, c, opts)
 	}
+	img, err := imageDecodePage(ctx, buf, sourceType, opts.Page)
+	if err != nil {
+		return
+	}
+	meta := readMetadata(buf, sourceType)
+	// 图片是否有调整（调整后的图片无法使用原图）
+	modified := false
+	// 转换为sRGB后不再保留原有的icc，不支持的icc则忽略
+	if opts.ConvertToSRGB && len(meta.ICC) != 0 {
+		if result, e := ImageToSRGB(img, meta.ICC); e == nil {
+			img = result
+			meta.ICC = nil
+			modified = true
+		}
+	}
+	// 根据exif的方向旋转，需要在调整尺寸前处理
+	orientation := 0
+	if !opts.DisableAutoOrient {
+		orientation = readExifOrientation(meta.Exif)
+		img = ImageOrient(img, orientation)
+	}
+	if orientation > OrientationNormal {
+		modified = true
+	}
+	if opts.Rect != nil || opts.Width != 0 || opts.Height != 0 {
+		modified = true
+		img, err = opts.resize(img)
+		if err != nil {
+			return
+		}
+	}
+	if len(opts.Operations) != 0 {
+		modified = true
+		img, err = ImageOperate(img, opts.Operations)
+		if err != nil {
+			return
+		}
+	}
+	imgInfo, err = c.ImageEncoder.Encode(ctx, img, &EncodeOptions{
+		Quality: opts.Quality,
+		Dither:  opts.Dither,
+	})
+	// pngquant无法满足质量要求时，图片未调整则返回原图，否则使用无损压缩
+	if IsQualityTooLow(err) {
+		imgInfo, err = qualityTooLowFallback(buf, img, sourceType == outputType && !modified)
+	}
+	if err != nil {
+		return
+	}
+	imgInfo.Width = img.Bounds().Dx()
+	imgInfo.Height = img.Bounds().Dy()
+	// 编码器未指定类型（非兜底转换）则为输出类型
+	if imgInfo.Type == EncodeTypeUnknown {
+		imgInfo.Type = outputType
+	}
+	if imgInfo.Encoder == "" {
+		imgInfo.Encoder = c.Name
+	}
+	// 编码后的数据不包括元数据（原图则包括），根据策略重新写入
+	imgInfo.Data, err = writeMetadata(imgInfo.Data, imgInfo.Type, meta.filter(opts.Metadata, orientation > OrientationNormal))
 	return
 }
 
-// TextOptim text optim
+// resize resize or crop the image by options, the rectangle is cropped before resizing
+func (opts *ImageOptimOptions) resize(img image.Image) (image.Image, error) {
+	if opts.Rect != nil {
+		result, err := ImageCropRect(img, opts.Rect)
+		if err != nil {
+			return nil, err
+		}
+		img = result
+	}
+	if opts.Width == 0 && opts.Height == 0 {
+		return img, nil
+	}
+	if opts.Focal != nil {
+		return ImageFocalCrop(img, opts.Focal, opts.Width, opts.Height, opts.WithoutEnlargement, opts.Filter), nil
+	}
+	fit := opts.Fit
+	// 智能裁剪需要先缩放至覆盖指定尺寸
+	if fit == FitNone && opts.Crop == CropSmart {
+		fit = FitCover
+	}
+	// 未指定模式时，如果需要裁剪则只裁剪不缩放
+	if fit == FitNone && opts.Crop != CropNone {
+		return ImageCrop(img, opts.Crop, opts.Width, opts.Height), nil
+	}
+	if fit == FitNone && !opts.WithoutEnlargement {
+		return ImageResizeWithFilter(img, opts.Width, opts.Height, opts.Filter), nil
+	}
+	return ImageFit(img, fit, opts.Crop, opts.Width, opts.Height, opts.WithoutEnlargement, opts.Filter), nil
+}
+
+// animationOptions get the options of animation, the smart crop window is
+// found from the first frame, then all frames are cropped by the same window
+// to avoid jitter and the analysis of each frame
+func (opts *ImageOptimOptions) animationOptions(first image.Image) (*ImageOptimOptions, error) {
+	if opts.Crop != CropSmart || opts.Focal != nil || opts.Width == 0 || opts.Height == 0 {
+		return opts, nil
+	}
+	// 仅缩放至覆盖时才需要智能裁剪
+	if opts.Fit != FitNone && opts.Fit != FitCover {
+		return opts, nil
+	}
+	if opts.Rect != nil {
+		result, err := ImageCropRect(first, opts.Rect)
+		if err != nil {
+			return nil, err
+		}
+		first = result
+	}
+	result := *opts
+	result.Focal = findSmartCropFocal(first, opts.Width, opts.Height, opts.WithoutEnlargement)
+	return &result, nil
+}
+
+// decodeAnimation decode the animation if both of the source and output codec
+// support animation, it returns nil if not supported
+func decodeAnimation(buf []byte, sourceType EncodeType, output *Codec) (*Animation, error) {
+	if _, ok := output.ImageEncoder.(AnimationEncoder); !ok {
+		return nil, nil
+	}
+	if sourceType == EncodeTypeUnknown {
+		sourceType = detectImageType(buf)
+	}
+	source, ok := GetCodec(sourceType)
+	if !ok {
+		return nil, nil
+	}
+	decoder, ok := source.ImageDecoder.(AnimationDecoder)
+	if !ok {
+		return nil, nil
+	}
+	return decoder.DecodeAnimation(buf)
+}
+
+// animationOptim resize the frames of animation and encode it
+func animationOptim(ctx context.Context, anim *Animation, c *Codec, opts *ImageOptimOptions) (*Image, error) {
+	opts, err := opts.animationOptions(anim.Frames[0])
+	if err != nil {
+		return nil, err
+	}
+	for i, frame := range anim.Frames {
+		// 帧数较多时耗时较长，每帧处理前判断是否已超时
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		img, err := opts.resize(frame)
+		if err != nil {
+			return nil, err
+		}
+		img, err = ImageOperate(img, opts.Operations)
+		if err != nil {
+			return nil, err
+		}
+		anim.Frames[i] = img
+	}
+	imgInfo, err := c.ImageEncoder.(AnimationEncoder).EncodeAnimation(ctx, anim, &EncodeOptions{
+		Quality: opts.Quality,
+		Dither:  opts.Dither,
+	})
+	if err != nil {
+		return nil, err
+	}
+	bounds := anim.Frames[0].Bounds()
+	imgInfo.Width = bounds.Dx()
+	imgInfo.Height = bounds.Dy()
+	if imgInfo.Type == EncodeTypeUnknown {
+		imgInfo.Type = opts.Output
+	}
+	if imgInfo.Encoder == "" {
+		imgInfo.Encoder = c.Name
+	}
+	// 动图不保留元数据
+	imgInfo.Data, err = writeMetadata(imgInfo.Data, imgInfo.Type, nil)
+	if err != nil {
+		return nil, err
+	}
+	return imgInfo, nil
+}
+
+// qualityTooLowFallback return the original image or the lossless png
+func qualityTooLowFallback(buf []byte, img image.Image, useOriginal bool) (*Image, error) {
+	if useOriginal {
+		return &Image{
+			Data:    buf,
+			Type:    EncodeTypePNG,
+			Encoder: EncoderOriginal,
+		}, nil
+	}
+	return pngLosslessEncode(img)
+}
+
+// maxDecodeSize the max size of decoded text, avoid the decompression bomb
+var maxDecodeSize = 256 * 1024 * 1024
+
+// ErrDecodeSizeExceeded the size of decoded text exceeds the limit
+var ErrDecodeSizeExceeded = errors.New("the size of decoded data exceeds the limit")
+
+// readAllWithLimit read all data of reader, it returns
+// ErrDecodeSizeExceeded if the size exceeds the limit
+func readAllWithLimit(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(maxDecodeSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDecodeSize {
+		return nil, ErrDecodeSizeExceeded
+	}
+	return data, nil
+}
+
+// TextDecode decode the compressed text, the data of unknown type will be returned directly
+func TextDecode(data []byte, sourceType EncodeType) ([]byte, error) {
+	if sourceType == EncodeTypeUnknown {
+		return data, nil
+	}
+	c, ok := GetCodec(sourceType)
+	if !ok || c.Decoder == nil {
+		return nil, errors.New("not support the source type")
+	}
+	return c.Decoder.Decode(data)
+}
+
+// TextOptim text optim, the data is the original text,
+// use TextOptimWithOptions to decode the data of source type first
 func TextOptim(data []byte, outputType EncodeType, quality int) (info *Text, err error) {
-	var buf []byte
-	var t EncodeType
-	switch outputType {
-	case EncodeTypeBr:
-		t = EncodeTypeBr
-		buf, err = BrotliEncode(data, quality)
-	case EncodeTypeSnappy:
-		t = EncodeTypeSnappy
-		buf, err = SnappyEncode(data)
-	case EncodeTypeLz4:
-		t = EncodeTypeLz4
-		buf, err = Lz4Encode(data, quality)
-	case EncodeTypeZstd:
-		t = EncodeTypeZstd
-		buf, err = ZstdEncode(data, quality)
+	return TextOptimWithOptions(data, &TextOptimOptions{
+		Output:  outputType,
+		Quality: quality,
+	})
+}
+
+// zstdOptions get the zstd options with the dictionary of id,
+// it returns error if the output type does not support dictionary
+func (opts *TextOptimOptions) zstdOptions() (*ZstdOptions, error) {
+	if opts.Dictionary == 0 {
+		return opts.Zstd, nil
+	}
+	switch opts.Output {
+	case EncodeTypeZstd, EncodeTypeBr:
+	case EncodeTypeAuto:
+		// 字典仅支持zstd与brotli，若候选类型均不支持则无法使用
+		if len(opts.Candidates) != 0 &&
+			!containsEncodeType(opts.Candidates, EncodeTypeZstd) &&
+			!containsEncodeType(opts.Candidates, EncodeTypeBr) {
+			return nil, ErrDictionaryNotSupported
+		}
 	default:
-		t = EncodeTypeGzip
-		buf, err = GzipEncode(data, quality)
+		return nil, ErrDictionaryNotSupported
+	}
+	dict, err := GetDictionary(opts.Dictionary)
+	if err != nil {
+		return nil, err
+	}
+	zstdOpts := &ZstdOptions{}
+	if opts.Zstd != nil {
+		*zstdOpts = *opts.Zstd
+	}
+	zstdOpts.Dictionary = dict
+	return zstdOpts, nil
+}
+
+// textEncode encode the data to output type
+func textEncode(data []byte, outputType EncodeType, opts *EncodeOptions) ([]byte, error) {
+	c, ok := GetCodec(outputType)
+	if !ok || c.Encoder == nil {
+		return nil, errors.New("not support the output type")
+	}
+	return c.Encoder.Encode(data, opts)
+}
+
+// TextOptimWithOptions text optim with options
+func TextOptimWithOptions(data []byte, opts *TextOptimOptions) (info *Text, err error) {
+	zstdOpts, err := opts.zstdOptions()
+	if err != nil {
+		return
+	}
+	data, err = TextDecode(data, opts.Source)
+	if err != nil {
+		return
+	}
+	if opts.Output == EncodeTypeAuto {
+		return textAutoEncode(data, opts, zstdOpts)
 	}
+	buf, err := textEncode(data, opts.Output, &EncodeOptions{
+		Quality:    opts.Quality,
+		Zstd:       zstdOpts,
+		Dictionary: opts.Dictionary,
+	})
 	if err != nil {
 		return
 	}
 	info = &Text{
-		Data: buf,
-		Type: t,
+		Data:       buf,
+		Type:       opts.Output,
+		Dictionary: opts.Dictionary,
 	}
 	return
 }
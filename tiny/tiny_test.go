@@ -253,6 +253,138 @@ func TestTextOptim(t *testing.T) {
 		assert.Equal(EncodeTypeZstd, info.Type)
 		assert.NotNil(info.Data)
 	})
+
+	t.Run("gzip to br", func(t *testing.T) {
+		assert := assert.New(t)
+		originalData := []byte("abcdabcdabcdabcdabcdabcdabcdabcd")
+		data, err := GzipEncode(originalData, 0)
+		assert.Nil(err)
+		info, err := TextOptimWithOptions(data, &TextOptimOptions{
+			Source: EncodeTypeGzip,
+			Output: EncodeTypeBr,
+		})
+		assert.Nil(err)
+		assert.Equal(EncodeTypeBr, info.Type)
+		result, err := BrotliDecode(info.Data)
+		assert.Nil(err)
+		assert.Equal(originalData, result)
+	})
+
+	t.Run("gzip to lz4", func(t *testing.T) {
+		assert := assert.New(t)
+		originalData := []byte("abcdabcdabcdabcdabcdabcdabcdabcd")
+		data, err := GzipEncode(originalData, 0)
+		assert.Nil(err)
+		info, err := TextOptimWithOptions(data, &TextOptimOptions{
+			Source:  EncodeTypeGzip,
+			Output:  EncodeTypeLz4,
+			Quality: 9,
+		})
+		assert.Nil(err)
+		assert.Equal(EncodeTypeLz4, info.Type)
+		result, err := Lz4Decode(info.Data)
+		assert.Nil(err)
+		assert.Equal(originalData, result)
+	})
+
+	t.Run("zstd with options", func(t *testing.T) {
+		assert := assert.New(t)
+		info, err := TextOptimWithOptions([]byte("abcd"), &TextOptimOptions{
+			Output:  EncodeTypeZstd,
+			Quality: 19,
+			Zstd: &ZstdOptions{
+				DisableChecksum: true,
+			},
+		})
+		assert.Nil(err)
+		assert.Equal(EncodeTypeZstd, info.Type)
+		data, err := ZstdDecode(info.Data)
+		assert.Nil(err)
+		assert.Equal("abcd", string(data))
+	})
+
+	t.Run("source type is not supported", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := TextOptimWithOptions([]byte("abcd"), &TextOptimOptions{
+			Source: EncodeTypePNG,
+			Output: EncodeTypeBr,
+		})
+		assert.NotNil(err)
+	})
+
+	t.Run("output type is not supported", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := TextOptim([]byte("abcd"), EncodeTypePNG, 0)
+		assert.NotNil(err)
+	})
+}
+
+func TestTextDecode(t *testing.T) {
+	assert := assert.New(t)
+	originalData := []byte("abcdabcdabcdabcdabcdabcdabcdabcd")
+	types := []EncodeType{
+		EncodeTypeGzip,
+		EncodeTypeBr,
+		EncodeTypeSnappy,
+		EncodeTypeLz4,
+		EncodeTypeZstd,
+	}
+	for _, encodeType := range types {
+		info, err := TextOptim(originalData, encodeType, 0)
+		assert.Nil(err)
+		data, err := TextDecode(info.Data, encodeType)
+		assert.Nil(err)
+		assert.Equal(originalData, data, encodeType.String())
+	}
+
+	// lz4的高压缩级别
+	info, err := TextOptim(originalData, EncodeTypeLz4, 9)
+	assert.Nil(err)
+	data, err := TextDecode(info.Data, EncodeTypeLz4)
+	assert.Nil(err)
+	assert.Equal(originalData, data)
+
+	data, err = TextDecode(originalData, EncodeTypeUnknown)
+	assert.Nil(err)
+	assert.Equal(originalData, data)
+}
+
+func TestTextDecodeLimit(t *testing.T) {
+	assert := assert.New(t)
+	defer func(size int) {
+		maxDecodeSize = size
+	}(maxDecodeSize)
+	maxDecodeSize = 1024
+
+	originalData := bytes.Repeat([]byte("abcd"), 512)
+	for _, encodeType := range []EncodeType{
+		EncodeTypeGzip,
+		EncodeTypeBr,
+		EncodeTypeSnappy,
+		EncodeTypeLz4,
+		EncodeTypeZstd,
+	} {
+		info, err := TextOptim(originalData, encodeType, 0)
+		assert.Nil(err)
+		_, err = TextDecode(info.Data, encodeType)
+		assert.Equal(ErrDecodeSizeExceeded, err, encodeType.String())
+
+		// zstd的窗口大小也受该限制，大于测试的限制值
+		if encodeType == EncodeTypeZstd {
+			continue
+		}
+		// 未超出限制的可正常解压
+		info, err = TextOptim(originalData[:1024], encodeType, 0)
+		assert.Nil(err)
+		data, err := TextDecode(info.Data, encodeType)
+		assert.Nil(err)
+		assert.Equal(originalData[:1024], data, encodeType.String())
+	}
+
+	buf, err := Lz4BlockEncode(originalData, 0)
+	assert.Nil(err)
+	_, err = Lz4BlockDecode(buf)
+	assert.Equal(ErrDecodeSizeExceeded, err)
 }
 
 func TestEncodeType(t *testing.T) {
@@ -262,6 +394,7 @@ func TestEncodeType(t *testing.T) {
 	assert.Equal(JPEG, EncodeTypeJPEG.String())
 	assert.Equal(PNG, EncodeTypePNG.String())
 	assert.Equal(WEBP, EncodeTypeWEBP.String())
+	assert.Equal(Auto, EncodeTypeAuto.String())
 }
 
 func TestConvertToEncodeType(t *testing.T) {
@@ -272,4 +405,6 @@ func TestConvertToEncodeType(t *testing.T) {
 	assert.Equal(EncodeTypeJPEG, ConvertToEncodeType(JPEG))
 	assert.Equal(EncodeTypePNG, ConvertToEncodeType(PNG))
 	assert.Equal(EncodeTypeWEBP, ConvertToEncodeType(WEBP))
+	assert.Equal(EncodeTypeAuto, ConvertToEncodeType(Auto))
+	assert.Equal(EncodeTypeSnappy, ConvertToEncodeType("snappy"))
 }
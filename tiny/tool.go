@@ -0,0 +1,161 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"context"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultProbeTimeout = 5 * time.Second
+
+type (
+	// Tool the external tool of encoder
+	Tool struct {
+		Name      string `json:"name,omitempty"`
+		Installed bool   `json:"installed,omitempty"`
+		Path      string `json:"path,omitempty"`
+		Version   string `json:"version,omitempty"`
+	}
+	// Capabilities the encode types which are actually usable
+	Capabilities struct {
+		ImageInputs  []string `json:"imageInputs,omitempty"`
+		ImageOutputs []string `json:"imageOutputs,omitempty"`
+		TextInputs   []string `json:"textInputs,omitempty"`
+		TextOutputs  []string `json:"textOutputs,omitempty"`
+		Tools        []*Tool  `json:"tools,omitempty"`
+		Fallback     string   `json:"fallback,omitempty"`
+	}
+)
+
+// externalTools the external tools and the args to get version
+var externalTools = map[string][]string{
+	EncoderCjpeg:    {"-version"},
+	EncoderPngquant: {"--version"},
+	EncoderCavif:    {"--version"},
+	DecoderAvifdec:  {"--version"},
+}
+
+var probedTools = struct {
+	sync.RWMutex
+	m map[string]*Tool
+}{}
+
+// probeTool check the tool is installed and get its version
+func probeTool(ctx context.Context, name string) *Tool {
+	tool := &Tool{
+		Name: name,
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return tool
+	}
+	tool.Installed = true
+	tool.Path = path
+	// 部分工具（如cjpeg）将版本信息输出至stderr，且退出码不一定为0
+	output, _ := exec.CommandContext(ctx, path, externalTools[name]...).CombinedOutput()
+	lines := strings.SplitN(strings.TrimSpace(string(output)), "\n", 2)
+	tool.Version = strings.TrimSpace(lines[0])
+	return tool
+}
+
+// ProbeTools probe the external tools of encoders, the result is cached
+// and used for capabilities
+func ProbeTools(ctx context.Context) []*Tool {
+	ctx, cancel := context.WithTimeout(ctx, defaultProbeTimeout)
+	defer cancel()
+	m := make(map[string]*Tool)
+	for name := range externalTools {
+		m[name] = probeTool(ctx, name)
+	}
+	probedTools.Lock()
+	probedTools.m = m
+	probedTools.Unlock()
+	return GetTools()
+}
+
+// GetTools get the probed tools(sorted by name), the tools will be probed if not yet
+func GetTools() []*Tool {
+	probedTools.RLock()
+	m := probedTools.m
+	probedTools.RUnlock()
+	if m == nil {
+		return ProbeTools(context.Background())
+	}
+	result := make([]*Tool, 0, len(m))
+	for _, tool := range m {
+		result = append(result, tool)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// isToolInstalled check the tool is installed
+func isToolInstalled(name string) bool {
+	for _, tool := range GetTools() {
+		if tool.Name == name {
+			return tool.Installed
+		}
+	}
+	return false
+}
+
+// isEncoderUsable check the encoder of codec is usable,
+// it is usable if the tool is installed or can fallback
+func (c *Codec) isEncoderUsable() bool {
+	if c.Tool == "" || isToolInstalled(c.Tool) {
+		return true
+	}
+	policy := GetFallbackPolicy()
+	return c.Fallback != FallbackNone && policy >= c.Fallback
+}
+
+// GetCapabilities get the capabilities of tiny
+func GetCapabilities() *Capabilities {
+	capabilities := &Capabilities{
+		Tools:    GetTools(),
+		Fallback: GetFallbackPolicy().String(),
+	}
+	codecs.RLock()
+	items := make([]*Codec, 0, len(codecs.types))
+	for _, c := range codecs.types {
+		items = append(items, c)
+	}
+	codecs.RUnlock()
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Type < items[j].Type
+	})
+	for _, c := range items {
+		if c.ImageDecoder != nil && (c.DecodeTool == "" || isToolInstalled(c.DecodeTool)) {
+			capabilities.ImageInputs = append(capabilities.ImageInputs, c.Name)
+		}
+		if c.ImageEncoder != nil && c.isEncoderUsable() {
+			capabilities.ImageOutputs = append(capabilities.ImageOutputs, c.Name)
+		}
+		if c.Decoder != nil {
+			capabilities.TextInputs = append(capabilities.TextInputs, c.Name)
+		}
+		if c.Encoder != nil {
+			capabilities.TextOutputs = append(capabilities.TextOutputs, c.Name)
+		}
+	}
+	return capabilities
+}
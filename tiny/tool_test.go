@@ -0,0 +1,68 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbeTools(t *testing.T) {
+	assert := assert.New(t)
+	tools := ProbeTools(context.Background())
+	assert.Equal(len(externalTools), len(tools))
+	for _, tool := range tools {
+		_, err := exec.LookPath(tool.Name)
+		assert.Equal(err == nil, tool.Installed, tool.Name)
+		if !tool.Installed {
+			assert.Empty(tool.Version)
+		}
+	}
+	assert.Equal(tools, GetTools())
+}
+
+func TestGetCapabilities(t *testing.T) {
+	assert := assert.New(t)
+	defer SetFallbackPolicy(GetFallbackPolicy())
+
+	// 其它测试可能注册了自定义的codec
+	SetFallbackPolicy(FallbackWEBP)
+	capabilities := GetCapabilities()
+	assert.Subset(capabilities.ImageInputs, []string{JPEG, PNG, WEBP})
+	assert.Equal(isToolInstalled(DecoderAvifdec), containsString(capabilities.ImageInputs, AVIF))
+	assert.Subset(capabilities.ImageOutputs, []string{JPEG, PNG, WEBP, AVIF})
+	assert.Subset(capabilities.TextInputs, []string{Gzip, Br, Snappy, Lz4, Zstd})
+	assert.Subset(capabilities.TextOutputs, []string{Gzip, Br, Snappy, Lz4, Zstd})
+	assert.Equal("webp", capabilities.Fallback)
+
+	SetFallbackPolicy(FallbackNone)
+	capabilities = GetCapabilities()
+	assert.Equal(isToolInstalled(EncoderCjpeg), containsString(capabilities.ImageOutputs, JPEG))
+	assert.Equal(isToolInstalled(EncoderPngquant), containsString(capabilities.ImageOutputs, PNG))
+	assert.Equal(isToolInstalled(EncoderCavif), containsString(capabilities.ImageOutputs, AVIF))
+	assert.Contains(capabilities.ImageOutputs, WEBP)
+}
+
+func containsString(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -17,47 +17,74 @@ package tiny
 import (
 	"bytes"
 	"context"
-	"io/ioutil"
-	"math/rand"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"time"
 )
 
 // Commander commander
 type Commander func(string, string) []string
 
-// https://stackoverflow.com/questions/22892120/how-to-generate-a-random-string-of-a-fixed-length-in-go
-const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+var tmpDir = os.Getenv("TINY_TMP_DIR")
+
 const (
-	letterIdxBits = 6                    // 6 bits to represent a letter index
-	letterIdxMask = 1<<letterIdxBits - 1 // All 1-bits, as many as letterIdxBits
-	letterIdxMax  = 63 / letterIdxBits   // # of letter indices fitting in 63 bits
+	// 未指定临时目录时，使用系统临时目录下的子目录，避免清除其它程序的文件
+	defaultTmpDirName = "tiny"
+	// 临时文件的前缀，用于清除残留的临时文件
+	tmpFilePrefix = "tiny-"
+	// DefaultTempFileMaxAge the default max age of temp file,
+	// the older file is regarded as orphan
+	DefaultTempFileMaxAge = 10 * time.Minute
 )
 
-// randomString create a random string
-func randomString(baseLetters string, n int) string {
-	b := make([]byte, n)
-	// A rand.Int63() generates 63 random bits, enough for letterIdxMax letters!
-	for i, cache, remain := n-1, rand.Int63(), letterIdxMax; i >= 0; {
-		if remain == 0 {
-			cache, remain = rand.Int63(), letterIdxMax
-		}
-		if idx := int(cache & letterIdxMask); idx < len(baseLetters) {
-			b[i] = baseLetters[idx]
-			i--
-		}
-		cache >>= letterIdxBits
-		remain--
+// getTmpDir get the dir of temp files, the dir is created if not exists
+func getTmpDir() (string, error) {
+	if tmpDir != "" {
+		return tmpDir, nil
 	}
+	dir := filepath.Join(os.TempDir(), defaultTmpDirName)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", err
+	}
+	return dir, nil
+}
 
-	return string(b)
+// doCommandPipe run the command with the data as stdin,
+// and write the stdout to writer, it's used for the tool supports stdin/stdout
+func doCommandPipe(ctx context.Context, data []byte, args []string, writer *bytes.Buffer) (err error) {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = writer
+	return runCommand(cmd, args)
 }
 
-var tmpDir = os.Getenv("TINY_TMP_DIR")
+// runCommand run the command, the error is converted to tool error with the stderr output
+func runCommand(cmd *exec.Cmd, args []string) error {
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if err != nil {
+		return newToolError(args, err, stderr.Bytes())
+	}
+	return nil
+}
+
+// doCommandConvert write the data to temp file and run the command,
+// then read the target file to writer, it's used for the tool which does not support stdin/stdout
+func doCommandConvert(ctx context.Context, data []byte, fn Commander, writer *bytes.Buffer) error {
+	return doCommandConvertWithExt(ctx, data, "", fn, writer)
+}
 
-func doCommandConvert(ctx context.Context, data []byte, fn Commander, writer *bytes.Buffer) (err error) {
-	filename := randomString(letterBytes, 10)
-	tmpfile, err := ioutil.TempFile(tmpDir, filename)
+// doCommandConvertWithExt same as doCommandConvert, the ext is added to the target file
+// for the tool which selects the output format by file extension
+func doCommandConvertWithExt(ctx context.Context, data []byte, ext string, fn Commander, writer *bytes.Buffer) (err error) {
+	dir, err := getTmpDir()
+	if err != nil {
+		return
+	}
+	tmpfile, err := os.CreateTemp(dir, tmpFilePrefix+"*")
 	if err != nil {
 		return
 	}
@@ -69,19 +96,44 @@ func doCommandConvert(ctx context.Context, data []byte, fn Commander, writer *by
 	if err != nil {
 		return
 	}
-	targetFile := originalFile + "-new"
+	targetFile := originalFile + "-new" + ext
+	// 删除临时文件（出错时也可能已生成）
+	defer os.Remove(targetFile)
 	args := fn(originalFile, targetFile)
 	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
-	err = cmd.Run()
+	err = runCommand(cmd, args)
 	if err != nil {
 		return
 	}
-	// 删除临时文件
-	defer os.Remove(targetFile)
-	target, err := ioutil.ReadFile(targetFile)
+	target, err := os.ReadFile(targetFile)
 	if err != nil {
 		return
 	}
 	writer.Write(target)
 	return
 }
+
+// CleanTempFiles remove the temp files of TINY_TMP_DIR(or the tiny dir of system temp dir)
+// which are older than max age, these files are left when the process is killed.
+// It returns the count of removed files.
+func CleanTempFiles(maxAge time.Duration) (int, error) {
+	dir, err := getTmpDir()
+	if err != nil {
+		return 0, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, tmpFilePrefix+"*"))
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil || info.IsDir() || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		if os.Remove(file) == nil {
+			count++
+		}
+	}
+	return count, nil
+}
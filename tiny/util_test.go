@@ -0,0 +1,113 @@
+// Copyright 2026 tree xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiny
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoCommandPipe(t *testing.T) {
+	assert := assert.New(t)
+	buffer := new(bytes.Buffer)
+	err := doCommandPipe(context.Background(), []byte("abcd"), []string{"cat"}, buffer)
+	assert.Nil(err)
+	assert.Equal("abcd", buffer.String())
+}
+
+func TestDoCommandConvert(t *testing.T) {
+	assert := assert.New(t)
+	originalTmpDir := tmpDir
+	defer func() {
+		tmpDir = originalTmpDir
+	}()
+	tmpDir = t.TempDir()
+
+	buffer := new(bytes.Buffer)
+	fn := func(originalFile, targetFile string) []string {
+		return []string{
+			"cp",
+			originalFile,
+			targetFile,
+		}
+	}
+	err := doCommandConvert(context.Background(), []byte("abcd"), fn, buffer)
+	assert.Nil(err)
+	assert.Equal("abcd", buffer.String())
+
+	// 临时文件均已删除
+	files, err := os.ReadDir(tmpDir)
+	assert.Nil(err)
+	assert.Empty(files)
+}
+
+func TestCleanTempFiles(t *testing.T) {
+	assert := assert.New(t)
+	originalTmpDir := tmpDir
+	defer func() {
+		tmpDir = originalTmpDir
+	}()
+	tmpDir = t.TempDir()
+
+	orphanFile := filepath.Join(tmpDir, tmpFilePrefix+"orphan")
+	newFile := filepath.Join(tmpDir, tmpFilePrefix+"new")
+	otherFile := filepath.Join(tmpDir, "other")
+	for _, file := range []string{orphanFile, newFile, otherFile} {
+		err := os.WriteFile(file, []byte("abcd"), 0600)
+		assert.Nil(err)
+	}
+	modTime := time.Now().Add(-time.Hour)
+	for _, file := range []string{orphanFile, otherFile} {
+		err := os.Chtimes(file, modTime, modTime)
+		assert.Nil(err)
+	}
+
+	count, err := CleanTempFiles(DefaultTempFileMaxAge)
+	assert.Nil(err)
+	assert.Equal(1, count)
+	_, err = os.Stat(orphanFile)
+	assert.True(os.IsNotExist(err))
+	_, err = os.Stat(newFile)
+	assert.Nil(err)
+	_, err = os.Stat(otherFile)
+	assert.Nil(err)
+}
+
+func TestGetTmpDir(t *testing.T) {
+	assert := assert.New(t)
+	originalTmpDir := tmpDir
+	defer func() {
+		tmpDir = originalTmpDir
+	}()
+
+	tmpDir = ""
+	dir, err := getTmpDir()
+	assert.Nil(err)
+	assert.Equal(filepath.Join(os.TempDir(), defaultTmpDirName), dir)
+	info, err := os.Stat(dir)
+	assert.Nil(err)
+	assert.True(info.IsDir())
+
+	tmpDir = t.TempDir()
+	dir, err = getTmpDir()
+	assert.Nil(err)
+	assert.Equal(tmpDir, dir)
+}
@@ -16,6 +16,7 @@ package tiny
 
 import (
 	"bytes"
+	"encoding/binary"
 	"image"
 	"io"
 
@@ -50,3 +51,72 @@ func WEBPEncode(img image.Image, quality int) (data []byte, err error) {
 func WebpDecode(reader io.Reader) (image.Image, error) {
 	return webp.Decode(reader)
 }
+
+// WEBPEncodeAnimation encode the animation to animated webp,
+// each frame is encoded as a full canvas frame
+func WEBPEncodeAnimation(anim *Animation, quality int) ([]byte, error) {
+	if len(anim.Frames) == 0 {
+		return nil, errWebpIsInvalid
+	}
+	bounds := anim.Frames[0].Bounds()
+	hasAlpha := false
+	frames := &bytes.Buffer{}
+	for i, img := range anim.Frames {
+		data, err := WEBPEncode(img, quality)
+		if err != nil {
+			return nil, err
+		}
+		chunks, err := parseWebpChunks(data)
+		if err != nil {
+			return nil, err
+		}
+		header := make([]byte, 16)
+		putUint24LE(header[6:], uint32(img.Bounds().Dx()-1))
+		putUint24LE(header[9:], uint32(img.Bounds().Dy()-1))
+		if i < len(anim.Delays) {
+			putUint24LE(header[12:], uint32(anim.Delays[i]))
+		}
+		// 每帧均为完整的画布，不与上一帧混合
+		header[15] = 0x02
+		frame := bytes.NewBuffer(header)
+		for _, c := range chunks {
+			// 帧数据只包括ALPH与VP8/VP8L
+			switch c.name {
+			case "ALPH":
+				hasAlpha = true
+			case "VP8L":
+				if _, _, alpha, _ := getWebpCanvasSize(c); alpha {
+					hasAlpha = true
+				}
+			case "VP8 ":
+			default:
+				continue
+			}
+			writeRIFFChunk(frame, c.name, c.data)
+		}
+		writeRIFFChunk(frames, "ANMF", frame.Bytes())
+	}
+
+	vp8x := make([]byte, 10)
+	vp8x[0] = webpFlagAnimation
+	if hasAlpha {
+		vp8x[0] |= webpFlagAlpha
+	}
+	putUint24LE(vp8x[4:], uint32(bounds.Dx()-1))
+	putUint24LE(vp8x[7:], uint32(bounds.Dy()-1))
+	// 背景色（BGRA）以及循环次数
+	animData := make([]byte, 6)
+	binary.LittleEndian.PutUint16(animData[4:], uint16(anim.LoopCount))
+
+	w := bytes.NewBuffer(make([]byte, 0, frames.Len()+64))
+	w.WriteString("RIFF")
+	// 文件长度最后再更新
+	w.Write(make([]byte, 4))
+	w.WriteString("WEBP")
+	writeRIFFChunk(w, "VP8X", vp8x)
+	writeRIFFChunk(w, "ANIM", animData)
+	w.Write(frames.Bytes())
+	result := w.Bytes()
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+	return result, nil
+}
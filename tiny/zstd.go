@@ -16,18 +16,97 @@ package tiny
 
 import (
 	"bytes"
+	"errors"
+	"io"
 
 	"github.com/klauspost/compress/zstd"
 )
 
-// ZstdEncode zstd encode
-func ZstdEncode(buf []byte, quality int) ([]byte, error) {
+// ZstdOptions zstd encoder options
+type ZstdOptions struct {
+	// WindowSize the window size of encoder, it must be a power of two between 1KB and 256MB(the max decoded size),
+	// 0 means the default value of the level
+	WindowSize int `json:"windowSize,omitempty"`
+	// DisableChecksum disable the checksum of frame
+	DisableChecksum bool `json:"disableChecksum,omitempty"`
+	// Concurrency the number of concurrent encoders for stream, 0 means GOMAXPROCS
+	Concurrency int `json:"concurrency,omitempty"`
+	// Dictionary the zstd dictionary for encoding
+	Dictionary []byte `json:"-"`
+}
+
+// ErrZstdWindowSizeExceeded the window size exceeds the max decoded size,
+// the data encoded with it can not be decoded
+var ErrZstdWindowSizeExceeded = errors.New("the window size of zstd exceeds the limit")
+
+// zstdEncoderOptions convert the quality(the level of zstd, 1-22) and options
+// to encoder options, the level is mapped to the supported encoder levels
+func zstdEncoderOptions(quality int, opts *ZstdOptions) []zstd.EOption {
 	if quality < minZstdQuality || quality > maxZstdQuality {
 		quality = defaultZstdQuality
 	}
+	eOpts := []zstd.EOption{
+		zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(quality)),
+	}
+	if opts == nil {
+		return eOpts
+	}
+	if opts.WindowSize != 0 {
+		eOpts = append(eOpts, zstd.WithWindowSize(opts.WindowSize))
+	}
+	if opts.DisableChecksum {
+		eOpts = append(eOpts, zstd.WithEncoderCRC(false))
+	}
+	if opts.Concurrency > 0 {
+		eOpts = append(eOpts, zstd.WithEncoderConcurrency(opts.Concurrency))
+	}
+	if len(opts.Dictionary) != 0 {
+		eOpts = append(eOpts, zstd.WithEncoderDict(opts.Dictionary))
+	}
+	return eOpts
+}
+
+// zstdDecoderOptions get the decoder options, the dictionaries of store
+// are added, so the data encoded with dictionary can be decoded
+func zstdDecoderOptions() []zstd.DOption {
+	dOpts := []zstd.DOption{
+		// 默认的最大内存为64GB，限制解压后的数据大小
+		zstd.WithDecoderMaxMemory(uint64(maxDecodeSize)),
+	}
+	dicts := listDictionaries()
+	if len(dicts) != 0 {
+		dOpts = append(dOpts, zstd.WithDecoderDicts(dicts...))
+	}
+	return dOpts
+}
+
+// NewZstdWriter create a zstd writer, the opts can be nil
+func NewZstdWriter(w io.Writer, quality int, opts *ZstdOptions) (io.WriteCloser, error) {
+	// 解压时窗口大小受限于最大解压数据
+	if opts != nil && opts.WindowSize > maxDecodeSize {
+		return nil, ErrZstdWindowSizeExceeded
+	}
+	return zstd.NewWriter(w, zstdEncoderOptions(quality, opts)...)
+}
 
+// NewZstdReader create a zstd reader
+func NewZstdReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r, zstdDecoderOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
+
+// ZstdEncode zstd encode
+func ZstdEncode(buf []byte, quality int) ([]byte, error) {
+	return ZstdEncodeWithOptions(buf, quality, nil)
+}
+
+// ZstdEncodeWithOptions zstd encode with options
+func ZstdEncodeWithOptions(buf []byte, quality int, opts *ZstdOptions) ([]byte, error) {
 	buffer := new(bytes.Buffer)
-	w, err := zstd.NewWriter(buffer, zstd.WithEncoderLevel(zstd.EncoderLevel(quality)))
+	w, err := NewZstdWriter(buffer, quality, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -42,3 +121,17 @@ func ZstdEncode(buf []byte, quality int) ([]byte, error) {
 
 	return buffer.Bytes(), nil
 }
+
+// ZstdDecode zstd decode
+func ZstdDecode(buf []byte) ([]byte, error) {
+	r, err := zstd.NewReader(nil, zstdDecoderOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := r.DecodeAll(buf, nil)
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		return nil, ErrDecodeSizeExceeded
+	}
+	return data, err
+}
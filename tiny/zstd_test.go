@@ -15,6 +15,7 @@
 package tiny
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,3 +28,41 @@ func TestZstdEncode(t *testing.T) {
 	assert.Nil(err)
 	assert.NotNil(buf)
 }
+
+func TestZstdDecode(t *testing.T) {
+	assert := assert.New(t)
+	originalBuf := []byte("abcdabcdabcdabcdabcdabcdabcdabcd")
+	buf, err := ZstdEncode(originalBuf, 0)
+	assert.Nil(err)
+	data, err := ZstdDecode(buf)
+	assert.Nil(err)
+	assert.Equal(originalBuf, data)
+}
+
+func TestZstdEncodeWithOptions(t *testing.T) {
+	assert := assert.New(t)
+	originalBuf := bytes.Repeat([]byte("abcd"), 1024)
+	for _, quality := range []int{1, 3, 6, 10, 22} {
+		buf, err := ZstdEncodeWithOptions(originalBuf, quality, &ZstdOptions{
+			WindowSize:      1024,
+			DisableChecksum: true,
+			Concurrency:     2,
+		})
+		assert.Nil(err)
+		data, err := ZstdDecode(buf)
+		assert.Nil(err)
+		assert.Equal(originalBuf, data)
+	}
+
+	// window size is not a power of two
+	_, err := ZstdEncodeWithOptions(originalBuf, 0, &ZstdOptions{
+		WindowSize: 1000,
+	})
+	assert.NotNil(err)
+
+	// window size exceeds the max decoded size
+	_, err = ZstdEncodeWithOptions(originalBuf, 0, &ZstdOptions{
+		WindowSize: 2 * maxDecodeSize,
+	})
+	assert.Equal(ErrZstdWindowSizeExceeded, err)
+}